@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// accountFetchAttempts is how often the account is requested before giving up
+const accountFetchAttempts = 5
+
+// AccountCache fetches the bot account once, retrying while Lichess is unavailable,
+// and keeps it for the life of the process
+type AccountCache struct {
+	mu      sync.Mutex
+	account *BotAccount
+	fetch   func() (*BotAccount, error)
+}
+
+// NewAccountCache creates a cache backed by getBotAccountDetails
+func NewAccountCache(cfg *BotConfig) *AccountCache {
+	return &AccountCache{fetch: func() (*BotAccount, error) { return getBotAccountDetails(cfg) }}
+}
+
+// Get returns the cached account, fetching it on first use
+func (c *AccountCache) Get() (*BotAccount, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.account != nil {
+		return c.account, nil
+	}
+
+	var account *BotAccount
+	err := RetryWithBackoff(accountFetchAttempts, func() error {
+		var err error
+		account, err = c.fetch()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.account = account
+	return account, nil
+}
+
+// ServeHTTP implements GET /api/account, exposing the cached account details
+func (c *AccountCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	account, err := c.Get()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccountCache_RetriesAndCaches(t *testing.T) {
+	withFastRetries(t)
+
+	calls := 0
+	cache := &AccountCache{fetch: func() (*BotAccount, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("lichess unavailable")
+		}
+		return &BotAccount{ID: "mybot", Username: "MyBot", Title: "BOT"}, nil
+	}}
+
+	for i := 0; i < 3; i++ {
+		account, err := cache.Get()
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		if account.ID != "mybot" {
+			t.Errorf("Unexpected account %+v", account)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected one failed and one successful fetch, got %d calls", calls)
+	}
+
+	rec := httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/account", nil))
+	var served BotAccount
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil || served.Username != "MyBot" {
+		t.Errorf("Unexpected /api/account response %q (err=%v)", rec.Body.String(), err)
+	}
+}
+
+func TestAccountCache_NotBotIsNotRetried(t *testing.T) {
+	withFastRetries(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"id":"someone","username":"Someone"}`))
+	}))
+	defer server.Close()
+
+	cache := NewAccountCache(newTestLichessConfig(server.URL))
+	if _, err := cache.Get(); err == nil {
+		t.Fatal("Expected error for non-bot account, but got nil")
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single request for a permanent error, got %d", requests)
+	}
+
+	rec := httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/account", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+}
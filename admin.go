@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+)
+
+// ipAllowlistMiddleware rejects requests whose remote address is not in one of
+// the allowed ranges with 403. An empty list allows every address.
+func ipAllowlistMiddleware(allowed []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(allowed) > 0 && !isIPAllowed(r.RemoteAddr, allowed) {
+			log.Printf("Admin API request from %s rejected: address not in ALLOWED_IP_RANGES", r.RemoteAddr)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isIPAllowed reports whether remoteAddr ("host:port" or a bare IP) is within any allowed range
+func isIPAllowed(remoteAddr string, allowed []netip.Prefix) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	// IPv4-mapped IPv6 addresses (::ffff:1.2.3.4) should match IPv4 ranges
+	addr = addr.Unmap().WithZone("")
+
+	for _, prefix := range allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// adminTLSConfig returns a TLS config that requires clients to present a
+// certificate signed by the CA in caCertPath (mutual TLS)
+func adminTLSConfig(caCertPath string) (*tls.Config, error) {
+	caPEM, err := os.ReadFile(caCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ADMIN_TLS_CA_CERT: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no valid certificates found in %s", caCertPath)
+	}
+
+	return &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func mustParsePrefixes(t *testing.T, cidrs ...string) []netip.Prefix {
+	t.Helper()
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			t.Fatalf("Invalid CIDR '%s': %v", cidr, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes
+}
+
+func TestIsIPAllowed(t *testing.T) {
+	allowed := mustParsePrefixes(t, "10.0.0.0/8", "192.168.1.0/24", "2001:db8::/32", "::1/128")
+
+	tests := []struct {
+		remoteAddr string
+		expected   bool
+	}{
+		{"10.1.2.3:5555", true},
+		{"192.168.1.200:80", true},
+		{"192.168.2.1:80", false},
+		{"8.8.8.8:443", false},
+		{"[2001:db8::1]:8080", true},
+		{"[2001:db9::1]:8080", false},
+		{"[::1]:1234", true},
+		{"[::ffff:10.0.0.5]:1234", true},
+		{"[fe80::1%eth0]:1234", false},
+		{"10.0.0.1", true},
+		{"not-an-ip:80", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr, func(t *testing.T) {
+			if got := isIPAllowed(tt.remoteAddr, allowed); got != tt.expected {
+				t.Errorf("isIPAllowed(%s) = %v, expected %v", tt.remoteAddr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIPAllowlistMiddleware(t *testing.T) {
+	handler := ipAllowlistMiddleware(mustParsePrefixes(t, "127.0.0.0/8"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/games", nil)
+	req.RemoteAddr = "127.0.0.1:4000"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for allowed IP, got %d", rec.Code)
+	}
+
+	req.RemoteAddr = "203.0.113.7:4000"
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for disallowed IP, got %d", rec.Code)
+	}
+}
+
+func TestIPAllowlistMiddleware_EmptyAllowsAll(t *testing.T) {
+	handler := ipAllowlistMiddleware(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/games", nil)
+	req.RemoteAddr = "203.0.113.7:4000"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 with no ranges configured, got %d", rec.Code)
+	}
+}
+
+// testCert is a generated certificate with its private key
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func (c testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// generateTestCert creates a certificate signed by parent, or self-signed when parent is nil
+func generateTestCert(t *testing.T, commonName string, isCA bool, parent *testCert) testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	return testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func TestAdminTLSConfig_MutualAuth(t *testing.T) {
+	ca := generateTestCert(t, "test-ca", true, nil)
+	client := generateTestCert(t, "admin-client", false, &ca)
+	rogueCA := generateTestCert(t, "rogue-ca", true, nil)
+	rogueClient := generateTestCert(t, "rogue-client", false, &rogueCA)
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, ca.certPEM, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	tlsConfig, err := adminTLSConfig(caPath)
+	if err != nil {
+		t.Fatalf("adminTLSConfig() failed: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	doRequest := func(certs ...tls.Certificate) error {
+		httpClient := server.Client()
+		transport := httpClient.Transport.(*http.Transport).Clone()
+		transport.TLSClientConfig.Certificates = certs
+		httpClient.Transport = transport
+
+		resp, err := httpClient.Get(server.URL + "/api/games")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	if err := doRequest(client.tlsCertificate()); err != nil {
+		t.Errorf("Expected request with valid client certificate to succeed, got %v", err)
+	}
+	if err := doRequest(); err == nil {
+		t.Error("Expected request without client certificate to fail")
+	}
+	if err := doRequest(rogueClient.tlsCertificate()); err == nil {
+		t.Error("Expected request with certificate from another CA to fail")
+	}
+}
+
+func TestAdminTLSConfig_InvalidCA(t *testing.T) {
+	if _, err := adminTLSConfig(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("Expected error for missing CA file, but got nil")
+	}
+
+	badPath := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(badPath, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := adminTLSConfig(badPath); err == nil {
+		t.Error("Expected error for invalid CA file, but got nil")
+	}
+}
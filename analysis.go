@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// Analysis events logged when comparing the LLM move with the engine move
+const (
+	AnalysisMatch      = "match"
+	AnalysisDivergence = "divergence"
+)
+
+// Prompt modes recorded in MoveAnalysis.PromptMode
+const (
+	PromptModeSingle    = "single"
+	PromptModeMultiStep = "multi-step"
+)
+
+// MoveAnalysis records the LLM move next to the engine's choice for the same position
+type MoveAnalysis struct {
+	GameID        string
+	MoveNumber    int
+	LLMMove       string
+	StockfishMove string
+	// CentipawnDiff is how much worse the LLM move is than the engine move
+	// according to the engine (0 when the moves match)
+	CentipawnDiff int
+	// Reasoning is the model's step-by-step analysis when THINK_BEFORE_MOVE is enabled
+	Reasoning string
+	// PromptMode ("single" or "multi-step") and LatencyMS allow comparing prompting strategies
+	PromptMode string
+	LatencyMS  int64
+}
+
+// Event returns "match" when both moves are the same and "divergence" otherwise
+func (a MoveAnalysis) Event() string {
+	if a.LLMMove == a.StockfishMove {
+		return AnalysisMatch
+	}
+	return AnalysisDivergence
+}
+
+// logMoveAnalysis writes a single analysis line for the move
+func logMoveAnalysis(a MoveAnalysis) {
+	log.Printf("Analysis [%s] game %s move %d: llm=%s stockfish=%s cp_diff=%d mode=%s latency_ms=%d",
+		a.Event(), a.GameID, a.MoveNumber, a.LLMMove, a.StockfishMove, a.CentipawnDiff, a.PromptMode, a.LatencyMS)
+}
+
+// analyzeMove runs Stockfish on the position after moves from initialFEN and compares
+// its best move with llmMove. The centipawn difference is the engine's score for its
+// own move minus its score for the position after llmMove, both from the mover's side.
+func analyzeMove(cfg *BotConfig, gameID string, moves []string, initialFEN, llmMove string) (MoveAnalysis, error) {
+	a := MoveAnalysis{GameID: gameID, MoveNumber: len(moves)/2 + 1, LLMMove: llmMove}
+	best, bestScore, err := runStockfish(cfg, moves, initialFEN, cfg.StockfishDepth)
+	if err != nil {
+		return a, fmt.Errorf("stockfish analysis failed: %v", err)
+	}
+	a.StockfishMove = best
+	if best == llmMove {
+		return a, nil
+	}
+
+	after := append(append([]string(nil), moves...), llmMove)
+	replyScore, err := evaluateWithStockfish(cfg, after, initialFEN, cfg.StockfishDepth)
+	if err != nil {
+		return a, fmt.Errorf("stockfish evaluation of %s failed: %v", llmMove, err)
+	}
+	// replyScore is from the opponent's point of view
+	a.CentipawnDiff = max(0, bestScore+replyScore)
+	return a, nil
+}
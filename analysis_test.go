@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestMoveAnalysis_Event(t *testing.T) {
+	match := MoveAnalysis{LLMMove: "e2e4", StockfishMove: "e2e4"}
+	if event := match.Event(); event != AnalysisMatch {
+		t.Errorf("Expected '%s', got '%s'", AnalysisMatch, event)
+	}
+
+	divergence := MoveAnalysis{LLMMove: "a2a3", StockfishMove: "e2e4", CentipawnDiff: 35}
+	if event := divergence.Event(); event != AnalysisDivergence {
+		t.Errorf("Expected '%s', got '%s'", AnalysisDivergence, event)
+	}
+}
+
+func TestAnalyzeMove(t *testing.T) {
+	path, _ := writeStubStockfish(t, "bestmove e7e5")
+	cfg := &BotConfig{StockfishPath: path, StockfishDepth: 10}
+
+	match, err := analyzeMove(cfg, "game1", []string{"e2e4"}, "", "e7e5")
+	if err != nil {
+		t.Fatalf("analyzeMove() failed: %v", err)
+	}
+	if match.Event() != AnalysisMatch || match.CentipawnDiff != 0 || match.MoveNumber != 1 {
+		t.Errorf("Expected a match on move 1 without difference, got %+v", match)
+	}
+
+	// The stub scores every position +20 for the side to move, so the LLM move
+	// leaves the opponent 20cp better where the engine move kept +20
+	divergence, err := analyzeMove(cfg, "game1", []string{"e2e4"}, "", "a7a6")
+	if err != nil {
+		t.Fatalf("analyzeMove() failed: %v", err)
+	}
+	if divergence.Event() != AnalysisDivergence || divergence.StockfishMove != "e7e5" || divergence.CentipawnDiff != 40 {
+		t.Errorf("Expected a 40cp divergence from e7e5, got %+v", divergence)
+	}
+
+	cfg.StockfishPath = "/nonexistent/stockfish"
+	if _, err := analyzeMove(cfg, "game1", nil, "", "e2e4"); err == nil {
+		t.Error("Expected an error when stockfish cannot be started")
+	}
+}
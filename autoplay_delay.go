@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// AutoplayDelay holds back the bot's reply so it arrives a random time between
+// min and max after the opponent's move. Time already spent choosing the move
+// counts towards the delay, so slow LLM answers are not delayed any further.
+type AutoplayDelay struct {
+	min, max time.Duration
+
+	now   func() time.Time
+	sleep func(time.Duration)
+	rand  func(n int64) int64
+}
+
+// NewAutoplayDelay creates the delay configured by MIN_AUTOPLAY_DELAY_MS and MAX_AUTOPLAY_DELAY_MS
+func NewAutoplayDelay(cfg *BotConfig) *AutoplayDelay {
+	return &AutoplayDelay{
+		min:   time.Duration(cfg.MinAutoplayDelayMS) * time.Millisecond,
+		max:   time.Duration(cfg.MaxAutoplayDelayMS) * time.Millisecond,
+		now:   time.Now,
+		sleep: time.Sleep,
+		rand:  rand.Int63n,
+	}
+}
+
+// Wait sleeps until the randomly chosen delay after opponentMovedAt has passed and
+// returns how long it slept
+func (d *AutoplayDelay) Wait(opponentMovedAt time.Time) time.Duration {
+	if d.max <= 0 {
+		return 0
+	}
+	target := d.min
+	if d.max > d.min {
+		target += time.Duration(d.rand(int64(d.max-d.min) + 1))
+	}
+
+	remaining := target - d.now().Sub(opponentMovedAt)
+	if remaining <= 0 {
+		return 0
+	}
+	d.sleep(remaining)
+	return remaining
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestAutoplayDelay(minMS, maxMS int, now time.Time, slept *[]time.Duration) *AutoplayDelay {
+	d := NewAutoplayDelay(&BotConfig{MinAutoplayDelayMS: minMS, MaxAutoplayDelayMS: maxMS})
+	d.now = func() time.Time { return now }
+	d.sleep = func(wait time.Duration) { *slept = append(*slept, wait) }
+	return d
+}
+
+func TestAutoplayDelay_Wait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name      string
+		minMS     int
+		maxMS     int
+		random    int64
+		elapsed   time.Duration
+		wantSleep time.Duration
+	}{
+		{"disabled", 0, 0, 0, 0, 0},
+		{"fixed delay", 1000, 1000, 0, 0, time.Second},
+		{"lowest random delay", 500, 1500, 0, 0, 500 * time.Millisecond},
+		{"highest random delay", 500, 1500, int64(time.Second), 0, 1500 * time.Millisecond},
+		{"thinking time counts", 1000, 1000, 0, 300 * time.Millisecond, 700 * time.Millisecond},
+		{"slow move is not delayed", 1000, 1000, 0, 2 * time.Second, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var slept []time.Duration
+			d := newTestAutoplayDelay(tt.minMS, tt.maxMS, now, &slept)
+			d.rand = func(n int64) int64 {
+				if tt.random >= n {
+					t.Errorf("Random value %d out of range [0, %d)", tt.random, n)
+				}
+				return tt.random
+			}
+
+			waited := d.Wait(now.Add(-tt.elapsed))
+			if waited != tt.wantSleep {
+				t.Errorf("Expected to wait %v, got %v", tt.wantSleep, waited)
+			}
+			if tt.wantSleep == 0 && len(slept) != 0 {
+				t.Errorf("Expected no sleep, got %v", slept)
+			}
+			if tt.wantSleep > 0 && (len(slept) != 1 || slept[0] != tt.wantSleep) {
+				t.Errorf("Expected one sleep of %v, got %v", tt.wantSleep, slept)
+			}
+		})
+	}
+}
+
+func TestAutoplayDelay_RealSleep(t *testing.T) {
+	d := NewAutoplayDelay(&BotConfig{MinAutoplayDelayMS: 20, MaxAutoplayDelayMS: 40})
+	start := time.Now()
+	d.Wait(start)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected a delay of at least 20ms, got %v", elapsed)
+	}
+}
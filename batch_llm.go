@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// batchResult is the answer to one batched LLM request
+type batchResult struct {
+	content string
+	err     error
+}
+
+// batchCall is an LLM request waiting in a batch
+type batchCall struct {
+	request openRouterRequest
+	done    chan batchResult
+}
+
+// BatchLLMClient collects LLM requests from games that need a move at the same
+// time and sends them together once the batch window closes. Requests in a batch
+// run in parallel, but at most maxParallel at once across all batches, so bursts of
+// simultaneous games share one concurrency limit instead of each hitting the API.
+type BatchLLMClient struct {
+	window time.Duration
+	sem    chan struct{}
+	call   func(openRouterRequest) (string, error)
+
+	mu      sync.Mutex
+	pending []*batchCall
+}
+
+// NewBatchLLMClient creates a client that sends requests with callOpenRouter
+func NewBatchLLMClient(cfg *BotConfig) *BatchLLMClient {
+	return &BatchLLMClient{
+		window: time.Duration(cfg.BatchWindowMS) * time.Millisecond,
+		sem:    make(chan struct{}, cfg.BatchMaxParallel),
+		call: func(request openRouterRequest) (string, error) {
+			return callOpenRouter(cfg, request)
+		},
+	}
+}
+
+// Call adds request to the current batch and blocks until its response arrives.
+// With a zero window the request is sent straight away, still within the parallel limit.
+func (c *BatchLLMClient) Call(request openRouterRequest) (string, error) {
+	if c.window <= 0 {
+		c.sem <- struct{}{}
+		defer func() { <-c.sem }()
+		return c.call(request)
+	}
+
+	bc := &batchCall{request: request, done: make(chan batchResult, 1)}
+	c.mu.Lock()
+	c.pending = append(c.pending, bc)
+	if len(c.pending) == 1 {
+		time.AfterFunc(c.window, c.flush)
+	}
+	c.mu.Unlock()
+
+	result := <-bc.done
+	return result.content, result.err
+}
+
+// flush sends every pending request and logs the batch latency next to the time
+// the same requests would have taken one after another
+func (c *BatchLLMClient) flush() {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	var sequentialMu sync.Mutex
+	var sequential time.Duration
+	for _, bc := range batch {
+		wg.Add(1)
+		go func(bc *batchCall) {
+			defer wg.Done()
+			c.sem <- struct{}{}
+			callStart := time.Now()
+			content, err := c.call(bc.request)
+			elapsed := time.Since(callStart)
+			<-c.sem
+
+			sequentialMu.Lock()
+			sequential += elapsed
+			sequentialMu.Unlock()
+			bc.done <- batchResult{content: content, err: err}
+		}(bc)
+	}
+	wg.Wait()
+
+	if len(batch) > 1 {
+		log.Printf("LLM batch of %d requests took %v (%v if sent sequentially)",
+			len(batch), time.Since(start).Round(time.Millisecond), sequential.Round(time.Millisecond))
+	}
+}
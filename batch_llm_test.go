@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchLLMClient_BatchesConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	var batchStart sync.Once
+	firstCall := make(chan time.Time, 1)
+
+	client := NewBatchLLMClient(&BotConfig{BatchWindowMS: 30, BatchMaxParallel: 2})
+	client.call = func(request openRouterRequest) (string, error) {
+		batchStart.Do(func() { firstCall <- time.Now() })
+		n := inFlight.Add(1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		if request.Model == "broken" {
+			return "", errors.New("model unavailable")
+		}
+		return "move from " + request.Model, nil
+	}
+
+	start := time.Now()
+	models := []string{"a", "b", "c", "broken"}
+	results := make([]string, len(models))
+	errs := make([]error, len(models))
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func(i int, model string) {
+			defer wg.Done()
+			results[i], errs[i] = client.Call(openRouterRequest{Model: model})
+		}(i, model)
+	}
+	wg.Wait()
+
+	if waited := (<-firstCall).Sub(start); waited < 25*time.Millisecond {
+		t.Errorf("Expected requests to wait for the batch window, first call after %v", waited)
+	}
+	if max := maxInFlight.Load(); max != 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", max)
+	}
+	for i, model := range models[:3] {
+		if errs[i] != nil || results[i] != "move from "+model {
+			t.Errorf("Expected 'move from %s', got '%s' (%v)", model, results[i], errs[i])
+		}
+	}
+	if errs[3] == nil {
+		t.Error("Expected the failing request to return its error")
+	}
+}
+
+func TestBatchLLMClient_ZeroWindowCallsDirectly(t *testing.T) {
+	client := NewBatchLLMClient(&BotConfig{BatchWindowMS: 0, BatchMaxParallel: 1})
+	client.call = func(request openRouterRequest) (string, error) {
+		return "e2e4", nil
+	}
+
+	content, err := client.Call(openRouterRequest{Model: "m"})
+	if err != nil || content != "e2e4" {
+		t.Errorf("Expected 'e2e4', got '%s' (%v)", content, err)
+	}
+}
@@ -0,0 +1,649 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bot plays the games of one Lichess BOT account, each in its own goroutine
+type Bot struct {
+	cfg     *BotConfig
+	botID   string
+	botName string
+	// reporter posts finished games to WEBHOOK_URL (nil when unset)
+	reporter *GameReporter
+	// commands answers "!" chat commands from CHAT_ADMIN_USERNAMES
+	commands *ChatCommands
+	// batch sends the move requests of all games together (nil without LLM_BATCH_MAX_PARALLEL)
+	batch *BatchLLMClient
+	// positions holds the known-good moves from PREWARM_PGN_FILE (nil when unset)
+	positions *PositionCache
+	// discord posts finished games to DISCORD_WEBHOOK_URL (nil when unset)
+	discord *DiscordNotifier
+	// delay holds back replies by the MIN/MAX_AUTOPLAY_DELAY_MS (nil without a delay)
+	delay *AutoplayDelay
+	// moveLog appends every bot move to MOVE_LOG_CSV (nil when unset)
+	moveLog *MoveLogger
+	// results keeps the finished games for rematches
+	results *GameResults
+
+	mu    sync.Mutex
+	games map[string]*Game // nil until the game's gameFull event arrives
+	wg    sync.WaitGroup
+}
+
+// NewBot creates a bot playing as account
+func NewBot(cfg *BotConfig, account *BotAccount) *Bot {
+	b := &Bot{
+		cfg:     cfg,
+		botID:   account.ID,
+		botName: account.Username,
+		games:   make(map[string]*Game),
+		results: NewGameResults(cfg),
+	}
+	b.commands = b.newChatCommands()
+	if cfg.PrewarmPGNFile != "" {
+		positions := NewPositionCache()
+		if _, err := prewarmPositionCache(positions, cfg.PrewarmPGNFile); err != nil {
+			log.Printf("Playing without a position cache: %v", err)
+		} else {
+			b.positions = positions
+		}
+	}
+	if cfg.BatchMaxParallel > 0 {
+		b.batch = NewBatchLLMClient(cfg)
+	}
+	if cfg.WebhookURL != "" {
+		b.reporter = NewGameReporter(cfg)
+	}
+	if cfg.MaxAutoplayDelayMS > 0 {
+		b.delay = NewAutoplayDelay(cfg)
+	}
+	if cfg.DiscordWebhookURL != "" {
+		b.discord = NewDiscordNotifier(cfg.DiscordWebhookURL)
+	}
+	if cfg.MoveLogCSV != "" {
+		moveLog, err := NewMoveLogger(cfg.MoveLogCSV)
+		if err != nil {
+			log.Printf("Not logging moves to %s: %v", cfg.MoveLogCSV, err)
+		} else {
+			b.moveLog = moveLog
+		}
+	}
+	return b
+}
+
+// StartGame plays gameID in the background. It returns false if the game is already being played.
+func (b *Bot) StartGame(ctx context.Context, gameID string) bool {
+	b.mu.Lock()
+	if _, ok := b.games[gameID]; ok {
+		b.mu.Unlock()
+		return false
+	}
+	b.games[gameID] = nil
+	b.mu.Unlock()
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer b.removeGame(gameID)
+		b.playGame(ctx, gameID)
+	}()
+	return true
+}
+
+// IsActive reports whether gameID is being played
+func (b *Bot) IsActive(gameID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.games[gameID]
+	return ok
+}
+
+// ActiveGames returns the number of games being played
+func (b *Bot) ActiveGames() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.games)
+}
+
+// Wait blocks until every game goroutine has returned
+func (b *Bot) Wait() {
+	b.wg.Wait()
+}
+
+// Close releases the bot's files once its games are over
+func (b *Bot) Close() error {
+	if b.moveLog == nil {
+		return nil
+	}
+	return b.moveLog.Close()
+}
+
+func (b *Bot) setGame(game *Game) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.games[game.ID] = game
+}
+
+func (b *Bot) removeGame(gameID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.games, gameID)
+}
+
+// playGame follows a game's stream and moves whenever it is the bot's turn, until the game ends
+func (b *Bot) playGame(ctx context.Context, gameID string) {
+	var game *Game
+	var timeout *GameTimeout
+	var idle *time.Timer
+	defer func() {
+		if timeout != nil {
+			timeout.Stop()
+		}
+		if idle != nil {
+			idle.Stop()
+		}
+	}()
+
+	logger := gameEventLogger(b.cfg, gameID)
+	if b.cfg.GameLogDir != "" {
+		gameLog, err := newGameLogger(b.cfg.GameLogDir, gameID)
+		if err != nil {
+			log.Printf("Logging game %s to the global log: %v", gameID, err)
+		} else {
+			logger = gameLog.Logger
+			defer func() {
+				if err := gameLog.Close(); err != nil {
+					log.Printf("Failed to close the log of game %s: %v", gameID, err)
+				}
+			}()
+		}
+	}
+
+	err := streamGameEvents(ctx, b.cfg, gameID, logger, func(event map[string]interface{}) bool {
+		var state map[string]interface{}
+		switch event["type"] {
+		case "gameFull":
+			if game == nil {
+				g, err := newGameFromFull(event, b.botID)
+				if err != nil {
+					log.Printf("Cannot play game %s: %v", gameID, err)
+					return false
+				}
+				game = g
+				if b.cfg.GameLogDir != "" {
+					game.logger = logger
+				}
+				b.initGame(game)
+				b.setGame(game)
+				log.Printf("Playing game %s as %s against %s", game.ID, game.Color, game.Opponent.Name)
+				timeout = b.startGameTimeout(game)
+				idle = b.startIdleAbort(game)
+				// Not after a restart in the middle of the game
+				if game.MoveCount() < 2 && !game.IsOver() {
+					b.sendChat(game, ChatMsgGreeting)
+				}
+				break
+			}
+			// Sent again after a reconnect; only the state can have changed
+			state, _ = event["state"].(map[string]interface{})
+		case "gameState":
+			if game == nil {
+				return true
+			}
+			state = event
+		case "chatLine":
+			if game != nil {
+				b.respondToChat(game, event)
+			}
+			return true
+		default:
+			return true
+		}
+
+		if state != nil {
+			played := game.MoveCount()
+			if err := game.Update(state); err != nil {
+				game.logf("Skipping invalid game state in game %s: %v", gameID, err)
+				return true
+			}
+			if game.MoveCount() != played && timeout != nil && b.cfg.ResetTimeoutOnMove {
+				timeout.Reset()
+			}
+			if idle != nil {
+				if game.MoveCount() > 0 {
+					idle.Stop()
+				} else {
+					idle.Reset(b.abortIdleDuration())
+				}
+			}
+		}
+		b.sendClockWarnings(game)
+
+		if game.IsOver() {
+			return false
+		}
+		if err := b.checkAndMakeMove(game); err != nil {
+			game.logf("Failed to move in game %s: %v", gameID, err)
+		}
+		return true
+	})
+	if err != nil {
+		log.Printf("Stopped following game %s: %v", gameID, err)
+	}
+	if game != nil && game.IsOver() {
+		status, _ := game.Status()
+		log.Printf("Game %s finished: %s (outcome: %s)", gameID, status, game.Outcome())
+		b.finishGame(game)
+	}
+}
+
+// finishGame says goodbye in the chat, saves the game record to GAME_PGN_DIR and reports
+// a finished game to the webhook and Discord in the background. Aborted games have no
+// result and are skipped.
+func (b *Bot) finishGame(game *Game) {
+	outcome := game.Outcome()
+	if outcome == "" {
+		return
+	}
+	b.results.Add(GameResult{
+		GameID:         game.ID,
+		Opponent:       game.Opponent.ID,
+		Color:          game.Color,
+		Rated:          game.Rated,
+		ClockLimit:     game.ClockLimit,
+		ClockIncrement: game.ClockIncrement,
+		Outcome:        outcome,
+	})
+	b.sendChat(game, ChatMsgGameEnd)
+	if b.cfg.GamePGNDir != "" {
+		if err := b.saveGamePGN(game); err != nil {
+			game.logf("Failed to save the PGN of game %s: %v", game.ID, err)
+		}
+	}
+	report := GameReport{
+		GameID:          game.ID,
+		Outcome:         outcome,
+		Opponent:        game.Opponent.Name,
+		MoveCount:       game.MoveCount(),
+		DurationSeconds: int(time.Since(game.StartedAt).Seconds()),
+		URL:             lichessGameURL(b.cfg.LichessBaseURL, game.ID),
+	}
+	if b.reporter != nil {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.reporter.Report(report)
+		}()
+	}
+	if b.discord != nil {
+		summary := GameSummary{GameReport: report, TimeControl: game.TimeControl, BotColor: game.Color, Moves: game.Moves()}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			if err := b.discord.Post(summary); err != nil {
+				game.logf("Failed to post the summary of game %s to Discord: %v", game.ID, err)
+			}
+		}()
+	}
+}
+
+// initGame sets up the per-game helpers the configuration asks for
+func (b *Bot) initGame(game *Game) {
+	game.llmCalls = NewLLMCallBreaker(game.ID, b.cfg.MaxLLMCallsPerGame)
+	game.batch = b.batch
+	game.positions = b.positions
+	game.timeScrambleMS = b.cfg.TimeScrambleThresholdMS
+	game.personality = gamePersonality(b.cfg.Personality, game.BotRating, game.Opponent.Rating)
+	if len(b.cfg.TestMoveSequence) > 0 {
+		game.scripted = NewScriptedMoves(b.cfg.TestMoveSequence)
+	}
+	if b.cfg.PonderMode {
+		game.PonderCache = NewPonderCache()
+	}
+	if len(b.cfg.ChatResponseMap) > 0 {
+		game.chat = NewChatResponder(b.cfg.ChatResponseMap)
+	}
+	if b.cfg.OfferDrawAfterNMoves > 0 {
+		game.drawOffer = NewDrawOfferTracker(b.cfg.OfferDrawAfterNMoves)
+	}
+	if len(b.cfg.ClockWarningThresholdsMS) > 0 {
+		game.clockMonitor = NewClockMonitor(b.cfg.ClockWarningThresholdsMS)
+	}
+}
+
+// sendClockWarnings tells the player chat when either clock drops below one of the
+// CLOCK_WARNING_THRESHOLDS_MS for the first time
+func (b *Bot) sendClockWarnings(game *Game) {
+	if game.clockMonitor == nil || !game.HasClock() || game.IsOver() {
+		return
+	}
+	clocks := []struct {
+		player      string
+		remainingMs int
+	}{
+		{b.botName, game.BotClockMS()},
+		{game.Opponent.Name, game.OpponentClockMS()},
+	}
+	for _, clock := range clocks {
+		for _, threshold := range game.clockMonitor.Check(clock.player, clock.remainingMs) {
+			text := formatClockWarning(b.cfg.ClockWarningMessage, clock.player, threshold)
+			if err := sendChatMessage(b.cfg, game.ID, "player", text); err != nil {
+				game.logf("Failed to send clock warning in game %s: %v", game.ID, err)
+			}
+		}
+	}
+}
+
+// gamePGN renders game as PGN with the GAME_TAG_FILE tags. The roster tags describing
+// the game itself take precedence over custom tags of the same name.
+func (b *Bot) gamePGN(game *Game) (string, error) {
+	tags := make(map[string]string, len(b.cfg.GameTags)+7)
+	for name, value := range b.cfg.GameTags {
+		tags[name] = value
+	}
+	event := "Casual " + game.Speed + " game"
+	if game.Rated {
+		event = "Rated " + game.Speed + " game"
+	}
+	white, black := b.botName, game.Opponent.Name
+	if game.Color == "black" {
+		white, black = black, white
+	}
+	status, winner := game.Status()
+	tags["Event"] = event
+	tags["Site"] = lichessGameURL(b.cfg.LichessBaseURL, game.ID)
+	tags["Date"] = game.StartedAt.Format("2006.01.02")
+	tags["Round"] = "-"
+	tags["White"], tags["Black"] = white, black
+	tags["Result"] = pgnResult(status, winner)
+	return formatPGN(tags, game.Moves(), game.InitialFEN)
+}
+
+// saveGamePGN writes the record of a finished game to {GAME_PGN_DIR}/{gameID}.pgn
+func (b *Bot) saveGamePGN(game *Game) error {
+	pgn, err := b.gamePGN(game)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(b.cfg.GamePGNDir, 0755); err != nil {
+		return fmt.Errorf("failed to create game PGN dir: %v", err)
+	}
+	return os.WriteFile(filepath.Join(b.cfg.GamePGNDir, game.ID+".pgn"), []byte(pgn), 0644)
+}
+
+// sendChat sends the CHAT_LANGUAGE message for key to the player chat of game
+func (b *Bot) sendChat(game *Game, key string) {
+	text := b.cfg.ChatMessages.Get(b.cfg.ChatLanguage, key)
+	if text == "" {
+		return
+	}
+	if err := sendChatMessage(b.cfg, game.ID, "player", text); err != nil {
+		game.logf("Failed to send %s message in game %s: %v", key, game.ID, err)
+	}
+}
+
+// respondToChat answers a chatLine event in the room it was sent to: "!" commands
+// from CHAT_ADMIN_USERNAMES, and messages containing one of the CHAT_RESPONSE_MAP
+// trigger phrases
+func (b *Bot) respondToChat(game *Game, event map[string]interface{}) {
+	username, _ := event["username"].(string)
+	text, _ := event["text"].(string)
+	room, _ := event["room"].(string)
+	// Never answer the bot's own messages or Lichess system messages
+	if strings.EqualFold(username, b.botID) || strings.EqualFold(username, "lichess") {
+		return
+	}
+	reply, ok := b.commands.Handle(username, text)
+	if !ok && game.chat != nil {
+		reply, ok = game.chat.Respond(username, text)
+	}
+	if !ok {
+		return
+	}
+	if room == "" {
+		room = "player"
+	}
+	if err := sendChatMessage(b.cfg, game.ID, room, reply); err != nil {
+		game.logf("Failed to answer chat in game %s: %v", game.ID, err)
+	}
+}
+
+// newChatCommands creates the "!" commands CHAT_ADMIN_USERNAMES may use in any game chat
+func (b *Bot) newChatCommands() *ChatCommands {
+	commands := NewChatCommands(b.cfg.ChatAdmins)
+	commands.Register("status", func(args []string) string {
+		return fmt.Sprintf("Playing %d game(s)", b.ActiveGames())
+	})
+	return commands
+}
+
+// maxGameDurationUnit is the unit of MAX_GAME_DURATION_MINUTES (a variable so tests can shorten it)
+var maxGameDurationUnit = time.Minute
+
+// startGameTimeout resigns game if it is still running after MAX_GAME_DURATION_MINUTES.
+// It returns nil when no maximum duration is configured.
+func (b *Bot) startGameTimeout(game *Game) *GameTimeout {
+	if b.cfg.MaxGameDurationMinutes <= 0 {
+		return nil
+	}
+	limit := time.Duration(b.cfg.MaxGameDurationMinutes) * maxGameDurationUnit
+	return NewGameTimeout(limit, func() {
+		if game.IsOver() {
+			return
+		}
+		game.logf("Game %s has been running for longer than %d minutes, resigning", game.ID, b.cfg.MaxGameDurationMinutes)
+		if err := resignGame(b.cfg, game.ID); err != nil {
+			game.logf("Failed to resign overlong game %s: %v", game.ID, err)
+		}
+	})
+}
+
+// abortIdleUnit is the unit of ABORT_IDLE_SECONDS (a variable so tests can shorten it)
+var abortIdleUnit = time.Second
+
+func (b *Bot) abortIdleDuration() time.Duration {
+	return time.Duration(b.cfg.AbortIdleSeconds) * abortIdleUnit
+}
+
+// startIdleAbort aborts game if no gameState update arrives for ABORT_IDLE_SECONDS
+// after gameFull while no move has been played, i.e. the opponent joined but never
+// played. The caller resets the timer on every update and stops it after the first move.
+func (b *Bot) startIdleAbort(game *Game) *time.Timer {
+	if b.cfg.AbortIdleSeconds <= 0 || game.MoveCount() > 0 {
+		return nil
+	}
+	return time.AfterFunc(b.abortIdleDuration(), func() {
+		if game.IsOver() || game.MoveCount() > 0 {
+			return
+		}
+		game.logf("No move in game %s for %d seconds, aborting", game.ID, b.cfg.AbortIdleSeconds)
+		if err := abortGame(b.cfg, game.ID); err != nil {
+			game.logf("Failed to abort idle game %s: %v", game.ID, err)
+		}
+	})
+}
+
+// checkAndMakeMove chooses and submits the bot's move if it is the bot's turn in game
+func (b *Bot) checkAndMakeMove(game *Game) error {
+	if !game.IsBotTurn() {
+		return nil
+	}
+	if b.cfg.MoveTimeoutResignBelowMS > 0 && game.HasClock() && game.BotClockMS() < b.cfg.MoveTimeoutResignBelowMS {
+		game.logf("Resigning game %s with %dms left on the clock (threshold %dms)",
+			game.ID, game.BotClockMS(), b.cfg.MoveTimeoutResignBelowMS)
+		return resignGame(b.cfg, game.ID)
+	}
+
+	cfg := b.cfg
+	scramble := game.HasClock() && game.IsTimeScramble(game.BotClockMS())
+	if scramble {
+		game.logf("Time scramble in game %s (%dms left), using the fastest prompt", game.ID, game.BotClockMS())
+		cfg = timeScrambleConfig(cfg)
+	}
+
+	moves := game.Moves()
+	start := time.Now()
+	move, err := b.chooseMove(cfg, game)
+	if errors.Is(err, ErrLLMCallLimit) && b.cfg.LLMCircuitBreakerAction == BreakerActionResign {
+		game.logf("Resigning game %s after %d LLM calls", game.ID, game.llmCalls.Calls())
+		return resignGame(b.cfg, game.ID)
+	}
+	if err != nil {
+		return err
+	}
+	latency := time.Since(start)
+	// The delay only hides instant replies; it is not worth losing on time for
+	if b.delay != nil && !scramble {
+		b.delay.Wait(game.LastMoveAt())
+	}
+	offerDraw := b.shouldOfferDraw(cfg, game, append(moves, move))
+	if err := submitMove(b.cfg, game.ID, moves, game.InitialFEN, move, offerDraw); err != nil {
+		return err
+	}
+	if offerDraw {
+		game.logf("Offered a draw in game %s", game.ID)
+		b.sendChat(game, ChatMsgDrawOffer)
+	}
+	b.logMove(cfg, game, moves, move, latency)
+	b.startAnalysis(cfg, game, moves, move, latency)
+	b.startExplanation(game, moves, move)
+	b.startPondering(game, append(moves, move))
+	return nil
+}
+
+// shouldOfferDraw reports whether the bot's move should carry its one draw offer of
+// the game: after DRAW_AFTER_N_MOVES moves, when Stockfish rates the position after
+// the move as roughly equal. A time scramble leaves no time for the evaluation.
+func (b *Bot) shouldOfferDraw(cfg *BotConfig, game *Game, after []string) bool {
+	if game.drawOffer == nil || (game.HasClock() && game.IsTimeScramble(game.BotClockMS())) {
+		return false
+	}
+	return game.drawOffer.ShouldOffer(game.MoveCount(), func() (int, error) {
+		return evaluateWithStockfish(cfg, after, game.InitialFEN, cfg.StockfishDepth)
+	})
+}
+
+// logMove appends the bot's move to MOVE_LOG_CSV. The model column names the engine
+// for Stockfish moves, and the attempt is 0 for moves chosen without asking the LLM.
+func (b *Bot) logMove(cfg *BotConfig, game *Game, moves []string, move string, latency time.Duration) {
+	if b.moveLog == nil {
+		return
+	}
+	fen, err := movesToFEN(moves, game.InitialFEN)
+	if err != nil {
+		game.logf("Not logging move %s in game %s: %v", move, game.ID, err)
+		return
+	}
+	model := b.moveModel(game)
+	if cfg.Engine == EngineStockfish {
+		model = EngineStockfish
+	}
+	err = b.moveLog.Log(MoveRecord{
+		Timestamp:  time.Now(),
+		GameID:     game.ID,
+		MoveNumber: len(moves)/2 + 1,
+		Color:      game.Color,
+		UCIMove:    move,
+		FENBefore:  fen,
+		LLMModel:   model,
+		LatencyMS:  latency.Milliseconds(),
+		Attempt:    game.LLMReply(len(moves)).Attempts,
+	})
+	if err != nil {
+		game.logf("Failed to log move %s in game %s: %v", move, game.ID, err)
+	}
+}
+
+// startAnalysis compares the LLM's move with the Stockfish best move in the
+// background and logs the result, when ANALYSIS_MODE is set
+func (b *Bot) startAnalysis(cfg *BotConfig, game *Game, moves []string, move string, latency time.Duration) {
+	if !cfg.AnalysisMode || cfg.Engine == EngineStockfish {
+		return
+	}
+	mode := PromptModeSingle
+	if cfg.LLMMultiStep {
+		mode = PromptModeMultiStep
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		analysis, err := analyzeMove(cfg, game.ID, moves, game.InitialFEN, move)
+		if err != nil {
+			game.logf("Analysis of move %s in game %s failed: %v", move, game.ID, err)
+			return
+		}
+		analysis.Reasoning = game.LLMReply(len(moves)).Reasoning
+		analysis.PromptMode = mode
+		analysis.LatencyMS = latency.Milliseconds()
+		logMoveAnalysis(analysis)
+	}()
+}
+
+// startExplanation sends the LLM's one-sentence explanation of move to the player
+// chat in the background, when EXPLAIN_MOVES is set
+func (b *Bot) startExplanation(game *Game, moves []string, move string) {
+	if !b.cfg.ExplainMoves || b.cfg.DisableLLM || (game.llmCalls != nil && game.llmCalls.Tripped()) {
+		return
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		explanation, err := explainMove(b.cfg, moves, game.InitialFEN, move)
+		if err == nil && explanation != "" {
+			err = sendChatMessage(b.cfg, game.ID, "player", explanation)
+		}
+		if err != nil {
+			game.logf("Failed to explain move %s in game %s: %v", move, game.ID, err)
+		}
+	}()
+}
+
+// startPondering works out the reply to the opponent's most likely answer to the
+// position after moves in the background, when PONDER_MODE is set
+func (b *Bot) startPondering(game *Game, moves []string) {
+	if game.PonderCache == nil || b.cfg.DisableLLM || b.cfg.Engine == EngineStockfish {
+		return
+	}
+	model := b.moveModel(game)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		if err := ponderReply(b.cfg, game, moves, model); err != nil {
+			game.logf("Pondering failed in game %s: %v", game.ID, err)
+		}
+	}()
+}
+
+// chooseMove asks the configured engine for the bot's move, using cfg for the request
+// (the bot's configuration or its time scramble variant)
+func (b *Bot) chooseMove(cfg *BotConfig, game *Game) (string, error) {
+	if cfg.Engine == EngineStockfish {
+		return getBestMoveFromStockfish(cfg, game.Moves(), game.InitialFEN, cfg.StockfishDepth)
+	}
+	move, err := getBestMoveFromLLM(cfg, game, b.moveModel(game))
+	if errors.Is(err, ErrLLMCallLimit) && cfg.LLMCircuitBreakerAction == BreakerActionRandom {
+		game.logf("LLM call limit reached in game %s, playing a random move", game.ID)
+		return randomLegalMove(game.Moves(), game.InitialFEN, rand.Intn)
+	}
+	return move, err
+}
+
+// moveModel returns the LLM model for the bot's next move: FastModel once the bot's
+// clock is below MOVE_TIMEOUT_REQUEST_FAST_MODEL_MS, the main model otherwise
+func (b *Bot) moveModel(game *Game) string {
+	threshold := b.cfg.MoveTimeoutRequestFastModelMS
+	if threshold > 0 && b.cfg.FastModel != "" && game.HasClock() && game.BotClockMS() < threshold {
+		return b.cfg.FastModel
+	}
+	return b.cfg.OpenRouterModel
+}
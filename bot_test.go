@@ -0,0 +1,720 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lichess-bot-agent/lichessmock"
+)
+
+// waitUntil polls cond until it holds, failing the test after a few seconds
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newTestBot returns a bot playing random moves against a mock Lichess
+func newTestBot(t *testing.T) (*Bot, *lichessmock.MockLichessServer) {
+	t.Helper()
+	mock := lichessmock.NewServer()
+	t.Cleanup(mock.Close)
+	cfg := newTestLichessConfig(mock.URL())
+	cfg.DisableLLM = true
+	cfg.MaxIllegalMoveRetries = 1
+	return NewBot(cfg, &BotAccount{ID: "mockbot", Username: "MockBot", Title: "BOT"}), mock
+}
+
+// testGameFull is a gameFull event for a standard game with the mock bot as color
+func testGameFull(gameID, color, moves string) map[string]interface{} {
+	bot := map[string]interface{}{"id": "mockbot", "name": "MockBot", "title": "BOT"}
+	opponent := map[string]interface{}{"id": "opponent", "name": "Opponent", "rating": 1500}
+	event := map[string]interface{}{
+		"type": "gameFull", "id": gameID, "initialFen": "startpos", "speed": "blitz",
+		"white": bot, "black": opponent,
+		"state": map[string]interface{}{"type": "gameState", "moves": moves, "wtime": 180000, "btime": 180000, "status": "started"},
+	}
+	if color == "black" {
+		event["white"], event["black"] = opponent, bot
+	}
+	return event
+}
+
+func TestBot_PlaysUntilGameEnds(t *testing.T) {
+	bot, mock := newTestBot(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mock.InjectGameEvent("game1", testGameFull("game1", "black", "e2e4"))
+	if !bot.StartGame(ctx, "game1") {
+		t.Fatal("Expected StartGame to start a new game")
+	}
+	if bot.StartGame(ctx, "game1") {
+		t.Error("Expected StartGame to refuse a game that is already running")
+	}
+
+	waitUntil(t, "the bot's first move", func() bool { return len(mock.Moves("game1")) == 1 })
+	reply := mock.Moves("game1")[0]
+	if !isLegalMove([]string{"e2e4"}, "", reply) {
+		t.Errorf("Expected a legal reply to e2e4, got %s", reply)
+	}
+
+	// The opponent's move is not answered until it is the bot's turn again
+	mock.InjectGameEvent("game1", map[string]interface{}{"type": "gameState", "moves": "e2e4 " + reply, "status": "started"})
+	mock.InjectGameEvent("game1", map[string]interface{}{"type": "gameState", "moves": "e2e4 " + reply, "status": "resign", "winner": "white"})
+	waitUntil(t, "the game to end", func() bool { return bot.ActiveGames() == 0 })
+	if moves := mock.Moves("game1"); len(moves) != 1 {
+		t.Errorf("Expected exactly one bot move, got %v", moves)
+	}
+	bot.Wait()
+}
+
+func TestBot_StopsOnInvalidGameFull(t *testing.T) {
+	bot, mock := newTestBot(t)
+	event := testGameFull("game2", "white", "")
+	event["white"] = map[string]interface{}{"id": "someone-else"}
+	mock.InjectGameEvent("game2", event)
+
+	bot.StartGame(context.Background(), "game2")
+	waitUntil(t, "the game to be dropped", func() bool { return !bot.IsActive("game2") })
+	mock.AssertMoves(t, "game2")
+}
+
+func TestBot_ResignsBelowClockThreshold(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.MoveTimeoutResignBelowMS = 2000
+
+	event := testGameFull("game3", "white", "")
+	event["state"].(map[string]interface{})["wtime"] = 1500
+	mock.InjectGameEvent("game3", event)
+	bot.StartGame(context.Background(), "game3")
+
+	waitUntil(t, "the resignation", func() bool { return len(mock.ResignedGames()) == 1 })
+	mock.AssertMoves(t, "game3")
+	mock.InjectGameEvent("game3", map[string]interface{}{"type": "gameState", "moves": "", "status": "resign", "winner": "black"})
+	bot.Wait()
+}
+
+func TestBot_MoveModel(t *testing.T) {
+	bot, _ := newTestBot(t)
+	bot.cfg.OpenRouterModel = "openai/gpt-4o"
+	bot.cfg.FastModel = "openai/gpt-4o-mini"
+	bot.cfg.MoveTimeoutRequestFastModelMS = 15000
+
+	tests := []struct {
+		state    map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"moves": "", "status": "started", "wtime": 60000.0, "btime": 60000.0}, "openai/gpt-4o"},
+		{map[string]interface{}{"moves": "", "status": "started", "wtime": 14000.0, "btime": 60000.0}, "openai/gpt-4o-mini"},
+		// Correspondence and unlimited games have no clock to run out of
+		{map[string]interface{}{"moves": "", "status": "started"}, "openai/gpt-4o"},
+	}
+	for _, tt := range tests {
+		game := &Game{ID: "g", Color: "white", whiteStarts: true}
+		game.Update(tt.state)
+		if got := bot.moveModel(game); got != tt.expected {
+			t.Errorf("moveModel() with state %v: expected %s, got %s", tt.state, tt.expected, got)
+		}
+	}
+}
+
+func TestBot_ResignsAfterMaxGameDuration(t *testing.T) {
+	original := maxGameDurationUnit
+	maxGameDurationUnit = 50 * time.Millisecond
+	defer func() { maxGameDurationUnit = original }()
+
+	bot, mock := newTestBot(t)
+	bot.cfg.MaxGameDurationMinutes = 1
+	// The opponent is to move and never does
+	mock.InjectGameEvent("game4", testGameFull("game4", "black", ""))
+	bot.StartGame(context.Background(), "game4")
+
+	waitUntil(t, "the overlong game to be resigned", func() bool { return len(mock.ResignedGames()) == 1 })
+	mock.InjectGameEvent("game4", map[string]interface{}{"type": "gameState", "moves": "", "status": "resign", "winner": "white"})
+	bot.Wait()
+}
+
+func TestBot_ResetTimeoutOnMove(t *testing.T) {
+	original := maxGameDurationUnit
+	maxGameDurationUnit = 200 * time.Millisecond
+	defer func() { maxGameDurationUnit = original }()
+
+	bot, mock := newTestBot(t)
+	bot.cfg.MaxGameDurationMinutes = 1
+	bot.cfg.ResetTimeoutOnMove = true
+	mock.InjectGameEvent("game5", testGameFull("game5", "black", ""))
+	bot.StartGame(context.Background(), "game5")
+
+	// Moves every 100ms keep restarting the 200ms countdown. Each state ends with
+	// white to move, so the bot (black) never has to answer.
+	played := ""
+	for _, pair := range []string{"e2e4 e7e5", "g1f3 b8c6", "f1c4 f8c5", "e1g1 g8f6"} {
+		time.Sleep(100 * time.Millisecond)
+		played += pair + " "
+		mock.InjectGameEvent("game5", map[string]interface{}{"type": "gameState", "moves": played, "status": "started"})
+	}
+	time.Sleep(100 * time.Millisecond)
+	if resigned := mock.ResignedGames(); len(resigned) != 0 {
+		t.Errorf("Expected no resignation while moves keep coming, got %v", resigned)
+	}
+
+	waitUntil(t, "the resignation once moves stop", func() bool { return len(mock.ResignedGames()) == 1 })
+	mock.InjectGameEvent("game5", map[string]interface{}{"type": "gameState", "moves": played, "status": "resign", "winner": "white"})
+	bot.Wait()
+}
+
+func TestBot_AbortsIdleGameWithoutMoves(t *testing.T) {
+	original := abortIdleUnit
+	abortIdleUnit = 50 * time.Millisecond
+	defer func() { abortIdleUnit = original }()
+
+	bot, mock := newTestBot(t)
+	bot.cfg.AbortIdleSeconds = 2
+	mock.InjectGameEvent("game6", testGameFull("game6", "black", ""))
+	bot.StartGame(context.Background(), "game6")
+
+	waitUntil(t, "the idle game to be aborted", func() bool { return len(mock.AbortedGames()) == 1 })
+	mock.InjectGameEvent("game6", map[string]interface{}{"type": "gameState", "moves": "", "status": "aborted"})
+	bot.Wait()
+}
+
+func TestBot_DoesNotAbortGameWithMoves(t *testing.T) {
+	original := abortIdleUnit
+	abortIdleUnit = 50 * time.Millisecond
+	defer func() { abortIdleUnit = original }()
+
+	bot, mock := newTestBot(t)
+	bot.cfg.AbortIdleSeconds = 2
+	mock.InjectGameEvent("game7", testGameFull("game7", "black", ""))
+	bot.StartGame(context.Background(), "game7")
+	mock.InjectGameEvent("game7", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "started"})
+
+	time.Sleep(300 * time.Millisecond)
+	if aborted := mock.AbortedGames(); len(aborted) != 0 {
+		t.Errorf("Expected no abort once moves were played, got %v", aborted)
+	}
+	mock.InjectGameEvent("game7", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "draw"})
+	bot.Wait()
+}
+
+func TestBot_LLMCallBreaker(t *testing.T) {
+	for _, action := range []string{BreakerActionRandom, BreakerActionResign} {
+		t.Run(action, func(t *testing.T) {
+			var calls atomic.Int64
+			withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e2e5"}}]}`))
+			})
+
+			bot, mock := newTestBot(t)
+			bot.cfg.DisableLLM = false
+			bot.cfg.MaxIllegalMoveRetries = 3
+			bot.cfg.MaxLLMCallsPerGame = 4
+			bot.cfg.LLMCircuitBreakerAction = action
+
+			// The first turn uses three calls on illegal moves; the repeated state
+			// gets one more call before the breaker trips
+			mock.InjectGameEvent("loop", testGameFull("loop", "white", ""))
+			mock.InjectGameEvent("loop", map[string]interface{}{"type": "gameState", "moves": "", "status": "started"})
+			bot.StartGame(context.Background(), "loop")
+
+			if action == BreakerActionRandom {
+				waitUntil(t, "the random fallback move", func() bool { return len(mock.Moves("loop")) == 1 })
+				if move := mock.Moves("loop")[0]; !isLegalMove(nil, "", move) {
+					t.Errorf("Expected a legal random move, got %s", move)
+				}
+			} else {
+				waitUntil(t, "the resignation", func() bool { return len(mock.ResignedGames()) == 1 })
+				mock.AssertMoves(t, "loop")
+			}
+			if n := calls.Load(); n != 4 {
+				t.Errorf("Expected 4 LLM calls before the breaker tripped, got %d", n)
+			}
+			mock.InjectGameEvent("loop", map[string]interface{}{"type": "gameState", "moves": "", "status": "aborted"})
+			bot.Wait()
+		})
+	}
+}
+
+func TestBot_SendsClockWarnings(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.ClockWarningThresholdsMS = []int{30000, 10000}
+	bot.cfg.ClockWarningMessage = defaultClockWarningMessage
+
+	// White (the opponent) is to move, so the bot only watches the clocks
+	event := testGameFull("clock", "black", "")
+	event["state"].(map[string]interface{})["btime"] = 25000
+	mock.InjectGameEvent("clock", event)
+	bot.StartGame(context.Background(), "clock")
+	waitUntil(t, "the bot's clock warning", func() bool { return len(mock.ChatMessages("clock")) == 1 })
+
+	mock.InjectGameEvent("clock", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "wtime": 5000, "btime": 24000, "status": "started"})
+	waitUntil(t, "the opponent's clock warnings", func() bool { return len(mock.ChatMessages("clock")) == 3 })
+
+	expected := []string{
+		"player: MockBot has less than 30 seconds left",
+		"player: Opponent has less than 30 seconds left",
+		"player: Opponent has less than 10 seconds left",
+	}
+	if got := mock.ChatMessages("clock"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected clock warnings %q, got %q", expected, got)
+	}
+	mock.InjectGameEvent("clock", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "outoftime", "winner": "black"})
+	bot.Wait()
+}
+
+func TestBot_RespondsToChat(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.ChatResponseMap = map[string]string{"good game": "Thanks {username}, well played!"}
+
+	mock.InjectGameEvent("chat", testGameFull("chat", "black", ""))
+	bot.StartGame(context.Background(), "chat")
+	chat := func(username, text string) {
+		mock.InjectGameEvent("chat", map[string]interface{}{"type": "chatLine", "room": "player", "username": username, "text": text})
+	}
+	chat("mockbot", "Good game")
+	chat("Opponent", "Good game!")
+	chat("Opponent", "good game again")
+	waitUntil(t, "the chat reply", func() bool { return len(mock.ChatMessages("chat")) > 0 })
+
+	mock.InjectGameEvent("chat", map[string]interface{}{"type": "gameState", "moves": "", "status": "aborted"})
+	bot.Wait()
+	expected := []string{"player: Thanks Opponent, well played!"}
+	if got := mock.ChatMessages("chat"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected chat %q, got %q", expected, got)
+	}
+}
+
+func TestBot_ReportsFinishedGame(t *testing.T) {
+	reports := make(chan GameReport, 2)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report GameReport
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("Failed to decode report: %v", err)
+		}
+		reports <- report
+	}))
+	defer webhook.Close()
+
+	bot, mock := newTestBot(t)
+	bot.cfg.WebhookURL = webhook.URL
+	bot.reporter = NewGameReporter(bot.cfg)
+
+	mock.InjectGameEvent("won", testGameFull("won", "black", ""))
+	bot.StartGame(context.Background(), "won")
+	mock.InjectGameEvent("won", map[string]interface{}{"type": "gameState", "moves": "f2f3 e7e5 g2g4 d8h4", "status": "mate", "winner": "black"})
+	mock.InjectGameEvent("aborted", testGameFull("aborted", "black", ""))
+	bot.StartGame(context.Background(), "aborted")
+	mock.InjectGameEvent("aborted", map[string]interface{}{"type": "gameState", "moves": "", "status": "aborted"})
+	bot.Wait()
+
+	if len(reports) != 1 {
+		t.Fatalf("Expected only the finished game to be reported, got %d reports", len(reports))
+	}
+	report := <-reports
+	expected := GameReport{GameID: "won", Outcome: OutcomeWin, Opponent: "Opponent", MoveCount: 4, URL: mock.URL() + "/won"}
+	if report != expected {
+		t.Errorf("Expected report %+v, got %+v", expected, report)
+	}
+}
+
+func TestBot_WritesGameLog(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.GameLogDir = t.TempDir()
+	bot.cfg.MoveTimeoutResignBelowMS = 2000
+
+	event := testGameFull("logged", "white", "")
+	event["state"].(map[string]interface{})["wtime"] = 1500
+	mock.InjectGameEvent("logged", event)
+	bot.StartGame(context.Background(), "logged")
+	waitUntil(t, "the resignation", func() bool { return len(mock.ResignedGames()) == 1 })
+	mock.InjectGameEvent("logged", map[string]interface{}{"type": "gameState", "moves": "", "status": "resign", "winner": "black"})
+	bot.Wait()
+
+	file, err := openGameRecord(filepath.Join(bot.cfg.GameLogDir, "logged.log.gz"))
+	if err != nil {
+		t.Fatalf("Expected a compressed game log: %v", err)
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read the game log: %v", err)
+	}
+	for _, expected := range []string{"game_id=logged", `msg="game event"`, "gameFull", "Resigning game logged with 1500ms left"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected the game log to contain '%s', got: %s", expected, content)
+		}
+	}
+}
+
+func TestBot_SendsChatMessagesInLanguage(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.ChatLanguage = "de"
+	bot.cfg.ChatMessages = defaultChatMessages()
+	bot.cfg.ChatMessages["de"] = map[string]string{ChatMsgGreeting: "Hallo! Viel Spaß!"}
+
+	mock.InjectGameEvent("greet", testGameFull("greet", "black", ""))
+	bot.StartGame(context.Background(), "greet")
+	mock.InjectGameEvent("greet", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "resign", "winner": "black"})
+	// A game joined after a restart is not greeted again
+	mock.InjectGameEvent("resumed", testGameFull("resumed", "black", "e2e4 e7e5"))
+	bot.StartGame(context.Background(), "resumed")
+	mock.InjectGameEvent("resumed", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "aborted"})
+	bot.Wait()
+
+	expected := []string{"player: Hallo! Viel Spaß!", "player: Good game, thanks for playing!"}
+	if got := mock.ChatMessages("greet"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected chat %q, got %q", expected, got)
+	}
+	if got := mock.ChatMessages("resumed"); len(got) != 0 {
+		t.Errorf("Expected no chat in the resumed and aborted game, got %q", got)
+	}
+}
+
+func TestBot_ExplainsMoves(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"It fights for the centre and frees the bishop."}}]}`))
+	})
+	bot, mock := newTestBot(t)
+	bot.cfg.DisableLLM = false
+	bot.cfg.TestMoveSequence = []string{"e7e5"}
+	bot.cfg.ExplainMoves = true
+	bot.cfg.MaxExplanationLength = 20
+
+	mock.InjectGameEvent("explain", testGameFull("explain", "black", "e2e4"))
+	bot.StartGame(context.Background(), "explain")
+	waitUntil(t, "the explanation", func() bool { return len(mock.ChatMessages("explain")) == 1 })
+	mock.InjectGameEvent("explain", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "aborted"})
+	bot.Wait()
+
+	if got := mock.ChatMessages("explain")[0]; got != "player: It fights for the..." {
+		t.Errorf("Expected the truncated explanation in the player chat, got '%s'", got)
+	}
+}
+
+func TestBot_SavesGamePGN(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.GamePGNDir = filepath.Join(t.TempDir(), "games")
+	bot.cfg.GameTags = map[string]string{"style": "aggressive", "White": "not the roster"}
+
+	mock.InjectGameEvent("pgn", testGameFull("pgn", "black", ""))
+	bot.StartGame(context.Background(), "pgn")
+	mock.InjectGameEvent("pgn", map[string]interface{}{"type": "gameState", "moves": "f2f3 e7e5 g2g4 d8h4", "status": "mate", "winner": "black"})
+	bot.Wait()
+
+	file, err := openGameRecord(filepath.Join(bot.cfg.GamePGNDir, "pgn.pgn"))
+	if err != nil {
+		t.Fatalf("Expected a game record: %v", err)
+	}
+	defer file.Close()
+	games, err := parsePGN(file)
+	if err != nil || len(games) != 1 {
+		t.Fatalf("Expected one game in the record, got %v (%v)", games, err)
+	}
+	tags := games[0].Tags
+	if tags["White"] != "Opponent" || tags["Black"] != "MockBot" || tags["Result"] != "0-1" || tags["style"] != "aggressive" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+	if tags["Event"] != "Casual blitz game" || tags["Site"] != mock.URL()+"/pgn" {
+		t.Errorf("Unexpected event tags %v", tags)
+	}
+	if got := strings.Join(games[0].Moves, " "); got != "f3 e5 g4 Qh4#" {
+		t.Errorf("Expected the game's moves in SAN, got '%s'", got)
+	}
+}
+
+func TestBot_KeepsResultForRematch(t *testing.T) {
+	bot, mock := newTestBot(t)
+	event := testGameFull("result", "white", "")
+	event["rated"] = true
+	event["clock"] = map[string]interface{}{"initial": 300000, "increment": 3000}
+
+	mock.InjectGameEvent("result", event)
+	bot.StartGame(context.Background(), "result")
+	mock.InjectGameEvent("result", map[string]interface{}{"type": "gameState", "moves": "e2e4", "status": "resign", "winner": "white"})
+	bot.Wait()
+
+	result, ok := bot.results.Get("result")
+	expected := GameResult{GameID: "result", Opponent: "opponent", Color: "white", Rated: true, ClockLimit: 300, ClockIncrement: 3, Outcome: OutcomeWin}
+	if !ok || result != expected {
+		t.Errorf("Expected result %+v, got %+v (found %v)", expected, result, ok)
+	}
+}
+
+func TestBot_TimeScrambleSkipsChainOfThought(t *testing.T) {
+	var prompts []string
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Messages[0].Content)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e2e4"}}]}`))
+	})
+
+	bot, mock := newTestBot(t)
+	bot.cfg.DisableLLM = false
+	bot.cfg.LLMChainOfThought = true
+	bot.cfg.TimeScrambleThresholdMS = 10000
+
+	event := testGameFull("scramble", "white", "")
+	event["state"].(map[string]interface{})["wtime"] = 5000
+	mock.InjectGameEvent("scramble", event)
+	bot.StartGame(context.Background(), "scramble")
+
+	waitUntil(t, "the bot's move", func() bool { return len(mock.Moves("scramble")) == 1 })
+	if len(prompts) != 1 || strings.Contains(prompts[0], chainOfThoughtInstruction) {
+		t.Errorf("Expected a single prompt without chain-of-thought in a time scramble, got %q", prompts)
+	}
+	mock.InjectGameEvent("scramble", map[string]interface{}{"type": "gameState", "moves": "e2e4", "status": "outoftime", "winner": "black"})
+	bot.Wait()
+}
+
+func TestBot_LogsMovesToCSV(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e7e5"}}]}`))
+	})
+	bot, mock := newTestBot(t)
+	bot.cfg.DisableLLM = false
+	bot.cfg.OpenRouterModel = "openai/gpt-4o"
+	path := filepath.Join(t.TempDir(), "moves.csv")
+	bot.cfg.MoveLogCSV = path
+	bot = NewBot(bot.cfg, &BotAccount{ID: "mockbot", Username: "MockBot"})
+
+	mock.InjectGameEvent("csv", testGameFull("csv", "black", "e2e4"))
+	bot.StartGame(context.Background(), "csv")
+	waitUntil(t, "the bot's move", func() bool { return len(mock.Moves("csv")) == 1 })
+	mock.InjectGameEvent("csv", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "aborted"})
+	bot.Wait()
+	if err := bot.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected the move log: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("Expected a header and one row, got %q (%v)", rows, err)
+	}
+	row := rows[1]
+	expected := []string{"csv", "1", "black", "e7e5", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", "openai/gpt-4o"}
+	if !reflect.DeepEqual(row[1:7], expected) || row[8] != "1" {
+		t.Errorf("Unexpected move row %q", row)
+	}
+}
+
+func TestBot_PersonalityPrompt(t *testing.T) {
+	tests := []struct {
+		name      string
+		botRating int
+		expected  string
+	}{
+		{"configured style", 1550, PersonalityAggressive},
+		{"much weaker opponent", 1900, PersonalityRomantic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			systemPrompts := make(chan string, 1)
+			withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+				var req openRouterRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				if req.Messages[0].Role == "system" {
+					systemPrompts <- req.Messages[0].Content
+				}
+				w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e7e5"}}]}`))
+			})
+			bot, mock := newTestBot(t)
+			bot.cfg.DisableLLM = false
+			bot.cfg.Personality = PersonalityAggressive
+
+			event := testGameFull("style", "black", "e2e4")
+			event["black"] = map[string]interface{}{"id": "mockbot", "name": "MockBot", "rating": tt.botRating}
+			mock.InjectGameEvent("style", event)
+			bot.StartGame(context.Background(), "style")
+			waitUntil(t, "the bot's move", func() bool { return len(mock.Moves("style")) == 1 })
+			mock.InjectGameEvent("style", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "aborted"})
+			bot.Wait()
+
+			select {
+			case prompt := <-systemPrompts:
+				if prompt != personalityPrompts[tt.expected] {
+					t.Errorf("Expected the %s system prompt, got %q", tt.expected, prompt)
+				}
+			default:
+				t.Error("Expected a system prompt with the move request")
+			}
+		})
+	}
+}
+
+func TestBot_ChatCommandsFromAdminsOnly(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.ChatAdmins = []string{"BotOwner"}
+	bot.commands = bot.newChatCommands()
+
+	mock.InjectGameEvent("cmd", testGameFull("cmd", "black", ""))
+	bot.StartGame(context.Background(), "cmd")
+	mock.InjectGameEvent("cmd", map[string]interface{}{"type": "chatLine", "room": "player", "username": "Opponent", "text": "!status"})
+	mock.InjectGameEvent("cmd", map[string]interface{}{"type": "chatLine", "room": "spectator", "username": "botowner", "text": "!status"})
+	waitUntil(t, "the command reply", func() bool { return len(mock.ChatMessages("cmd")) > 0 })
+	mock.InjectGameEvent("cmd", map[string]interface{}{"type": "gameState", "moves": "", "status": "aborted"})
+	bot.Wait()
+
+	expected := []string{"spectator: Playing 1 game(s)"}
+	if got := mock.ChatMessages("cmd"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected only the admin's command to be answered with %q, got %q", expected, got)
+	}
+}
+
+func TestBot_OffersDrawOnce(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.StockfishPath, _ = writeStubStockfish(t, "bestmove a7a6")
+	bot.cfg.StockfishDepth = 1
+	bot.cfg.OfferDrawAfterNMoves = 3
+	bot.cfg.TestMoveSequence = []string{"e7e5", "g8f6", "b8c6"}
+	bot.cfg.ChatMessages = defaultChatMessages()
+
+	mock.InjectGameEvent("draw", testGameFull("draw", "black", "e2e4"))
+	bot.StartGame(context.Background(), "draw")
+	waitUntil(t, "the first move", func() bool { return len(mock.Moves("draw")) == 1 })
+	mock.InjectGameEvent("draw", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5 d2d3", "status": "started"})
+	waitUntil(t, "the second move", func() bool { return len(mock.Moves("draw")) == 2 })
+	mock.InjectGameEvent("draw", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5 d2d3 g8f6 c2c3", "status": "started"})
+	waitUntil(t, "the third move", func() bool { return len(mock.Moves("draw")) == 3 })
+	mock.InjectGameEvent("draw", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5 d2d3 g8f6 c2c3 b8c6", "status": "draw"})
+	bot.Wait()
+
+	if offers := mock.DrawOffers("draw"); !reflect.DeepEqual(offers, []string{"g8f6"}) {
+		t.Errorf("Expected a single draw offer with g8f6, got %v", offers)
+	}
+	messages := defaultChatMessages()[defaultChatLanguage]
+	expected := []string{"player: " + messages[ChatMsgGreeting], "player: " + messages[ChatMsgDrawOffer], "player: " + messages[ChatMsgGameEnd]}
+	if chat := mock.ChatMessages("draw"); !reflect.DeepEqual(chat, expected) {
+		t.Errorf("Expected the draw offer message between greeting and goodbye, got %q", chat)
+	}
+}
+
+func TestBot_BatchesMoveRequests(t *testing.T) {
+	var inFlight, peak int32
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e2e4"}}]}`))
+	})
+	bot, mock := newTestBot(t)
+	bot.cfg.DisableLLM = false
+	bot.cfg.BatchWindowMS = 50
+	bot.cfg.BatchMaxParallel = 1
+	bot = NewBot(bot.cfg, &BotAccount{ID: "mockbot", Username: "MockBot"})
+
+	for _, id := range []string{"batch1", "batch2"} {
+		mock.InjectGameEvent(id, testGameFull(id, "white", ""))
+		bot.StartGame(context.Background(), id)
+	}
+	waitUntil(t, "both moves", func() bool { return len(mock.Moves("batch1")) == 1 && len(mock.Moves("batch2")) == 1 })
+	for _, id := range []string{"batch1", "batch2"} {
+		mock.InjectGameEvent(id, map[string]interface{}{"type": "gameState", "moves": "e2e4", "status": "aborted"})
+	}
+	bot.Wait()
+
+	if peak != 1 {
+		t.Errorf("Expected the batch to respect LLM_BATCH_MAX_PARALLEL=1 across games, got %d parallel requests", peak)
+	}
+}
+
+func TestBot_PostsDiscordSummary(t *testing.T) {
+	messages := make(chan discordMessage, 1)
+	discord := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg discordMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("Failed to decode Discord message: %v", err)
+		}
+		messages <- msg
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer discord.Close()
+
+	bot, mock := newTestBot(t)
+	bot.cfg.DiscordWebhookURL = discord.URL
+	bot = NewBot(bot.cfg, &BotAccount{ID: "mockbot", Username: "MockBot"})
+
+	event := testGameFull("discord", "black", "")
+	event["clock"] = map[string]interface{}{"initial": 180000, "increment": 2000}
+	mock.InjectGameEvent("discord", event)
+	bot.StartGame(context.Background(), "discord")
+	mock.InjectGameEvent("discord", map[string]interface{}{"type": "gameState", "moves": "f2f3 e7e5 g2g4 d8h4", "status": "mate", "winner": "black"})
+	bot.Wait()
+
+	if len(messages) != 1 {
+		t.Fatalf("Expected one Discord message, got %d", len(messages))
+	}
+	fields := map[string]string{}
+	for _, field := range (<-messages).Embeds[0].Fields {
+		fields[field.Name] = field.Value
+	}
+	expected := map[string]string{"Opponent": "Opponent", "Outcome": "Win", "Moves": "4", "Time control": "3+2", "Bot color": "black"}
+	for name, value := range expected {
+		if fields[name] != value {
+			t.Errorf("Expected %s '%s', got '%s'", name, value, fields[name])
+		}
+	}
+}
+
+func TestBot_AutoplayDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		botClock int
+		delayed  bool
+	}{
+		{"normal clock", 180000, true},
+		{"time scramble", 5000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, mock := newTestBot(t)
+			bot.cfg.MinAutoplayDelayMS = 300
+			bot.cfg.MaxAutoplayDelayMS = 300
+			bot.cfg.TimeScrambleThresholdMS = 10000
+			bot = NewBot(bot.cfg, &BotAccount{ID: "mockbot", Username: "MockBot"})
+
+			event := testGameFull("delay", "black", "e2e4")
+			event["state"].(map[string]interface{})["btime"] = tt.botClock
+			start := time.Now()
+			mock.InjectGameEvent("delay", event)
+			bot.StartGame(context.Background(), "delay")
+			waitUntil(t, "the bot's move", func() bool { return len(mock.Moves("delay")) == 1 })
+			elapsed := time.Since(start)
+			mock.InjectGameEvent("delay", map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "aborted"})
+			bot.Wait()
+
+			if delayed := elapsed >= 300*time.Millisecond; delayed != tt.delayed {
+				t.Errorf("Expected delayed=%v, the move took %v", tt.delayed, elapsed)
+			}
+		})
+	}
+}
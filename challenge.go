@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Decline reasons understood by the Lichess challenge decline API
+const (
+	DeclineGeneric = "generic"
+	DeclineTooFast = "tooFast"
+	DeclineLater   = "later"
+)
+
+// lichessTitles are the titles Lichess can show next to a username
+var lichessTitles = map[string]bool{
+	"GM": true, "IM": true, "FM": true, "CM": true, "NM": true,
+	"WGM": true, "WIM": true, "WFM": true, "WCM": true,
+	"LM": true, "BOT": true,
+}
+
+// isLichessTitle reports whether title is a known Lichess title
+func isLichessTitle(title string) bool {
+	return lichessTitles[title]
+}
+
+// ChallengeMiddleware checks one condition of an incoming challenge. It returns false
+// and one of the Decline* reasons when the challenge should be declined.
+type ChallengeMiddleware func(challenge ChallengeData) (accept bool, reason string)
+
+// newChallengePipeline returns the challenge checks enabled in cfg, in the order they
+// run. random returns a value in [0, 1), normally rand.Float64.
+func newChallengePipeline(cfg *BotConfig, random func() float64) []ChallengeMiddleware {
+	return []ChallengeMiddleware{
+		func(c ChallengeData) (bool, string) { return checkTitledChallenger(c, cfg.AcceptOnlyTitled) },
+		func(c ChallengeData) (bool, string) { return checkMinClock(c, cfg.GameMinClockSeconds) },
+		func(c ChallengeData) (bool, string) {
+			return checkAcceptProbability(c, cfg.ChallengeAcceptProbability, random)
+		},
+	}
+}
+
+// runChallengePipeline runs the checks of pipeline in order and stops at the first
+// one that declines the challenge, returning its reason
+func runChallengePipeline(pipeline []ChallengeMiddleware, challenge ChallengeData) (bool, string) {
+	for _, check := range pipeline {
+		if accept, reason := check(challenge); !accept {
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+// handleChallenge accepts an incoming challenge or declines it with the reason given
+// by the first check of pipeline that rejects it
+func handleChallenge(cfg *BotConfig, pipeline []ChallengeMiddleware, challenge ChallengeData) error {
+	if accept, reason := runChallengePipeline(pipeline, challenge); !accept {
+		return declineChallenge(cfg, challenge.ID, reason)
+	}
+	return acceptChallenge(cfg, challenge.ID)
+}
+
+// checkTitledChallenger decides whether a challenge passes the ACCEPT_ONLY_TITLED rule.
+// It returns the decline reason when the challenge should be declined.
+func checkTitledChallenger(challenge ChallengeData, acceptOnlyTitled bool) (bool, string) {
+	if !acceptOnlyTitled {
+		return true, ""
+	}
+	if !isLichessTitle(challenge.Challenger.Title) {
+		return false, DeclineGeneric
+	}
+	return true, ""
+}
+
+// checkMinClock declines clock challenges whose initial time is below minSeconds
+// (0 disables the check). Untimed and correspondence challenges always pass.
+func checkMinClock(challenge ChallengeData, minSeconds int) (bool, string) {
+	if minSeconds <= 0 || challenge.TimeControl.Type != "clock" {
+		return true, ""
+	}
+	if challenge.TimeControl.Limit < minSeconds {
+		return false, DeclineTooFast
+	}
+	return true, ""
+}
+
+// checkAcceptProbability accepts a challenge with the given probability (0.0-1.0),
+// declining the rest as "later" so the bot appears busy from time to time.
+// random returns a value in [0, 1), normally rand.Float64.
+func checkAcceptProbability(challenge ChallengeData, probability float64, random func() float64) (bool, string) {
+	if probability >= 1 {
+		return true, ""
+	}
+	if random() >= probability {
+		return false, DeclineLater
+	}
+	return true, ""
+}
+
+// ChallengeUser is the challenger or destination user of a challenge
+type ChallengeUser struct {
+	ID          string
+	Name        string
+	Title       string
+	Rating      int
+	Provisional bool
+}
+
+// ChallengeTimeControl describes the clock of a challenge. Limit and Increment
+// are in seconds and only set when Type is "clock".
+type ChallengeTimeControl struct {
+	Type      string
+	Limit     int
+	Increment int
+}
+
+// ChallengeData is the typed form of a challenge object from the Lichess event stream
+type ChallengeData struct {
+	ID          string
+	Status      string
+	Variant     string
+	Speed       string
+	Rated       bool
+	Color       string
+	Challenger  ChallengeUser
+	DestUser    ChallengeUser
+	TimeControl ChallengeTimeControl
+}
+
+// TimeControlString formats the clock as "minutes+increment", e.g. "3+2" or "0.5+0".
+// Challenges without a clock return their time control type, such as "correspondence".
+func (c ChallengeData) TimeControlString() string {
+	if c.TimeControl.Type != "" && c.TimeControl.Type != "clock" {
+		return c.TimeControl.Type
+	}
+	return formatTimeControl(c.TimeControl.Limit, c.TimeControl.Increment)
+}
+
+// formatTimeControl formats a clock limit and increment in seconds the way Lichess
+// shows them, with the limit in minutes
+func formatTimeControl(limitSeconds, incrementSeconds int) string {
+	minutes := strconv.FormatFloat(float64(limitSeconds)/60, 'f', -1, 64)
+	return fmt.Sprintf("%s+%d", minutes, incrementSeconds)
+}
+
+// parseChallengeData converts a raw challenge object into ChallengeData.
+// Only the ID is required; any other field that is present must have the expected type.
+func parseChallengeData(raw map[string]interface{}) (ChallengeData, error) {
+	var c ChallengeData
+	var err error
+
+	if c.ID, err = stringField(raw, "id"); err != nil {
+		return c, err
+	}
+	if c.ID == "" {
+		return c, fmt.Errorf("challenge is missing id")
+	}
+	if c.Status, err = stringField(raw, "status"); err != nil {
+		return c, err
+	}
+	if c.Speed, err = stringField(raw, "speed"); err != nil {
+		return c, err
+	}
+	if c.Color, err = stringField(raw, "color"); err != nil {
+		return c, err
+	}
+	if c.Rated, err = boolField(raw, "rated"); err != nil {
+		return c, err
+	}
+
+	variant, err := objectField(raw, "variant")
+	if err != nil {
+		return c, err
+	}
+	if c.Variant, err = stringField(variant, "key"); err != nil {
+		return c, fmt.Errorf("variant: %v", err)
+	}
+
+	if c.Challenger, err = parseChallengeUser(raw, "challenger"); err != nil {
+		return c, err
+	}
+	if c.DestUser, err = parseChallengeUser(raw, "destUser"); err != nil {
+		return c, err
+	}
+
+	tc, err := objectField(raw, "timeControl")
+	if err != nil {
+		return c, err
+	}
+	if c.TimeControl.Type, err = stringField(tc, "type"); err != nil {
+		return c, fmt.Errorf("timeControl: %v", err)
+	}
+	if c.TimeControl.Limit, err = intField(tc, "limit"); err != nil {
+		return c, fmt.Errorf("timeControl: %v", err)
+	}
+	if c.TimeControl.Increment, err = intField(tc, "increment"); err != nil {
+		return c, fmt.Errorf("timeControl: %v", err)
+	}
+
+	return c, nil
+}
+
+func parseChallengeUser(raw map[string]interface{}, key string) (ChallengeUser, error) {
+	var u ChallengeUser
+	obj, err := objectField(raw, key)
+	if err != nil || obj == nil {
+		return u, err
+	}
+	if u.ID, err = stringField(obj, "id"); err != nil {
+		return u, fmt.Errorf("%s: %v", key, err)
+	}
+	if u.Name, err = stringField(obj, "name"); err != nil {
+		return u, fmt.Errorf("%s: %v", key, err)
+	}
+	if u.Title, err = stringField(obj, "title"); err != nil {
+		return u, fmt.Errorf("%s: %v", key, err)
+	}
+	if u.Rating, err = intField(obj, "rating"); err != nil {
+		return u, fmt.Errorf("%s: %v", key, err)
+	}
+	if u.Provisional, err = boolField(obj, "provisional"); err != nil {
+		return u, fmt.Errorf("%s: %v", key, err)
+	}
+	return u, nil
+}
+
+// The field helpers return the zero value for missing (or null) keys
+// and an error when the key holds a value of the wrong type.
+
+func stringField(m map[string]interface{}, key string) (string, error) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("field '%s' must be a string, got %T", key, v)
+	}
+	return s, nil
+}
+
+func boolField(m map[string]interface{}, key string) (bool, error) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("field '%s' must be a boolean, got %T", key, v)
+	}
+	return b, nil
+}
+
+// intField accepts JSON numbers, which encoding/json decodes as float64
+func intField(m map[string]interface{}, key string) (int, error) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("field '%s' must be a number, got %T", key, v)
+	}
+	return int(f), nil
+}
+
+func objectField(m map[string]interface{}, key string) (map[string]interface{}, error) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("field '%s' must be an object, got %T", key, v)
+	}
+	return obj, nil
+}
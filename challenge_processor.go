@@ -0,0 +1,37 @@
+package main
+
+import "sync"
+
+// ChallengeProcessor hands incoming challenges to a pool of workers so that
+// a burst of challenges is validated and answered in parallel rather than
+// one after another on the event stream goroutine.
+type ChallengeProcessor struct {
+	challenges chan ChallengeData
+	wg         sync.WaitGroup
+}
+
+// NewChallengeProcessor starts poolSize workers, each calling handle for the challenges it receives
+func NewChallengeProcessor(poolSize int, handle func(ChallengeData)) *ChallengeProcessor {
+	p := &ChallengeProcessor{challenges: make(chan ChallengeData, poolSize)}
+	for i := 0; i < poolSize; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for challenge := range p.challenges {
+				handle(challenge)
+			}
+		}()
+	}
+	return p
+}
+
+// Submit queues a challenge, blocking only when every worker is busy and the buffer is full
+func (p *ChallengeProcessor) Submit(challenge ChallengeData) {
+	p.challenges <- challenge
+}
+
+// Close stops accepting challenges and waits for the workers to finish the queued ones
+func (p *ChallengeProcessor) Close() {
+	close(p.challenges)
+	p.wg.Wait()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChallengeProcessor_Parallel(t *testing.T) {
+	const n = 3
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	handled := map[string]bool{}
+	release := make(chan struct{})
+
+	p := NewChallengeProcessor(n, func(c ChallengeData) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		handled[c.ID] = true
+		mu.Unlock()
+	})
+
+	for _, id := range []string{"c1", "c2", "c3"} {
+		p.Submit(ChallengeData{ID: id})
+	}
+
+	// All three workers should pick up a challenge before any of them finishes
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		r := running
+		mu.Unlock()
+		if r == n || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	p.Close()
+
+	if maxRunning != n {
+		t.Errorf("Expected %d challenges processed concurrently, got %d", n, maxRunning)
+	}
+	if len(handled) != n {
+		t.Errorf("Expected %d challenges handled, got %v", n, handled)
+	}
+}
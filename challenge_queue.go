@@ -0,0 +1,75 @@
+package main
+
+import "time"
+
+// challengeQueueTTL is how long a queued challenge is considered worth accepting.
+// Lichess drops challenges whose challenger has gone away, so stale entries are
+// skipped without asking and fresh ones are still re-checked before accepting.
+const challengeQueueTTL = 60 * time.Second
+
+type queuedChallenge struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+// ChallengeQueue buffers challenges that arrive while the bot is at MAX_CONCURRENT_GAMES
+type ChallengeQueue struct {
+	challenges chan queuedChallenge
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewChallengeQueue creates a queue holding at most size challenges
+func NewChallengeQueue(size int) *ChallengeQueue {
+	return &ChallengeQueue{
+		challenges: make(chan queuedChallenge, size),
+		ttl:        challengeQueueTTL,
+		now:        time.Now,
+	}
+}
+
+// Enqueue adds a challenge without blocking. It returns false when the queue is
+// full, in which case the challenge should be declined as before.
+func (q *ChallengeQueue) Enqueue(challengeID string) bool {
+	select {
+	case q.challenges <- queuedChallenge{ID: challengeID, ExpiresAt: q.now().Add(q.ttl)}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Next returns the oldest queued challenge that has not expired and that stillPending
+// confirms is still open on Lichess. Stale challenges are dropped along the way.
+func (q *ChallengeQueue) Next(stillPending func(challengeID string) bool) (string, bool) {
+	for {
+		select {
+		case c := <-q.challenges:
+			if q.now().After(c.ExpiresAt) || !stillPending(c.ID) {
+				continue
+			}
+			return c.ID, true
+		default:
+			return "", false
+		}
+	}
+}
+
+// Len returns the number of challenges waiting in the queue
+func (q *ChallengeQueue) Len() int {
+	return len(q.challenges)
+}
+
+// isChallengePending reports whether challengeID is still among the bot's incoming challenges
+func isChallengePending(cfg *BotConfig, challengeID string) (bool, error) {
+	challenges, err := getPendingChallenges(cfg, 0)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range challenges {
+		if id, _ := c["id"].(string); id == challengeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
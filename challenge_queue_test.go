@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChallengeQueue_UnderLoad(t *testing.T) {
+	q := NewChallengeQueue(5)
+
+	var accepted atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if q.Enqueue(fmt.Sprintf("c%d", i)) {
+				accepted.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if accepted.Load() != 5 || q.Len() != 5 {
+		t.Fatalf("Expected 5 queued challenges, got accepted=%d len=%d", accepted.Load(), q.Len())
+	}
+
+	seen := map[string]bool{}
+	for {
+		id, ok := q.Next(func(string) bool { return true })
+		if !ok {
+			break
+		}
+		seen[id] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("Expected to dequeue 5 distinct challenges, got %v", seen)
+	}
+}
+
+func TestChallengeQueue_SkipsStaleChallenges(t *testing.T) {
+	now := time.Now()
+	q := NewChallengeQueue(3)
+	q.now = func() time.Time { return now }
+
+	q.Enqueue("expired")
+	now = now.Add(challengeQueueTTL / 2)
+	q.Enqueue("withdrawn")
+	q.Enqueue("valid")
+	now = now.Add(challengeQueueTTL/2 + time.Second)
+
+	id, ok := q.Next(func(id string) bool { return id != "withdrawn" })
+	if !ok || id != "valid" {
+		t.Errorf("Expected 'valid', got '%s' (ok=%v)", id, ok)
+	}
+	if _, ok := q.Next(func(string) bool { return true }); ok {
+		t.Error("Expected queue to be empty")
+	}
+}
+
+func TestIsChallengePending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"in":[{"id":"abc"},{"id":"def"}],"out":[]}`))
+	}))
+	defer server.Close()
+	cfg := newTestLichessConfig(server.URL)
+
+	if pending, err := isChallengePending(cfg, "def"); err != nil || !pending {
+		t.Errorf("Expected 'def' to be pending, got %v (err=%v)", pending, err)
+	}
+	if pending, _ := isChallengePending(cfg, "xyz"); pending {
+		t.Error("Expected 'xyz' not to be pending")
+	}
+}
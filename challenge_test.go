@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"lichess-bot-agent/lichessmock"
+)
+
+func challengeFrom(title string) ChallengeData {
+	return ChallengeData{
+		ID:         "chal1",
+		Challenger: ChallengeUser{ID: "player", Name: "Player", Rating: 2000, Title: title},
+	}
+}
+
+func TestCheckTitledChallenger_AcceptsEveryTitle(t *testing.T) {
+	titles := []string{"GM", "IM", "FM", "CM", "NM", "WGM", "WIM", "WFM", "WCM", "LM", "BOT"}
+	for _, title := range titles {
+		t.Run(title, func(t *testing.T) {
+			accept, reason := checkTitledChallenger(challengeFrom(title), true)
+			if !accept || reason != "" {
+				t.Errorf("Expected challenge from %s to be accepted, got accept=%v reason='%s'", title, accept, reason)
+			}
+		})
+	}
+}
+
+func TestCheckTitledChallenger_DeclinesUntitled(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge ChallengeData
+	}{
+		{"empty title", challengeFrom("")},
+		{"unknown title", challengeFrom("XYZ")},
+		{"lowercase title", challengeFrom("gm")},
+		{"no challenger", ChallengeData{ID: "chal1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accept, reason := checkTitledChallenger(tt.challenge, true)
+			if accept || reason != DeclineGeneric {
+				t.Errorf("Expected decline with '%s', got accept=%v reason='%s'", DeclineGeneric, accept, reason)
+			}
+		})
+	}
+}
+
+func TestCheckTitledChallenger_Disabled(t *testing.T) {
+	if accept, _ := checkTitledChallenger(challengeFrom(""), false); !accept {
+		t.Error("Expected untitled challenger to be accepted when ACCEPT_ONLY_TITLED is off")
+	}
+}
+
+func TestCheckMinClock(t *testing.T) {
+	clock := func(limit, increment int) ChallengeData {
+		return ChallengeData{ID: "c", TimeControl: ChallengeTimeControl{Type: "clock", Limit: limit, Increment: increment}}
+	}
+
+	tests := []struct {
+		name      string
+		challenge ChallengeData
+		minimum   int
+		accept    bool
+	}{
+		{"15+0 below threshold", clock(15, 0), 30, false},
+		{"60+0 above threshold", clock(60, 0), 30, true},
+		{"exactly at threshold", clock(30, 0), 30, true},
+		{"check disabled", clock(15, 0), 0, true},
+		{"correspondence", ChallengeData{ID: "c", TimeControl: ChallengeTimeControl{Type: "correspondence"}}, 30, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accept, reason := checkMinClock(tt.challenge, tt.minimum)
+			if accept != tt.accept {
+				t.Errorf("Expected accept=%v, got %v", tt.accept, accept)
+			}
+			if !accept && reason != DeclineTooFast {
+				t.Errorf("Expected decline reason '%s', got '%s'", DeclineTooFast, reason)
+			}
+		})
+	}
+}
+
+func TestCheckAcceptProbability(t *testing.T) {
+	tests := []struct {
+		name        string
+		probability float64
+		random      float64
+		accept      bool
+	}{
+		{"always accept", 1.0, 0.99, true},
+		{"never accept", 0.0, 0.0, false},
+		{"roll below probability", 0.7, 0.69, true},
+		{"roll at probability", 0.7, 0.7, false},
+		{"roll above probability", 0.7, 0.9, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accept, reason := checkAcceptProbability(ChallengeData{ID: "c"}, tt.probability, func() float64 { return tt.random })
+			if accept != tt.accept {
+				t.Errorf("Expected accept=%v, got %v", tt.accept, accept)
+			}
+			if !accept && reason != DeclineLater {
+				t.Errorf("Expected decline reason '%s', got '%s'", DeclineLater, reason)
+			}
+		})
+	}
+}
+
+func TestCheckAcceptProbability_Distribution(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	accepted := 0
+	const trials = 10000
+	for i := 0; i < trials; i++ {
+		if ok, _ := checkAcceptProbability(ChallengeData{ID: "c"}, 0.3, rng.Float64); ok {
+			accepted++
+		}
+	}
+	if rate := float64(accepted) / trials; rate < 0.27 || rate > 0.33 {
+		t.Errorf("Expected about 30%% of challenges accepted, got %.1f%%", rate*100)
+	}
+}
+
+func TestRunChallengePipeline_StopsAtFirstDecline(t *testing.T) {
+	var ran []string
+	check := func(name string, accept bool, reason string) ChallengeMiddleware {
+		return func(ChallengeData) (bool, string) {
+			ran = append(ran, name)
+			return accept, reason
+		}
+	}
+
+	pipeline := []ChallengeMiddleware{
+		check("titled", true, ""),
+		check("clock", false, DeclineTooFast),
+		check("probability", false, DeclineLater),
+	}
+	accept, reason := runChallengePipeline(pipeline, ChallengeData{ID: "c"})
+	if accept || reason != DeclineTooFast {
+		t.Errorf("Expected decline '%s', got accept=%v reason '%s'", DeclineTooFast, accept, reason)
+	}
+	if len(ran) != 2 || ran[1] != "clock" {
+		t.Errorf("Expected the pipeline to stop after the clock check, ran %v", ran)
+	}
+
+	if accept, _ := runChallengePipeline(pipeline[:1], ChallengeData{ID: "c"}); !accept {
+		t.Error("Expected a challenge passing every check to be accepted")
+	}
+}
+
+func TestNewChallengePipeline(t *testing.T) {
+	cfg := &BotConfig{AcceptOnlyTitled: true, GameMinClockSeconds: 60, ChallengeAcceptProbability: 0.5}
+	pipeline := newChallengePipeline(cfg, func() float64 { return 0.9 })
+
+	blitz := ChallengeTimeControl{Type: "clock", Limit: 180}
+	bullet := ChallengeTimeControl{Type: "clock", Limit: 30}
+	tests := []struct {
+		name      string
+		challenge ChallengeData
+		reason    string
+	}{
+		{"untitled", ChallengeData{ID: "c", TimeControl: bullet}, DeclineGeneric},
+		{"too fast", ChallengeData{ID: "c", Challenger: ChallengeUser{Title: "GM"}, TimeControl: bullet}, DeclineTooFast},
+		{"unlucky roll", ChallengeData{ID: "c", Challenger: ChallengeUser{Title: "GM"}, TimeControl: blitz}, DeclineLater},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accept, reason := runChallengePipeline(pipeline, tt.challenge)
+			if accept || reason != tt.reason {
+				t.Errorf("Expected decline '%s', got accept=%v reason '%s'", tt.reason, accept, reason)
+			}
+		})
+	}
+
+	cfg.ChallengeAcceptProbability = 1
+	titled := ChallengeData{ID: "c", Challenger: ChallengeUser{Title: "GM"}, TimeControl: blitz}
+	if accept, reason := runChallengePipeline(pipeline, titled); !accept {
+		t.Errorf("Expected challenge accepted, got decline '%s'", reason)
+	}
+}
+
+func TestHandleChallenge(t *testing.T) {
+	mock := lichessmock.NewServer()
+	defer mock.Close()
+	cfg := newTestLichessConfig(mock.URL())
+	cfg.GameMinClockSeconds = 60
+	cfg.ChallengeAcceptProbability = 1
+	pipeline := newChallengePipeline(cfg, func() float64 { return 0 })
+
+	mock.AddChallenge(map[string]interface{}{"id": "slow"})
+	mock.AddChallenge(map[string]interface{}{"id": "fast"})
+	slow := ChallengeData{ID: "slow", TimeControl: ChallengeTimeControl{Type: "clock", Limit: 300}}
+	fast := ChallengeData{ID: "fast", TimeControl: ChallengeTimeControl{Type: "clock", Limit: 15}}
+	for _, challenge := range []ChallengeData{slow, fast} {
+		if err := handleChallenge(cfg, pipeline, challenge); err != nil {
+			t.Fatalf("handleChallenge(%s) failed: %v", challenge.ID, err)
+		}
+	}
+
+	if accepted := mock.AcceptedChallenges(); len(accepted) != 1 || accepted[0] != "slow" {
+		t.Errorf("Expected only 'slow' accepted, got %v", accepted)
+	}
+	if reason := mock.DeclinedChallenges()["fast"]; reason != DeclineTooFast {
+		t.Errorf("Expected 'fast' declined as '%s', got '%s'", DeclineTooFast, reason)
+	}
+}
+
+const sampleChallengeJSON = `{
+	"id": "H9fIRZUk",
+	"url": "https://lichess.org/H9fIRZUk",
+	"status": "created",
+	"challenger": {"id": "bobby", "name": "Bobby", "title": "FM", "rating": 2352, "provisional": false, "online": true},
+	"destUser": {"id": "llmbot", "name": "LLMBot", "title": "BOT", "rating": 1500, "provisional": true},
+	"variant": {"key": "standard", "name": "Standard", "short": "Std"},
+	"rated": true,
+	"speed": "blitz",
+	"timeControl": {"type": "clock", "limit": 180, "increment": 2, "show": "3+2"},
+	"color": "random",
+	"finalColor": "black"
+}`
+
+func TestParseChallengeData(t *testing.T) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(sampleChallengeJSON), &raw); err != nil {
+		t.Fatalf("Failed to decode sample challenge: %v", err)
+	}
+
+	c, err := parseChallengeData(raw)
+	if err != nil {
+		t.Fatalf("parseChallengeData() failed: %v", err)
+	}
+
+	expected := ChallengeData{
+		ID:          "H9fIRZUk",
+		Status:      "created",
+		Variant:     "standard",
+		Speed:       "blitz",
+		Rated:       true,
+		Color:       "random",
+		Challenger:  ChallengeUser{ID: "bobby", Name: "Bobby", Title: "FM", Rating: 2352},
+		DestUser:    ChallengeUser{ID: "llmbot", Name: "LLMBot", Title: "BOT", Rating: 1500, Provisional: true},
+		TimeControl: ChallengeTimeControl{Type: "clock", Limit: 180, Increment: 2},
+	}
+	if c != expected {
+		t.Errorf("Expected %+v, got %+v", expected, c)
+	}
+}
+
+func TestParseChallengeData_OptionalFields(t *testing.T) {
+	// Open challenges have no destUser and correspondence games have no clock
+	raw := map[string]interface{}{
+		"id":          "corr1",
+		"challenger":  map[string]interface{}{"id": "anna", "name": "Anna", "rating": 1800.0},
+		"timeControl": map[string]interface{}{"type": "correspondence", "daysPerTurn": 3.0},
+	}
+
+	c, err := parseChallengeData(raw)
+	if err != nil {
+		t.Fatalf("parseChallengeData() failed: %v", err)
+	}
+	if c.DestUser != (ChallengeUser{}) {
+		t.Errorf("Expected empty DestUser, got %+v", c.DestUser)
+	}
+	if c.TimeControl.Type != "correspondence" || c.TimeControl.Limit != 0 {
+		t.Errorf("Unexpected time control %+v", c.TimeControl)
+	}
+	if c.Challenger.Title != "" || c.Challenger.Rating != 1800 {
+		t.Errorf("Unexpected challenger %+v", c.Challenger)
+	}
+}
+
+func TestParseChallengeData_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+	}{
+		{"missing id", map[string]interface{}{"status": "created"}},
+		{"non-string id", map[string]interface{}{"id": 12.0}},
+		{"non-boolean rated", map[string]interface{}{"id": "c", "rated": "yes"}},
+		{"variant not an object", map[string]interface{}{"id": "c", "variant": "standard"}},
+		{"non-string title", map[string]interface{}{"id": "c", "challenger": map[string]interface{}{"title": 42.0}}},
+		{"string rating", map[string]interface{}{"id": "c", "challenger": map[string]interface{}{"rating": "2000"}}},
+		{"string clock limit", map[string]interface{}{"id": "c", "timeControl": map[string]interface{}{"limit": "180"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseChallengeData(tt.raw); err == nil {
+				t.Error("Expected error, but got nil")
+			}
+		})
+	}
+}
+
+func TestChallengeData_TimeControlString(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeControl ChallengeTimeControl
+		expected    string
+	}{
+		{"blitz with increment", ChallengeTimeControl{Type: "clock", Limit: 180, Increment: 2}, "3+2"},
+		{"no increment", ChallengeTimeControl{Type: "clock", Limit: 600, Increment: 0}, "10+0"},
+		{"half minute", ChallengeTimeControl{Type: "clock", Limit: 30, Increment: 0}, "0.5+0"},
+		{"quarter minute", ChallengeTimeControl{Type: "clock", Limit: 15, Increment: 1}, "0.25+1"},
+		{"ninety seconds", ChallengeTimeControl{Type: "clock", Limit: 90, Increment: 1}, "1.5+1"},
+		{"classical", ChallengeTimeControl{Type: "clock", Limit: 1800, Increment: 20}, "30+20"},
+		{"correspondence", ChallengeTimeControl{Type: "correspondence"}, "correspondence"},
+		{"unlimited", ChallengeTimeControl{Type: "unlimited"}, "unlimited"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ChallengeData{TimeControl: tt.timeControl}
+			if got := c.TimeControlString(); got != tt.expected {
+				t.Errorf("Expected time control '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ChatResponder picks automatic replies to opponent chat messages for a single game.
+// Each trigger phrase fires at most once per game.
+type ChatResponder struct {
+	mu        sync.Mutex
+	triggers  []string // lower-cased, longest first so specific phrases win
+	responses map[string]string
+	used      map[string]bool
+}
+
+// NewChatResponder creates a responder from a trigger phrase -> response template map
+func NewChatResponder(responseMap map[string]string) *ChatResponder {
+	r := &ChatResponder{
+		responses: make(map[string]string, len(responseMap)),
+		used:      make(map[string]bool),
+	}
+	for trigger, response := range responseMap {
+		trigger = strings.ToLower(strings.TrimSpace(trigger))
+		if trigger == "" {
+			continue
+		}
+		r.triggers = append(r.triggers, trigger)
+		r.responses[trigger] = response
+	}
+	sort.Slice(r.triggers, func(i, j int) bool {
+		if len(r.triggers[i]) != len(r.triggers[j]) {
+			return len(r.triggers[i]) > len(r.triggers[j])
+		}
+		return r.triggers[i] < r.triggers[j]
+	})
+	return r
+}
+
+// Respond returns the reply for a chat message from username, if any trigger phrase
+// matches and has not been used yet in this game. The template may use {username}.
+func (r *ChatResponder) Respond(username, text string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lower := strings.ToLower(text)
+	for _, trigger := range r.triggers {
+		if r.used[trigger] || !strings.Contains(lower, trigger) {
+			continue
+		}
+		r.used[trigger] = true
+		return strings.ReplaceAll(r.responses[trigger], "{username}", username), true
+	}
+	return "", false
+}
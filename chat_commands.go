@@ -0,0 +1,56 @@
+package main
+
+import "strings"
+
+// chatCommandPrefix marks a chat message as a command, e.g. "!status"
+const chatCommandPrefix = "!"
+
+// ChatCommandFunc produces the reply to a chat command from its arguments
+type ChatCommandFunc func(args []string) string
+
+// ChatCommands dispatches "!" chat commands, answering only usernames in the admin list
+type ChatCommands struct {
+	admins   map[string]bool
+	commands map[string]ChatCommandFunc
+}
+
+// NewChatCommands creates a dispatcher for the given admins (CHAT_ADMIN_USERNAMES)
+func NewChatCommands(admins []string) *ChatCommands {
+	c := &ChatCommands{
+		admins:   make(map[string]bool, len(admins)),
+		commands: make(map[string]ChatCommandFunc),
+	}
+	for _, admin := range admins {
+		// Lichess usernames are case-insensitive
+		c.admins[strings.ToLower(admin)] = true
+	}
+	return c
+}
+
+// Register adds a command, named without the "!" prefix
+func (c *ChatCommands) Register(name string, fn ChatCommandFunc) {
+	c.commands[strings.ToLower(name)] = fn
+}
+
+// IsAdmin reports whether username may issue chat commands
+func (c *ChatCommands) IsAdmin(username string) bool {
+	return c.admins[strings.ToLower(username)]
+}
+
+// Handle runs the command in text if it is one and username is an admin. Commands
+// from anyone else are ignored without a reply, so players cannot probe for them.
+func (c *ChatCommands) Handle(username, text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], chatCommandPrefix) {
+		return "", false
+	}
+	if !c.IsAdmin(username) {
+		return "", false
+	}
+
+	fn, ok := c.commands[strings.ToLower(strings.TrimPrefix(fields[0], chatCommandPrefix))]
+	if !ok {
+		return "", false
+	}
+	return fn(fields[1:]), true
+}
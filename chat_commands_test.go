@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChatCommands(t *testing.T) {
+	c := NewChatCommands([]string{"BotOwner"})
+	c.Register("status", func(args []string) string { return "playing 2 games" })
+	c.Register("echo", func(args []string) string { return strings.Join(args, " ") })
+
+	tests := []struct {
+		name     string
+		username string
+		text     string
+		reply    string
+		handled  bool
+	}{
+		{"admin command", "BotOwner", "!status", "playing 2 games", true},
+		{"admin username is case-insensitive", "botowner", "!STATUS", "playing 2 games", true},
+		{"command arguments", "BotOwner", "!echo hello there", "hello there", true},
+		{"non-admin command ignored", "randomplayer", "!status", "", false},
+		{"unknown command", "BotOwner", "!resign", "", false},
+		{"ordinary chat", "BotOwner", "good game", "", false},
+		{"empty message", "BotOwner", "   ", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, handled := c.Handle(tt.username, tt.text)
+			if handled != tt.handled || reply != tt.reply {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.reply, tt.handled, reply, handled)
+			}
+		})
+	}
+}
+
+func TestChatCommands_NoAdmins(t *testing.T) {
+	c := NewChatCommands(nil)
+	c.Register("status", func(args []string) string { return "ok" })
+
+	if _, handled := c.Handle("anyone", "!status"); handled {
+		t.Error("Expected commands to be ignored when no admins are configured")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const defaultChatLanguage = "en"
+
+// Chat message keys
+const (
+	ChatMsgGreeting  = "greeting"
+	ChatMsgGameEnd   = "gameEnd"
+	ChatMsgDrawOffer = "drawOffer"
+)
+
+// ChatMessages holds chat message templates by language and message key
+type ChatMessages map[string]map[string]string
+
+// defaultChatMessages are the built-in English messages
+func defaultChatMessages() ChatMessages {
+	return ChatMessages{
+		defaultChatLanguage: {
+			ChatMsgGreeting:  "Hi! I'm an LLM-powered bot. Good luck and have fun!",
+			ChatMsgGameEnd:   "Good game, thanks for playing!",
+			ChatMsgDrawOffer: "I think this position is equal. Would you accept a draw?",
+		},
+	}
+}
+
+// loadChatMessages reads templates from a JSON file ({"de": {"greeting": "..."}})
+// and merges them over the built-in English messages
+func loadChatMessages(path string) (ChatMessages, error) {
+	messages := defaultChatMessages()
+	if path == "" {
+		return messages, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CHAT_MESSAGES_FILE: %v", err)
+	}
+
+	var fromFile ChatMessages
+	if err := json.Unmarshal(data, &fromFile); err != nil {
+		return nil, fmt.Errorf("failed to parse CHAT_MESSAGES_FILE: %v", err)
+	}
+
+	for lang, templates := range fromFile {
+		if messages[lang] == nil {
+			messages[lang] = make(map[string]string)
+		}
+		for key, template := range templates {
+			messages[lang][key] = template
+		}
+	}
+	return messages, nil
+}
+
+// Get returns the message for key in lang, falling back to English
+// when the language or the key is missing
+func (m ChatMessages) Get(lang, key string) string {
+	if msg, ok := m[lang][key]; ok {
+		return msg
+	}
+	return m[defaultChatLanguage][key]
+}
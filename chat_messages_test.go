@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadChatMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.json")
+	content := `{
+		"de": {"greeting": "Hallo! Viel Glück!"},
+		"en": {"gameEnd": "GG!"}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write messages file: %v", err)
+	}
+
+	messages, err := loadChatMessages(path)
+	if err != nil {
+		t.Fatalf("loadChatMessages() failed: %v", err)
+	}
+
+	tests := []struct {
+		lang     string
+		key      string
+		expected string
+	}{
+		{"de", ChatMsgGreeting, "Hallo! Viel Glück!"},
+		{"de", ChatMsgGameEnd, "GG!"},                                           // missing key falls back to English
+		{"fr", ChatMsgDrawOffer, defaultChatMessages()["en"][ChatMsgDrawOffer]}, // missing language falls back to English
+		{"en", ChatMsgGameEnd, "GG!"},                                           // file overrides built-in English
+		{"en", "unknown", ""},
+	}
+
+	for _, tt := range tests {
+		if got := messages.Get(tt.lang, tt.key); got != tt.expected {
+			t.Errorf("Get(%s, %s) = '%s', expected '%s'", tt.lang, tt.key, got, tt.expected)
+		}
+	}
+}
+
+func TestLoadChatMessages_Errors(t *testing.T) {
+	if _, err := loadChatMessages(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file, but got nil")
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write messages file: %v", err)
+	}
+	if _, err := loadChatMessages(path); err == nil {
+		t.Error("Expected error for malformed file, but got nil")
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestChatResponder_Respond(t *testing.T) {
+	responder := NewChatResponder(map[string]string{
+		"good game": "Thanks {username}, good game!",
+		"blunder":   "Everyone blunders sometimes.",
+		"nice move": "Thank you!",
+	})
+
+	reply, ok := responder.Respond("alice", "Good Game, well played")
+	if !ok || reply != "Thanks alice, good game!" {
+		t.Errorf("Expected greeting reply, got '%s' (ok=%v)", reply, ok)
+	}
+
+	// The same phrase only triggers once per game
+	if reply, ok := responder.Respond("alice", "good game again"); ok {
+		t.Errorf("Expected no reply for repeated phrase, got '%s'", reply)
+	}
+
+	if reply, ok := responder.Respond("alice", "what a BLUNDER"); !ok || reply != "Everyone blunders sometimes." {
+		t.Errorf("Expected blunder reply, got '%s' (ok=%v)", reply, ok)
+	}
+
+	if reply, ok := responder.Respond("alice", "hello"); ok {
+		t.Errorf("Expected no reply for unknown phrase, got '%s'", reply)
+	}
+}
+
+func TestChatResponder_EmptyMap(t *testing.T) {
+	responder := NewChatResponder(nil)
+	if reply, ok := responder.Respond("bob", "good game"); ok {
+		t.Errorf("Expected no reply with empty map, got '%s'", reply)
+	}
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Game phases used to tailor LLM prompts and settings
+const (
+	PhaseOpening    = "opening"
+	PhaseMiddlegame = "middlegame"
+	PhaseEndgame    = "endgame"
+)
+
+const (
+	openingMaxFullMoves = 15 // moves 1-15 are treated as the opening
+	endgameMaxPieces    = 12 // total pieces on the board, kings and pawns included
+)
+
+// gamePhase estimates the phase of the game from the number of half-moves
+// played and the number of pieces left on the board.
+func gamePhase(moveCount, pieceCount int) string {
+	// Few pieces means endgame regardless of how early it is
+	if pieceCount <= endgameMaxPieces {
+		return PhaseEndgame
+	}
+	if (moveCount+1)/2 <= openingMaxFullMoves {
+		return PhaseOpening
+	}
+	return PhaseMiddlegame
+}
+
+// phasePromptHint returns a short sentence describing the phase for the LLM prompt
+func phasePromptHint(phase string) string {
+	switch phase {
+	case PhaseOpening:
+		return "This is an opening position."
+	case PhaseMiddlegame:
+		return "This is a middlegame position."
+	case PhaseEndgame:
+		return "This is an endgame position."
+	default:
+		return ""
+	}
+}
+
+const (
+	emptySquare = 0
+	startFEN    = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+	// fiftyMoveHalfMoves is the number of half-moves without a capture or pawn move
+	// after which either side may claim a draw
+	fiftyMoveHalfMoves = 100
+)
+
+// Board is an 8x8 grid indexed [row][file], row 0 being rank 8 as in FEN.
+// White pieces are upper case, black pieces lower case, empty squares are 0.
+type Board [8][8]rune
+
+// Position is a board plus the rest of the game state a FEN string carries
+type Position struct {
+	Board       Board
+	WhiteToMove bool
+	Castling    string // subset of "KQkq", empty when nobody may castle
+	EnPassant   string // target square such as "e3", empty when there is none
+	HalfMoves   int    // half-moves since the last capture or pawn move
+	FullMoves   int
+}
+
+// parseSquare converts algebraic coordinates like "e4" into board indexes
+func parseSquare(sq string) (row, file int, err error) {
+	if len(sq) != 2 || sq[0] < 'a' || sq[0] > 'h' || sq[1] < '1' || sq[1] > '8' {
+		return 0, 0, fmt.Errorf("invalid square '%s'", sq)
+	}
+	return int('8' - sq[1]), int(sq[0] - 'a'), nil
+}
+
+// isUCIMove reports whether move looks like a UCI move such as "e2e4" or "e7e8q"
+func isUCIMove(move string) bool {
+	if len(move) != 4 && len(move) != 5 {
+		return false
+	}
+	if _, _, err := parseSquare(move[0:2]); err != nil {
+		return false
+	}
+	if _, _, err := parseSquare(move[2:4]); err != nil {
+		return false
+	}
+	return len(move) == 4 || strings.ContainsRune("qrbn", rune(move[4]))
+}
+
+// autoAppendPromotion adds a queen promotion to a four-character move that takes a
+// pawn to the last rank, as LLMs often write "e7e8" for "e7e8q". moves are played
+// from initialFEN (the standard starting position when empty); any other move, or a
+// position that cannot be built, returns move unchanged.
+func autoAppendPromotion(moves []string, initialFEN, move string) string {
+	if len(move) != 4 || !isUCIMove(move) {
+		return move
+	}
+	p, err := positionAfter(moves, initialFEN)
+	if err != nil {
+		return move
+	}
+	fromRow, fromFile, _ := parseSquare(move[0:2])
+	toRow, _, _ := parseSquare(move[2:4])
+	if unicode.ToLower(p.Board[fromRow][fromFile]) != 'p' || (toRow != 0 && toRow != 7) {
+		return move
+	}
+	return move + "q"
+}
+
+// squareName is the inverse of parseSquare
+func squareName(row, file int) string {
+	return string([]byte{byte('a' + file), byte('8' - row)})
+}
+
+// boardFromPlacement builds a board from the piece placement field of a FEN string
+func boardFromPlacement(placement string) (Board, error) {
+	var b Board
+	rows := strings.Split(placement, "/")
+	if len(rows) != 8 {
+		return b, fmt.Errorf("invalid piece placement '%s'", placement)
+	}
+	for r, row := range rows {
+		f := 0
+		for _, c := range row {
+			if c >= '1' && c <= '8' {
+				f += int(c - '0')
+				continue
+			}
+			if !strings.ContainsRune("pnbrqkPNBRQK", c) || f >= 8 {
+				return b, fmt.Errorf("invalid piece placement '%s'", placement)
+			}
+			b[r][f] = c
+			f++
+		}
+		if f != 8 {
+			return b, fmt.Errorf("invalid piece placement '%s'", placement)
+		}
+	}
+	return b, nil
+}
+
+// parseFEN reads a FEN string. The move counters may be omitted, as some tools do.
+func parseFEN(fen string) (Position, error) {
+	var p Position
+	fields := strings.Fields(fen)
+	if len(fields) != 4 && len(fields) != 6 {
+		return p, fmt.Errorf("invalid FEN '%s'", fen)
+	}
+
+	var err error
+	if p.Board, err = boardFromPlacement(fields[0]); err != nil {
+		return p, err
+	}
+
+	switch fields[1] {
+	case "w":
+		p.WhiteToMove = true
+	case "b":
+	default:
+		return p, fmt.Errorf("invalid side to move '%s' in FEN", fields[1])
+	}
+
+	if fields[2] != "-" {
+		for _, c := range fields[2] {
+			if !strings.ContainsRune("KQkq", c) {
+				return p, fmt.Errorf("invalid castling rights '%s' in FEN", fields[2])
+			}
+		}
+		p.Castling = fields[2]
+	}
+
+	if fields[3] != "-" {
+		if _, _, err := parseSquare(fields[3]); err != nil {
+			return p, fmt.Errorf("invalid en passant square in FEN: %v", err)
+		}
+		p.EnPassant = fields[3]
+	}
+
+	p.FullMoves = 1
+	if len(fields) == 6 {
+		if p.HalfMoves, err = strconv.Atoi(fields[4]); err != nil || p.HalfMoves < 0 {
+			return p, fmt.Errorf("invalid half-move clock '%s' in FEN", fields[4])
+		}
+		if p.FullMoves, err = strconv.Atoi(fields[5]); err != nil || p.FullMoves < 1 {
+			return p, fmt.Errorf("invalid move number '%s' in FEN", fields[5])
+		}
+	}
+	return p, nil
+}
+
+// FEN renders the position as a FEN string
+func (p *Position) FEN() string {
+	var sb strings.Builder
+	for r := 0; r < 8; r++ {
+		empty := 0
+		for f := 0; f < 8; f++ {
+			if p.Board[r][f] == emptySquare {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			sb.WriteRune(p.Board[r][f])
+		}
+		if empty > 0 {
+			sb.WriteByte(byte('0' + empty))
+		}
+		if r < 7 {
+			sb.WriteByte('/')
+		}
+	}
+
+	side, castling, ep := "b", p.Castling, p.EnPassant
+	if p.WhiteToMove {
+		side = "w"
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	if ep == "" {
+		ep = "-"
+	}
+	return fmt.Sprintf("%s %s %s %s %d %d", sb.String(), side, castling, ep, p.HalfMoves, p.FullMoves)
+}
+
+// Play applies a UCI move, handling castling, en passant, promotion and
+// the castling rights, en passant square and move counters. It does not check legality.
+func (p *Position) Play(move string) error {
+	if len(move) != 4 && len(move) != 5 {
+		return fmt.Errorf("invalid move '%s'", move)
+	}
+	if len(move) == 5 && !strings.ContainsRune("qrbn", rune(move[4])) {
+		return fmt.Errorf("invalid promotion piece in move '%s'", move)
+	}
+	fromRow, fromFile, err := parseSquare(move[0:2])
+	if err != nil {
+		return err
+	}
+	toRow, toFile, err := parseSquare(move[2:4])
+	if err != nil {
+		return err
+	}
+
+	b := &p.Board
+	piece := b[fromRow][fromFile]
+	if piece == emptySquare {
+		return fmt.Errorf("no piece on %s for move '%s'", move[0:2], move)
+	}
+	captured := b[toRow][toFile]
+	kind := unicode.ToLower(piece)
+
+	// En passant: a pawn moving diagonally onto the en passant square takes the pawn beside it
+	if kind == 'p' && fromFile != toFile && captured == emptySquare {
+		captured = b[fromRow][toFile]
+		b[fromRow][toFile] = emptySquare
+	}
+
+	// Castling: the king moves two files, so bring the rook across
+	if kind == 'k' && (toFile-fromFile == 2 || fromFile-toFile == 2) {
+		rookFrom, rookTo := 7, 5
+		if toFile < fromFile {
+			rookFrom, rookTo = 0, 3
+		}
+		b[fromRow][rookTo] = b[fromRow][rookFrom]
+		b[fromRow][rookFrom] = emptySquare
+	}
+
+	b[toRow][toFile] = piece
+	b[fromRow][fromFile] = emptySquare
+	if len(move) == 5 {
+		promoted := rune(move[4])
+		if unicode.IsUpper(piece) {
+			promoted = unicode.ToUpper(promoted)
+		}
+		b[toRow][toFile] = promoted
+	}
+
+	// A king move loses both rights; a rook leaving or being taken on its corner loses one
+	if kind == 'k' {
+		if unicode.IsUpper(piece) {
+			p.removeCastling("KQ")
+		} else {
+			p.removeCastling("kq")
+		}
+	}
+	for _, sq := range []string{move[0:2], move[2:4]} {
+		switch sq {
+		case "h1":
+			p.removeCastling("K")
+		case "a1":
+			p.removeCastling("Q")
+		case "h8":
+			p.removeCastling("k")
+		case "a8":
+			p.removeCastling("q")
+		}
+	}
+
+	p.EnPassant = ""
+	if kind == 'p' && (toRow-fromRow == 2 || fromRow-toRow == 2) {
+		p.EnPassant = squareName((fromRow+toRow)/2, fromFile)
+	}
+
+	if kind == 'p' || captured != emptySquare {
+		p.HalfMoves = 0
+	} else {
+		p.HalfMoves++
+	}
+	if !unicode.IsUpper(piece) {
+		p.FullMoves++
+	}
+	p.WhiteToMove = !unicode.IsUpper(piece)
+	return nil
+}
+
+func (p *Position) removeCastling(rights string) {
+	p.Castling = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(rights, r) {
+			return -1
+		}
+		return r
+	}, p.Castling)
+}
+
+// positionAfter plays moves from initialFEN, or from the standard starting position when it is empty
+func positionAfter(moves []string, initialFEN string) (Position, error) {
+	if initialFEN == "" || initialFEN == "startpos" {
+		initialFEN = startFEN
+	}
+	p, err := parseFEN(initialFEN)
+	if err != nil {
+		return p, err
+	}
+	for _, move := range moves {
+		if err := p.Play(move); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// movesToBoard returns the board after playing moves from initialFEN
+// (the standard starting position when empty)
+func movesToBoard(moves []string, initialFEN string) ([8][8]rune, error) {
+	p, err := positionAfter(moves, initialFEN)
+	return p.Board, err
+}
+
+// movesToFEN returns the FEN of the position after playing moves from initialFEN
+func movesToFEN(moves []string, initialFEN string) (string, error) {
+	p, err := positionAfter(moves, initialFEN)
+	if err != nil {
+		return "", err
+	}
+	return p.FEN(), nil
+}
+
+// renderASCIIBoard draws the board from White's side with rank and file labels
+func renderASCIIBoard(b [8][8]rune) string {
+	var sb strings.Builder
+	for r := 0; r < 8; r++ {
+		sb.WriteByte(byte('8' - r))
+		for f := 0; f < 8; f++ {
+			sb.WriteByte(' ')
+			if b[r][f] == emptySquare {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteRune(b[r][f])
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("  a b c d e f g h\n")
+	return sb.String()
+}
+
+// isDrawByFiftyMoveRule reports whether fifty full moves have passed without a capture or pawn move
+// after playing moves from initialFEN (the standard starting position when empty)
+func isDrawByFiftyMoveRule(moves []string, initialFEN string) bool {
+	p, err := positionAfter(moves, initialFEN)
+	return err == nil && p.HalfMoves >= fiftyMoveHalfMoves
+}
+
+// isInsufficientMaterial reports whether neither side can possibly checkmate:
+// bare kings, a single minor piece, or bishops that all stand on one square colour.
+// moves are played from initialFEN, the standard starting position when empty.
+func isInsufficientMaterial(moves []string, initialFEN string) bool {
+	p, err := positionAfter(moves, initialFEN)
+	return err == nil && hasInsufficientMaterial(p.Board)
+}
+
+func hasInsufficientMaterial(b Board) bool {
+	knights, bishops := 0, 0
+	bishopColours := map[int]bool{}
+	for r := range b {
+		for f, piece := range b[r] {
+			switch unicode.ToLower(piece) {
+			case 'k', emptySquare:
+			case 'n':
+				knights++
+			case 'b':
+				bishops++
+				bishopColours[(r+f)%2] = true
+			default:
+				// Any pawn, rook or queen is enough material to mate
+				return false
+			}
+		}
+	}
+
+	if knights+bishops <= 1 {
+		return true
+	}
+	// Several minors draw only if they are all bishops on the same colour
+	return knights == 0 && len(bishopColours) == 1
+}
@@ -0,0 +1,208 @@
+package main
+
+import "testing"
+
+func TestGamePhase(t *testing.T) {
+	tests := []struct {
+		name       string
+		moveCount  int
+		pieceCount int
+		expected   string
+	}{
+		{"start position", 0, 32, PhaseOpening},
+		{"last opening move", 30, 30, PhaseOpening},
+		{"first middlegame move", 31, 28, PhaseMiddlegame},
+		{"late middlegame", 60, 20, PhaseMiddlegame},
+		{"endgame by piece count", 70, 12, PhaseEndgame},
+		{"early trade-down", 20, 10, PhaseEndgame},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gamePhase(tt.moveCount, tt.pieceCount); got != tt.expected {
+				t.Errorf("gamePhase(%d, %d) = '%s', expected '%s'", tt.moveCount, tt.pieceCount, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPhasePromptHint(t *testing.T) {
+	if hint := phasePromptHint(PhaseEndgame); hint != "This is an endgame position." {
+		t.Errorf("Unexpected endgame hint: '%s'", hint)
+	}
+	if hint := phasePromptHint("unknown"); hint != "" {
+		t.Errorf("Expected empty hint for unknown phase, got '%s'", hint)
+	}
+}
+
+func TestMovesToBoard(t *testing.T) {
+	// Castling kingside, en passant and promotion in one line
+	moves := []string{"e2e4", "d7d5", "e4e5", "f7f5", "e5f6", "g8h6", "f6g7", "e8d7", "g7h8q", "d7e8", "g1f3", "b8c6", "f1e2", "c6b4", "e1g1"}
+	b, err := movesToBoard(moves, "")
+	if err != nil {
+		t.Fatalf("movesToBoard() failed: %v", err)
+	}
+
+	checks := map[string]rune{
+		"f5": emptySquare, // taken en passant
+		"h8": 'Q',         // promoted pawn
+		"g1": 'K',
+		"f1": 'R', // castled rook
+		"h1": emptySquare,
+		"e1": emptySquare,
+		"b4": 'n',
+	}
+	for sq, expected := range checks {
+		row, file, _ := parseSquare(sq)
+		if b[row][file] != expected {
+			t.Errorf("Expected %q on %s, got %q", expected, sq, b[row][file])
+		}
+	}
+
+	if _, err := movesToBoard([]string{"e3e4"}, ""); err == nil {
+		t.Error("Expected error for move from empty square, but got nil")
+	}
+	if _, err := movesToBoard(nil, "not a fen"); err == nil {
+		t.Error("Expected error for invalid FEN, but got nil")
+	}
+}
+
+func TestMovesToFEN(t *testing.T) {
+	tests := []struct {
+		name       string
+		initialFEN string
+		moves      []string
+		expected   string
+	}{
+		{"start position", "", nil, startFEN},
+		{"after 1. e4", "", []string{"e2e4"},
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"},
+		{"sicilian", "", []string{"e2e4", "c7c5", "g1f3"},
+			"rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"},
+		{"castling clears white rights", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []string{"e1g1"},
+			"r3k2r/8/8/8/8/8/8/R4RK1 b kq - 1 1"},
+		{"rook capture clears rights", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []string{"a1a8"},
+			"R3k2r/8/8/8/8/8/8/4K2R b Kk - 0 1"},
+		{"en passant capture", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", []string{"e5d6"},
+			"4k3/8/3P4/8/8/8/8/4K3 b - - 0 1"},
+		{"queenside castling", "r3k3/8/8/8/8/8/8/4K3 b q - 3 40", []string{"e8c8"},
+			"2kr4/8/8/8/8/8/8/4K3 w - - 4 41"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fen, err := movesToFEN(tt.moves, tt.initialFEN)
+			if err != nil {
+				t.Fatalf("movesToFEN() failed: %v", err)
+			}
+			if fen != tt.expected {
+				t.Errorf("Expected FEN\n%s\ngot\n%s", tt.expected, fen)
+			}
+		})
+	}
+
+	if _, err := movesToFEN([]string{"e7e8k"}, "k7/4P3/8/8/8/8/8/4K3 w - - 0 1"); err == nil {
+		t.Error("Expected error for promotion to a king")
+	}
+}
+
+func TestAutoAppendPromotion(t *testing.T) {
+	tests := []struct {
+		name       string
+		initialFEN string
+		moves      []string
+		move       string
+		expected   string
+	}{
+		{"white push", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", nil, "e7e8", "e7e8q"},
+		{"white capture", "3r4/4P3/8/8/8/8/k7/4K3 w - - 0 1", nil, "e7d8", "e7d8q"},
+		{"black push", "4k3/8/8/8/8/8/3p4/K7 b - - 0 1", nil, "d2d1", "d2d1q"},
+		{"after moves", "4k3/8/8/8/8/8/3p4/K7 w - - 0 1", []string{"a1b1"}, "d2d1", "d2d1q"},
+		{"suffix kept", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", nil, "e7e8n", "e7e8n"},
+		{"pawn short of the last rank", "", nil, "e2e4", "e2e4"},
+		{"rook to the last rank", "4k3/R7/8/8/8/8/8/4K3 w - - 0 1", nil, "a7a8", "a7a8"},
+		{"not a move", "", nil, "resign", "resign"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := autoAppendPromotion(tt.moves, tt.initialFEN, tt.move); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderASCIIBoard(t *testing.T) {
+	b, _ := movesToBoard([]string{"e2e4"}, "")
+	expected := `8 r n b q k b n r
+7 p p p p p p p p
+6 . . . . . . . .
+5 . . . . . . . .
+4 . . . . P . . .
+3 . . . . . . . .
+2 P P P P . P P P
+1 R N B Q K B N R
+  a b c d e f g h
+`
+	if got := renderASCIIBoard(b); got != expected {
+		t.Errorf("Unexpected board rendering:\n%s", got)
+	}
+}
+
+func TestIsDrawByFiftyMoveRule(t *testing.T) {
+	shuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	var moves []string
+	for i := 0; i < 25; i++ {
+		moves = append(moves, shuffle...)
+	}
+
+	if !isDrawByFiftyMoveRule(moves, "") {
+		t.Error("Expected draw after 100 half-moves of knight shuffling")
+	}
+	if isDrawByFiftyMoveRule(moves[:99], "") {
+		t.Error("Expected no draw after 99 half-moves")
+	}
+	if isDrawByFiftyMoveRule(append([]string{"e2e4"}, moves[:99]...), "") {
+		t.Error("Expected pawn move to reset the fifty-move count")
+	}
+	if !isDrawByFiftyMoveRule([]string{"g1f3"}, "4k3/8/8/8/8/8/8/4K1N1 w - - 99 80") {
+		t.Error("Expected the half-move clock from the initial FEN to count")
+	}
+}
+
+func TestIsInsufficientMaterial(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement string
+		expected  bool
+	}{
+		{"bare kings", "8/8/4k3/8/8/4K3/8/8", true},
+		{"king and knight", "8/8/4k3/8/8/4K3/8/6N1", true},
+		{"king and bishop", "8/8/4k3/8/8/4K3/8/5B2", true},
+		{"same coloured bishops", "8/8/4k3/8/8/4K3/8/2b3B1", true},
+		{"opposite coloured bishops", "8/8/4k3/8/8/4K3/8/2b2B2", false},
+		{"two knights", "8/8/4k3/8/8/4K3/8/1N4N1", false},
+		{"pawn", "8/8/4k3/8/8/4K3/4P3/8", false},
+		{"rook", "8/8/4k3/8/8/4K3/8/R7", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := boardFromPlacement(tt.placement)
+			if err != nil {
+				t.Fatalf("boardFromPlacement() failed: %v", err)
+			}
+			if got := hasInsufficientMaterial(b); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if isInsufficientMaterial([]string{"e2e4"}, "") {
+		t.Error("Expected sufficient material after 1. e4")
+	}
+	if !isInsufficientMaterial([]string{"e1d1"}, "4k3/8/8/8/8/8/8/4K1N1 w - - 0 1") {
+		t.Error("Expected insufficient material from a king and knight initial FEN")
+	}
+}
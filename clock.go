@@ -0,0 +1,101 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClockMonitor tracks which clock warning thresholds have already been
+// announced for each player of a single game, so every warning is sent once.
+type ClockMonitor struct {
+	mu         sync.Mutex
+	thresholds []int // sorted in descending order
+	announced  map[string]map[int]bool
+}
+
+// NewClockMonitor creates a monitor for the given thresholds in milliseconds
+func NewClockMonitor(thresholds []int) *ClockMonitor {
+	sorted := append([]int(nil), thresholds...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	return &ClockMonitor{
+		thresholds: sorted,
+		announced:  make(map[string]map[int]bool),
+	}
+}
+
+// Check records the remaining time for a player ("white" or "black") and returns
+// the thresholds that were crossed for the first time, largest first.
+func (m *ClockMonitor) Check(player string, remainingMs int) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.announced[player] == nil {
+		m.announced[player] = make(map[int]bool)
+	}
+
+	var crossed []int
+	for _, threshold := range m.thresholds {
+		if remainingMs < threshold && !m.announced[player][threshold] {
+			m.announced[player][threshold] = true
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
+}
+
+// formatClockWarning fills the {player} and {seconds} placeholders of a warning template
+func formatClockWarning(template, player string, thresholdMs int) string {
+	return strings.NewReplacer(
+		"{player}", player,
+		"{seconds}", strconv.Itoa(thresholdMs/1000),
+	).Replace(template)
+}
+
+// GameTimeout calls onExpire once a game has been running for too long
+type GameTimeout struct {
+	duration time.Duration
+	timer    *time.Timer
+}
+
+// NewGameTimeout starts a timeout that calls onExpire after d
+func NewGameTimeout(d time.Duration, onExpire func()) *GameTimeout {
+	return &GameTimeout{
+		duration: d,
+		timer:    time.AfterFunc(d, onExpire),
+	}
+}
+
+// Reset restarts the countdown, e.g. after a move was played.
+// It returns false if the timeout has already fired or was stopped.
+func (t *GameTimeout) Reset() bool {
+	if !t.timer.Stop() {
+		return false
+	}
+	t.timer.Reset(t.duration)
+	return true
+}
+
+// Stop cancels the timeout when the game ends normally
+func (t *GameTimeout) Stop() {
+	t.timer.Stop()
+}
+
+// isTimeScramble reports whether remainingMs on the bot's clock is below the
+// TIME_SCRAMBLE_THRESHOLD_MS (thresholdMs 0 disables the check)
+func isTimeScramble(remainingMs, thresholdMs int) bool {
+	return thresholdMs > 0 && remainingMs < thresholdMs
+}
+
+// timeScrambleConfig returns the settings for a move made in time pressure: a copy
+// of cfg with the slower multi-call and step-by-step prompting modes and the
+// second-opinion validator switched off
+func timeScrambleConfig(cfg *BotConfig) *BotConfig {
+	fast := *cfg
+	fast.LLMChainOfThought = false
+	fast.LLMMultiStep = false
+	fast.ValidatorModel = ""
+	return &fast
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClockMonitor_Check(t *testing.T) {
+	monitor := NewClockMonitor([]int{10000, 60000, 30000})
+
+	if crossed := monitor.Check("white", 90000); len(crossed) != 0 {
+		t.Errorf("Expected no warnings above all thresholds, got %v", crossed)
+	}
+	if crossed := monitor.Check("white", 45000); !reflect.DeepEqual(crossed, []int{60000}) {
+		t.Errorf("Expected [60000], got %v", crossed)
+	}
+	// Same threshold must not be announced twice
+	if crossed := monitor.Check("white", 40000); len(crossed) != 0 {
+		t.Errorf("Expected no repeated warning, got %v", crossed)
+	}
+	// A big drop crosses several thresholds at once
+	if crossed := monitor.Check("white", 5000); !reflect.DeepEqual(crossed, []int{30000, 10000}) {
+		t.Errorf("Expected [30000 10000], got %v", crossed)
+	}
+	// Players are tracked independently
+	if crossed := monitor.Check("black", 50000); !reflect.DeepEqual(crossed, []int{60000}) {
+		t.Errorf("Expected [60000] for black, got %v", crossed)
+	}
+}
+
+func TestFormatClockWarning(t *testing.T) {
+	msg := formatClockWarning(defaultClockWarningMessage, "white", 30000)
+	if msg != "white has less than 30 seconds left" {
+		t.Errorf("Unexpected warning message: '%s'", msg)
+	}
+}
+
+func TestGameTimeout_Fires(t *testing.T) {
+	fired := make(chan struct{})
+	NewGameTimeout(10*time.Millisecond, func() { close(fired) })
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("Expected timeout to fire")
+	}
+}
+
+func TestGameTimeout_ResetAndStop(t *testing.T) {
+	var fired atomic.Bool
+	timeout := NewGameTimeout(50*time.Millisecond, func() { fired.Store(true) })
+
+	time.Sleep(30 * time.Millisecond)
+	if !timeout.Reset() {
+		t.Fatal("Expected Reset to succeed before the timeout fired")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if fired.Load() {
+		t.Fatal("Expected timeout not to fire after Reset")
+	}
+
+	timeout.Stop()
+	time.Sleep(50 * time.Millisecond)
+	if fired.Load() {
+		t.Error("Expected timeout not to fire after Stop")
+	}
+	if timeout.Reset() {
+		t.Error("Expected Reset to fail after Stop")
+	}
+}
+
+func TestIsTimeScramble(t *testing.T) {
+	tests := []struct {
+		remainingMs int
+		thresholdMs int
+		expected    bool
+	}{
+		{60000, 10000, false},
+		{10000, 10000, false},
+		{9999, 10000, true},
+		{0, 10000, true},
+		{500, 0, false},
+		{25000, 30000, true},
+	}
+
+	for _, tt := range tests {
+		if got := isTimeScramble(tt.remainingMs, tt.thresholdMs); got != tt.expected {
+			t.Errorf("isTimeScramble(%d, %d): expected %v, got %v", tt.remainingMs, tt.thresholdMs, tt.expected, got)
+		}
+	}
+}
+
+func TestTimeScrambleConfig(t *testing.T) {
+	cfg := &BotConfig{OpenRouterModel: "openai/gpt-4o", LLMChainOfThought: true, LLMMultiStep: true, ValidatorModel: "openai/gpt-4o-mini"}
+	fast := timeScrambleConfig(cfg)
+
+	if fast.LLMChainOfThought || fast.LLMMultiStep || fast.ValidatorModel != "" {
+		t.Errorf("Expected slow modes to be disabled, got %+v", fast)
+	}
+	if fast.OpenRouterModel != "openai/gpt-4o" {
+		t.Errorf("Expected other settings to be kept, got model '%s'", fast.OpenRouterModel)
+	}
+	if !cfg.LLMChainOfThought || !cfg.LLMMultiStep {
+		t.Error("Expected the original config to be unchanged")
+	}
+}
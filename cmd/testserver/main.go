@@ -0,0 +1,201 @@
+// Command testserver is a fake Lichess server for local end-to-end testing.
+//
+// It serves the lichessmock fake of the bot API on a real address, so the bot can
+// be run with LICHESS_BASE_URL=http://localhost:9999, and feeds its NDJSON event
+// streams from fixture files. Every request is logged.
+//
+// Fixture files in the fixtures directory, read at startup:
+//
+//	events.ndjson          events for /api/stream/event
+//	game_{gameID}.ndjson   events for /api/bot/game/stream/{gameID}
+//
+// Challenge events become pending challenges that the bot can accept or decline.
+// Extra events can be injected with:
+//
+//	POST /inject/events          body: one or more NDJSON lines
+//	POST /inject/game/{gameID}   body: one or more NDJSON lines
+//
+// Injected events are sent after the stream's fixture events.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"lichess-bot-agent/lichessmock"
+)
+
+const keepAliveInterval = 6 * time.Second
+
+// server feeds fixture and injected events into a lichessmock fake
+type server struct {
+	mock *lichessmock.MockLichessServer
+	// fixtures holds, per stream, a channel closed once its fixture events are queued.
+	// It is only written by newServer.
+	fixtures map[string]chan struct{}
+}
+
+// stream identifies an event stream: the bot's event stream, or a game's stream
+type stream struct {
+	gameID string // empty for /api/stream/event
+}
+
+func (st stream) String() string {
+	if st.gameID == "" {
+		return "events"
+	}
+	return "game " + st.gameID
+}
+
+func newServer(fixturesDir string, delay time.Duration) *server {
+	mock := lichessmock.New()
+	mock.SetAccount(map[string]interface{}{"id": "testbot", "username": "TestBot", "title": "BOT"})
+	mock.SetStreamDelay(delay)
+	mock.SetKeepAlive(keepAliveInterval)
+	s := &server{mock: mock, fixtures: make(map[string]chan struct{})}
+
+	files, _ := filepath.Glob(filepath.Join(fixturesDir, "*.ndjson"))
+	for _, path := range files {
+		name := filepath.Base(path)
+		var st stream
+		switch {
+		case name == "events.ndjson":
+		case strings.HasPrefix(name, "game_"):
+			st.gameID = strings.TrimSuffix(strings.TrimPrefix(name, "game_"), ".ndjson")
+		default:
+			continue
+		}
+		lines, err := readFixture(path)
+		if err != nil {
+			log.Printf("No fixture loaded for %s: %v", st, err)
+			continue
+		}
+		// Queue the fixture in the background: a stream holds only a limited number of
+		// events until a client reads them, and the server must keep serving meanwhile
+		done := make(chan struct{})
+		s.fixtures[st.String()] = done
+		go func() {
+			defer close(done)
+			for _, line := range lines {
+				s.push(st, line)
+			}
+		}()
+		log.Printf("Loading %d events for %s from %s", len(lines), st, name)
+	}
+	return s
+}
+
+// push queues one NDJSON event on a stream. Challenge events on the event stream are
+// added as pending challenges, so that accepting or declining them succeeds.
+func (s *server) push(st stream, line string) {
+	if st.gameID != "" {
+		s.mock.InjectGameEvent(st.gameID, json.RawMessage(line))
+		return
+	}
+	var event struct {
+		Type      string                 `json:"type"`
+		Challenge map[string]interface{} `json:"challenge"`
+	}
+	if json.Unmarshal([]byte(line), &event) == nil && event.Type == "challenge" && event.Challenge != nil {
+		s.mock.AddChallenge(event.Challenge)
+		return
+	}
+	s.mock.InjectEvent(json.RawMessage(line))
+}
+
+// readFixture reads non-empty lines of an NDJSON file, checking each is valid JSON
+func readFixture(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readEvents(f)
+}
+
+// readEvents reads NDJSON lines from r, skipping blank lines
+func readEvents(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !json.Valid([]byte(line)) {
+			return nil, fmt.Errorf("invalid JSON event: %s", line)
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// inject adds the NDJSON lines from the request body to a stream once its fixture
+// events are queued. It waits while the stream is full.
+func (s *server) inject(w http.ResponseWriter, r *http.Request, st stream) {
+	lines, err := readEvents(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if done, ok := s.fixtures[st.String()]; ok {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	for _, line := range lines {
+		s.push(st, line)
+	}
+	writeJSON(w, map[string]interface{}{"ok": true, "injected": len(lines)})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+// logRequests logs every request before passing it on
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", s.mock.Handler())
+	mux.HandleFunc("POST /inject/events", func(w http.ResponseWriter, r *http.Request) {
+		s.inject(w, r, stream{})
+	})
+	mux.HandleFunc("POST /inject/game/{gameID}", func(w http.ResponseWriter, r *http.Request) {
+		s.inject(w, r, stream{gameID: r.PathValue("gameID")})
+	})
+	return logRequests(mux)
+}
+
+func main() {
+	addr := flag.String("addr", ":9999", "address to listen on")
+	fixturesDir := flag.String("fixtures", "cmd/testserver/fixtures", "directory with NDJSON fixture files")
+	delay := flag.Duration("delay", 500*time.Millisecond, "delay before sending each event")
+	flag.Parse()
+
+	s := newServer(*fixturesDir, *delay)
+	log.Printf("Fake Lichess server listening on %s (fixtures: %s, delay: %s)", *addr, *fixturesDir, *delay)
+	if err := http.ListenAndServe(*addr, s.routes()); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
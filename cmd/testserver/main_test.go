@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServer_StreamsFixtureAndInjectedEvents(t *testing.T) {
+	dir := t.TempDir()
+	fixture := `{"type":"gameStart","game":{"gameId":"g1"}}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "events.ndjson"), []byte(fixture), 0600); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	ts := httptest.NewServer(newServer(dir, 0).routes())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/inject/events", "application/x-ndjson",
+		strings.NewReader(`{"type":"gameFinish","game":{"gameId":"g1"}}`+"\n"))
+	if err != nil {
+		t.Fatalf("Inject request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected inject status 200, got %d", resp.StatusCode)
+	}
+
+	stream, err := http.Get(ts.URL + "/api/stream/event")
+	if err != nil {
+		t.Fatalf("Stream request failed: %v", err)
+	}
+	defer stream.Body.Close()
+
+	scanner := bufio.NewScanner(stream.Body)
+	var events []string
+	for len(events) < 2 && scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			events = append(events, line)
+		}
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %v", events)
+	}
+	if !strings.Contains(events[0], "gameStart") || !strings.Contains(events[1], "gameFinish") {
+		t.Errorf("Expected fixture event followed by injected event, got %v", events)
+	}
+}
+
+func TestServer_InjectRejectsInvalidJSON(t *testing.T) {
+	ts := httptest.NewServer(newServer(t.TempDir(), 0).routes())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/inject/game/g1", "application/x-ndjson", strings.NewReader("not json\n"))
+	if err != nil {
+		t.Fatalf("Inject request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid JSON, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_LongFixtureDoesNotBlockOtherRequests(t *testing.T) {
+	dir := t.TempDir()
+	const events = 500 // more than a stream holds before it is read
+	var fixture strings.Builder
+	for i := 0; i < events; i++ {
+		fixture.WriteString(`{"type":"gameState","moves":""}` + "\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "game_g1.ndjson"), []byte(fixture.String()), 0600); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	ts := httptest.NewServer(newServer(dir, 0).routes())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/account")
+	if err != nil {
+		t.Fatalf("Account request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected account status 200, got %d", resp.StatusCode)
+	}
+
+	stream, err := http.Get(ts.URL + "/api/bot/game/stream/g1")
+	if err != nil {
+		t.Fatalf("Stream request failed: %v", err)
+	}
+	defer stream.Body.Close()
+	scanner := bufio.NewScanner(stream.Body)
+	received := 0
+	for received < events && scanner.Scan() {
+		if scanner.Text() != "" {
+			received++
+		}
+	}
+	if received != events {
+		t.Errorf("Expected %d fixture events, got %d", events, received)
+	}
+}
+
+func TestServer_FixtureChallengesCanBeAccepted(t *testing.T) {
+	dir := t.TempDir()
+	fixture := `{"type":"challenge","challenge":{"id":"chal1","challenger":{"id":"player"}}}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "events.ndjson"), []byte(fixture), 0600); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	s := newServer(dir, 0)
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	stream, err := http.Get(ts.URL + "/api/stream/event")
+	if err != nil {
+		t.Fatalf("Stream request failed: %v", err)
+	}
+	defer stream.Body.Close()
+	// Wait for the challenge to be announced
+	scanner := bufio.NewScanner(stream.Body)
+	announced := false
+	for !announced && scanner.Scan() {
+		announced = strings.Contains(scanner.Text(), "chal1")
+	}
+	if !announced {
+		t.Fatal("Expected the fixture challenge on the event stream")
+	}
+
+	resp, err := http.Post(ts.URL+"/api/challenge/chal1/accept", "", nil)
+	if err != nil {
+		t.Fatalf("Accept request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected accept status 200, got %d", resp.StatusCode)
+	}
+	if accepted := s.mock.AcceptedChallenges(); len(accepted) != 1 || accepted[0] != "chal1" {
+		t.Errorf("Expected chal1 accepted, got %v", accepted)
+	}
+}
@@ -0,0 +1,119 @@
+// Command upgrade turns a Lichess account into a bot account.
+//
+// The account is read from LICHESS_TOKEN, which needs the bot:play scope. The
+// upgrade is permanent and only works for accounts that have not played any games:
+//
+//	LICHESS_TOKEN=... go run ./cmd/upgrade
+//
+// With --dry-run the account is only checked and nothing is changed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://lichess.org"
+
+// account is the part of /api/account the upgrade needs
+type account struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Title    string `json:"title"`
+}
+
+// upgrader calls the Lichess API with a personal access token
+type upgrader struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func (u *upgrader) do(method, path string) ([]byte, error) {
+	req, err := http.NewRequest(method, u.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+u.token)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// account fetches the account the token belongs to
+func (u *upgrader) account() (account, error) {
+	var acc account
+	body, err := u.do(http.MethodGet, "/api/account")
+	if err != nil {
+		return acc, err
+	}
+	if err := json.Unmarshal(body, &acc); err != nil {
+		return acc, fmt.Errorf("failed to decode account: %v", err)
+	}
+	return acc, nil
+}
+
+// upgrade converts the account to a bot account
+func (u *upgrader) upgrade() error {
+	_, err := u.do(http.MethodPost, "/api/bot/account/upgrade")
+	return err
+}
+
+// run executes the command and returns the process exit code
+func run(args []string, token string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("upgrade", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dryRun := flags.Bool("dry-run", false, "only check whether the account is already a bot")
+	baseURL := flags.String("base-url", defaultBaseURL, "Lichess server URL")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if token == "" {
+		fmt.Fprintln(stderr, "LICHESS_TOKEN is not set. Create a token with the bot:play scope at https://lichess.org/account/oauth/token")
+		return 1
+	}
+
+	u := &upgrader{baseURL: strings.TrimRight(*baseURL, "/"), token: token, client: &http.Client{Timeout: 30 * time.Second}}
+	acc, err := u.account()
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to read account: %v\n", err)
+		return 1
+	}
+
+	if acc.Title == "BOT" {
+		fmt.Fprintf(stdout, "%s is already a bot account, nothing to do.\n", acc.Username)
+		return 0
+	}
+	if *dryRun {
+		fmt.Fprintf(stdout, "%s is not a bot account. Run without --dry-run to upgrade it.\n", acc.Username)
+		return 0
+	}
+
+	if err := u.upgrade(); err != nil {
+		fmt.Fprintf(stderr, "Failed to upgrade %s: %v\n", acc.Username, err)
+		fmt.Fprintln(stderr, "Only accounts that have never played a game can be upgraded.")
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s is now a bot account.\n", acc.Username)
+	return 0
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Getenv("LICHESS_TOKEN"), os.Stdout, os.Stderr))
+}
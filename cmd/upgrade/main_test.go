@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFakeLichess serves /api/account with the given title and counts upgrade calls
+func newFakeLichess(t *testing.T, title string, upgradeStatus int, upgrades *int) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/account", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test_token" {
+			t.Errorf("Unexpected Authorization header '%s'", auth)
+		}
+		w.Write([]byte(`{"id":"newbot","username":"NewBot","title":"` + title + `"}`))
+	})
+	mux.HandleFunc("POST /api/bot/account/upgrade", func(w http.ResponseWriter, r *http.Request) {
+		*upgrades++
+		w.WriteHeader(upgradeStatus)
+		w.Write([]byte(`{"error":"This account has played games"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		title         string
+		dryRun        bool
+		upgradeStatus int
+		wantCode      int
+		wantUpgrades  int
+		wantOutput    string
+	}{
+		{"upgrades account", "", false, http.StatusOK, 0, 1, "NewBot is now a bot account"},
+		{"already a bot", "BOT", false, http.StatusOK, 0, 0, "already a bot account"},
+		{"dry run", "", true, http.StatusOK, 0, 0, "is not a bot account"},
+		{"dry run on bot", "BOT", true, http.StatusOK, 0, 0, "already a bot account"},
+		{"upgrade rejected", "", false, http.StatusBadRequest, 1, 1, "never played a game"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upgrades := 0
+			server := newFakeLichess(t, tt.title, tt.upgradeStatus, &upgrades)
+
+			args := []string{"--base-url", server.URL}
+			if tt.dryRun {
+				args = append(args, "--dry-run")
+			}
+			var stdout, stderr bytes.Buffer
+			code := run(args, "test_token", &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tt.wantCode, code, stderr.String())
+			}
+			if upgrades != tt.wantUpgrades {
+				t.Errorf("Expected %d upgrade calls, got %d", tt.wantUpgrades, upgrades)
+			}
+			if output := stdout.String() + stderr.String(); !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.wantOutput, output)
+			}
+		})
+	}
+}
+
+func TestRun_MissingToken(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, "", &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "LICHESS_TOKEN") {
+		t.Errorf("Expected message about LICHESS_TOKEN, got '%s'", stderr.String())
+	}
+}
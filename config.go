@@ -1,20 +1,281 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPortCfg        = "8080"
+	defaultLichessBaseURL = "https://lichess.org"
+	defaultSimulateMoves  = 40
+	defaultAbortIdleSecs  = 60
+
+	defaultMaxStartupChallenges = 5
+	defaultMaxLLMCallsPerGame   = 300
+	defaultMaxExplanationLength = 140 // Lichess chat message limit
+	defaultKeepAliveTimeoutSecs = 30  // Lichess sends a keep-alive line every few seconds
+	defaultCompressAfterDays    = 7
+	defaultStockfishPath        = "stockfish"
+	defaultStockfishDepth       = 15
+	defaultHealthcheckInterval  = 60
+	defaultChallengePoolSize    = 4
+	defaultPuzzleCron           = "0 12 * * *"
+	defaultMinActiveGames       = 1
+	defaultSeekTimeControl      = "3+2"
+	defaultBatchWindowMS        = 50
+	defaultBatchMaxParallel     = 4
+	defaultTimeScrambleMS       = 10000
+	defaultMaxIllegalRetries    = 3
+	maxIllegalMoveRetries       = 10
+
+	defaultClockWarningMessage = "{player} has less than {seconds} seconds left"
 
-	"github.com/joho/godotenv"
+	defaultLLMFallbackTemperature = 0.8
+	defaultOpenRouterModel        = "openai/gpt-4o"
+	defaultFastModel              = "openai/gpt-4o-mini"
 )
 
-const defaultPortCfg = "8080"
+var defaultLLMStopSequences = []string{"\n", " "}
 
 // BotConfig holds all configuration for the bot
 type BotConfig struct {
 	LichessToken     string
 	OpenRouterAPIKey string
+	OpenRouterModel  string
 	Port             string
+	LichessBaseURL   string
+
+	// DebugGameID enables verbose logging for a single game; other games
+	// only get a one-line summary per event.
+	DebugGameID string
+
+	// Clock thresholds in milliseconds (0 disables the check).
+	// Below MoveTimeoutRequestFastModelMS FastModel is used for the move,
+	// below MoveTimeoutResignBelowMS the bot resigns.
+	MoveTimeoutResignBelowMS      int
+	MoveTimeoutRequestFastModelMS int
+	FastModel                     string
+
+	// Offline self-play mode: the bot plays itself instead of connecting to Lichess
+	SimulateOpponent   bool
+	SimulateMoves      int
+	SimulateWhiteModel string
+	SimulateBlackModel string
+
+	// PromptIncludeMoveNumbers renders the move list as "1. e2e4 e7e5 2. ..." in LLM prompts
+	PromptIncludeMoveNumbers bool
+
+	// Clock warnings sent to the player chat when a clock drops below a threshold.
+	// The message may use {player} and {seconds} placeholders.
+	ClockWarningThresholdsMS []int
+	ClockWarningMessage      string
+
+	// ChatResponseMap maps trigger phrases in opponent chat to reply templates
+	ChatResponseMap map[string]string
+
+	// LLMStopSequences are sent as "stop" so the model ends right after the move
+	LLMStopSequences []string
+
+	// WebhookURL receives a JSON report after every finished game,
+	// signed with WebhookSecret when one is set
+	WebhookURL    string
+	WebhookSecret string
+
+	// AnalysisMode compares every LLM move with the Stockfish best move
+	AnalysisMode bool
+
+	// HandoverDir is where active game state is saved on SIGTERM and resumed from on startup
+	HandoverDir string
+
+	// MaxGameDurationMinutes resigns games still running after this long (0 disables).
+	// With ResetTimeoutOnMove the countdown restarts after every move.
+	MaxGameDurationMinutes int
+	ResetTimeoutOnMove     bool
+
+	// OfferDrawAfterNMoves offers a draw once, after this many moves, if the position
+	// is roughly equal (0 disables)
+	OfferDrawAfterNMoves int
+
+	// TestMoveSequence is played instead of asking the LLM until it runs out
+	TestMoveSequence []string
+
+	// DisableLLM plays random legal moves instead of calling OpenRouter (testing only)
+	DisableLLM bool
+
+	// AllowedIPRanges restricts the admin API to these CIDR blocks (empty allows all)
+	AllowedIPRanges []netip.Prefix
+
+	// LLMFallbackTemperature is used when retrying after an invalid move,
+	// so the model explores other moves instead of repeating the same one
+	LLMFallbackTemperature float64
+
+	// AdminTLSCACert is a CA certificate file; when set, admin API clients must
+	// present a certificate signed by it
+	AdminTLSCACert string
+	// AdminToken is the bearer token admin endpoints such as /api/moves/suggest require
+	AdminToken string
+
+	// MaxMovesPerMinute logs an alert when a single game exceeds this move rate,
+	// which usually means a runaway loop (0 disables)
+	MaxMovesPerMinute int
+
+	// PonderMode pre-computes replies to the expected opponent move while waiting
+	PonderMode bool
+
+	// GameLogDir receives a {gameID}.log file per game in addition to the global log
+	GameLogDir string
+
+	// AbortIdleSeconds aborts a game when no move has been played this long after gameFull
+	AbortIdleSeconds int
+
+	// ChatLanguage selects the chat message templates (ISO 639-1 code)
+	ChatLanguage     string
+	ChatMessagesFile string
+	ChatMessages     ChatMessages
+
+	// StartupChallengePoll processes challenges that arrived while the bot was offline,
+	// at most MaxStartupChallenges of them
+	StartupChallengePoll bool
+	MaxStartupChallenges int
+
+	// AcceptOnlyTitled declines challenges from players without a Lichess title
+	AcceptOnlyTitled bool
+
+	// MaxLLMCallsPerGame caps LLM queries (including retries) in one game.
+	// Once exceeded, LLMCircuitBreakerAction ("resign" or "random") is taken.
+	MaxLLMCallsPerGame      int
+	LLMCircuitBreakerAction string
+
+	// PreloadModels sends a tiny query to every configured model at startup
+	// to warm up connections and surface API key problems early
+	PreloadModels bool
+
+	// LLMExtraHeaders are added to every OpenRouter request (may contain credentials)
+	LLMExtraHeaders map[string]string
+
+	// ExplainMoves sends a one-sentence LLM explanation of each bot move to the player chat
+	ExplainMoves         bool
+	MaxExplanationLength int
+
+	// ValidatorModel double-checks the primary model's move (empty disables validation)
+	ValidatorModel string
+
+	// LLMChainOfThought asks the model to reason before answering and reads the move from the last line
+	LLMChainOfThought bool
+
+	// GameTags are custom PGN headers added to every game, loaded from GAME_TAG_FILE (JSON)
+	GameTags map[string]string
+
+	// KeepAliveTimeoutSeconds reconnects a stream that has been completely silent this long
+	KeepAliveTimeoutSeconds int
+
+	// GamePGNDir holds finished game records; files older than CompressAfterDays are gzipped
+	GamePGNDir        string
+	CompressAfterDays int
+
+	// Engine selects the move source: "llm" (default) or "stockfish"
+	Engine         string
+	StockfishPath  string
+	StockfishDepth int
+
+	// HealthcheckPingURL is pinged every HealthcheckIntervalSeconds while the bot is alive
+	HealthcheckPingURL         string
+	HealthcheckIntervalSeconds int
+
+	// ChallengeProcessorPoolSize is the number of workers handling incoming challenges in parallel
+	ChallengeProcessorPoolSize int
+
+	// MoveLogCSV, when set, is the CSV file every move is appended to
+	MoveLogCSV string
+
+	// PuzzleMode makes the bot solve the daily puzzle on the PuzzleCron schedule instead of playing games
+	PuzzleMode bool
+	PuzzleCron string
+
+	// AutoSeek challenges an online bot matching the Seek* preferences whenever fewer
+	// than MinActiveGames games are running (BOT accounts cannot create real seeks)
+	AutoSeek        bool
+	MinActiveGames  int
+	SeekTimeControl string // "minutes+increment", e.g. "3+2"
+	SeekRatingRange string // "min-max", empty for any rating
+	SeekRated       bool
+
+	// MaxConcurrentGames limits simultaneous games (0 means no limit). Challenges arriving
+	// at the limit wait in a queue of ChallengeQueueSize instead of being declined.
+	MaxConcurrentGames int
+	ChallengeQueueSize int
+
+	// LLMMultiStep asks the model for candidate moves first and then for the best of them
+	LLMMultiStep bool
+
+	// Personality selects a playing style system prompt (see personalityPrompts); empty for none
+	Personality string
+
+	// LLMResponseFormatJSON requests a {"move": "..."} JSON object via response_format
+	LLMResponseFormatJSON bool
+
+	// GameMinClockSeconds declines clock challenges with less initial time than this (0 disables)
+	GameMinClockSeconds int
+
+	// ChatAdmins are the usernames allowed to issue "!" chat commands
+	ChatAdmins []string
+
+	// VerboseLLMLogs logs the full prompt and response of every LLM call instead of a summary
+	VerboseLLMLogs bool
+
+	// StudyOpponents are challenged at startup to practise openings; progress is kept in StudyRecordFile
+	StudyOpponents  []string
+	StudyRecordFile string
+
+	// LLM requests arriving within BatchWindowMS of each other are sent together,
+	// at most BatchMaxParallel at a time (BatchWindowMS 0 disables batching)
+	BatchWindowMS    int
+	BatchMaxParallel int
+
+	// PrewarmPGNFile is a PGN file whose games fill the position cache at startup
+	PrewarmPGNFile string
+
+	// DiscordWebhookURL receives an embed summarising every finished game
+	DiscordWebhookURL string
+
+	// LLMUseSAN shows the game's moves to the LLM in algebraic notation (Nf3) instead of UCI (g1f3)
+	LLMUseSAN bool
+
+	// MonthlyBudgetUSD caps the estimated OpenRouter spend per calendar month; once it is
+	// reached the cheapest configured model is used (0 means no budget)
+	MonthlyBudgetUSD float64
+	// LLMSpendFile keeps the estimated spend of each month across restarts
+	LLMSpendFile string
+
+	// The bot waits a random time between MinAutoplayDelayMS and MaxAutoplayDelayMS after
+	// the opponent's move before answering, so replies do not look instant. Both default to
+	// AutoplayDelayAfterOpponentMoveMS, which gives a fixed delay (0 disables it).
+	AutoplayDelayAfterOpponentMoveMS int
+	MinAutoplayDelayMS               int
+	MaxAutoplayDelayMS               int
+
+	// Below TimeScrambleThresholdMS on the bot's clock moves use the fastest prompt,
+	// without chain-of-thought or multi-step prompting (0 disables the check)
+	TimeScrambleThresholdMS int
+
+	// ChallengeAcceptProbability is the chance (0.0-1.0) that an otherwise acceptable
+	// challenge is accepted; the rest are declined as "later"
+	ChallengeAcceptProbability float64
+
+	// MaxIllegalMoveRetries is how many times the LLM is asked for a move before giving up
+	// when its answers are illegal
+	MaxIllegalMoveRetries int
+
+	// LLMModelAliases maps short names such as "gpt4o" to full OpenRouter model IDs.
+	// Model settings given as an alias are resolved when the config is loaded.
+	LLMModelAliases map[string]string
 }
 
 // LoadConfig loads the bot configuration from environment variables,
@@ -48,8 +309,386 @@ func LoadConfig() (*BotConfig, error) {
 		}
 	}
 
-	// Check required fields and provide defaults
-	if cfg.LichessToken == "" {
+	// Optional settings are read after .env has been loaded (if it was needed)
+	cfg.OpenRouterModel = os.Getenv("OPENROUTER_MODEL")
+	if cfg.OpenRouterModel == "" {
+		cfg.OpenRouterModel = defaultOpenRouterModel
+	}
+	cfg.LichessBaseURL = strings.TrimRight(os.Getenv("LICHESS_BASE_URL"), "/")
+	if cfg.LichessBaseURL == "" {
+		cfg.LichessBaseURL = defaultLichessBaseURL
+	}
+
+	cfg.DebugGameID = os.Getenv("DEBUG_GAME_ID")
+
+	var err error
+	if cfg.MoveTimeoutResignBelowMS, err = getEnvInt("MOVE_TIMEOUT_RESIGN_BELOW_MS", 0); err != nil {
+		return nil, err
+	}
+	if cfg.MoveTimeoutRequestFastModelMS, err = getEnvInt("MOVE_TIMEOUT_REQUEST_FAST_MODEL_MS", 0); err != nil {
+		return nil, err
+	}
+	cfg.FastModel = os.Getenv("OPENROUTER_FAST_MODEL")
+	if cfg.FastModel == "" && cfg.MoveTimeoutRequestFastModelMS > 0 {
+		cfg.FastModel = defaultFastModel
+	}
+
+	if cfg.SimulateOpponent, err = getEnvBool("SIMULATE_OPPONENT", false); err != nil {
+		return nil, err
+	}
+	if cfg.SimulateMoves, err = getEnvInt("SIMULATE_MOVES", defaultSimulateMoves); err != nil {
+		return nil, err
+	}
+	cfg.SimulateWhiteModel = os.Getenv("SIMULATE_WHITE_MODEL")
+	cfg.SimulateBlackModel = os.Getenv("SIMULATE_BLACK_MODEL")
+
+	if cfg.PromptIncludeMoveNumbers, err = getEnvBool("PROMPT_INCLUDE_MOVE_NUMBERS", true); err != nil {
+		return nil, err
+	}
+
+	if cfg.ClockWarningThresholdsMS, err = getEnvIntList("CLOCK_WARNING_THRESHOLDS_MS"); err != nil {
+		return nil, err
+	}
+	cfg.ClockWarningMessage = os.Getenv("CLOCK_WARNING_MESSAGE")
+	if cfg.ClockWarningMessage == "" {
+		cfg.ClockWarningMessage = defaultClockWarningMessage
+	}
+
+	if raw := os.Getenv("CHAT_RESPONSE_MAP"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.ChatResponseMap); err != nil {
+			return nil, fmt.Errorf("CHAT_RESPONSE_MAP must be a JSON object of strings: %v", err)
+		}
+	}
+
+	cfg.LLMStopSequences = append([]string(nil), defaultLLMStopSequences...)
+	if raw := os.Getenv("OPENROUTER_STOP_SEQUENCES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.LLMStopSequences); err != nil {
+			return nil, fmt.Errorf("OPENROUTER_STOP_SEQUENCES must be a JSON array of strings: %v", err)
+		}
+	}
+
+	cfg.WebhookURL = os.Getenv("WEBHOOK_URL")
+	cfg.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+
+	if cfg.AnalysisMode, err = getEnvBool("ANALYSIS_MODE", false); err != nil {
+		return nil, err
+	}
+
+	cfg.HandoverDir = os.Getenv("HANDOVER_DIR")
+
+	if cfg.MaxGameDurationMinutes, err = getEnvInt("MAX_GAME_DURATION_MINUTES", 0); err != nil {
+		return nil, err
+	}
+	if cfg.ResetTimeoutOnMove, err = getEnvBool("RESET_TIMEOUT_ON_MOVE", false); err != nil {
+		return nil, err
+	}
+
+	// DRAW_AFTER_N_MOVES replaces OFFER_DRAW_AFTER_MOVE_N, which is still read as a fallback
+	drawKey := "DRAW_AFTER_N_MOVES"
+	if os.Getenv(drawKey) == "" {
+		drawKey = "OFFER_DRAW_AFTER_MOVE_N"
+	}
+	if cfg.OfferDrawAfterNMoves, err = getEnvInt(drawKey, 0); err != nil {
+		return nil, err
+	}
+
+	cfg.TestMoveSequence = getEnvList("TEST_MOVE_SEQUENCE")
+	if len(cfg.TestMoveSequence) > 0 {
+		log.Printf("Warning: TEST_MOVE_SEQUENCE is set, the bot's first %d moves in every game will be scripted", len(cfg.TestMoveSequence))
+	}
+
+	if cfg.DisableLLM, err = getEnvBool("DISABLE_LLM", false); err != nil {
+		return nil, err
+	}
+	if cfg.DisableLLM {
+		log.Printf("WARNING: DISABLE_LLM is set, the bot will play random legal moves. Do not use this in production!")
+	}
+
+	for _, cidr := range getEnvList("ALLOWED_IP_RANGES") {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("ALLOWED_IP_RANGES contains invalid CIDR '%s': %v", cidr, err)
+		}
+		cfg.AllowedIPRanges = append(cfg.AllowedIPRanges, prefix.Masked())
+	}
+
+	if cfg.LLMFallbackTemperature, err = getEnvFloat("OPENROUTER_FALLBACK_TEMPERATURE", defaultLLMFallbackTemperature); err != nil {
+		return nil, err
+	}
+
+	cfg.AdminTLSCACert = os.Getenv("ADMIN_TLS_CA_CERT")
+	cfg.AdminToken = os.Getenv("ADMIN_TOKEN")
+
+	if cfg.MaxMovesPerMinute, err = getEnvInt("MAX_MOVES_PER_MINUTE", 0); err != nil {
+		return nil, err
+	}
+
+	if cfg.PonderMode, err = getEnvBool("PONDER_MODE", false); err != nil {
+		return nil, err
+	}
+
+	cfg.GameLogDir = os.Getenv("GAME_LOG_DIR")
+
+	if cfg.AbortIdleSeconds, err = getEnvInt("ABORT_IDLE_SECONDS", defaultAbortIdleSecs); err != nil {
+		return nil, err
+	}
+
+	cfg.ChatLanguage = strings.ToLower(os.Getenv("CHAT_LANGUAGE"))
+	if cfg.ChatLanguage == "" {
+		cfg.ChatLanguage = defaultChatLanguage
+	}
+	cfg.ChatMessagesFile = os.Getenv("CHAT_MESSAGES_FILE")
+	if cfg.ChatMessages, err = loadChatMessages(cfg.ChatMessagesFile); err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.ChatMessages[cfg.ChatLanguage]; !ok {
+		log.Printf("No chat messages for language '%s', falling back to English", cfg.ChatLanguage)
+	}
+
+	if cfg.StartupChallengePoll, err = getEnvBool("STARTUP_CHALLENGE_POLL", true); err != nil {
+		return nil, err
+	}
+	if cfg.MaxStartupChallenges, err = getEnvInt("MAX_STARTUP_CHALLENGES", defaultMaxStartupChallenges); err != nil {
+		return nil, err
+	}
+
+	if cfg.AcceptOnlyTitled, err = getEnvBool("ACCEPT_ONLY_TITLED", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxLLMCallsPerGame, err = getEnvInt("MAX_LLM_CALLS_PER_GAME", defaultMaxLLMCallsPerGame); err != nil {
+		return nil, err
+	}
+	cfg.LLMCircuitBreakerAction = os.Getenv("LLM_CIRCUIT_BREAKER_ACTION")
+	switch cfg.LLMCircuitBreakerAction {
+	case "":
+		cfg.LLMCircuitBreakerAction = BreakerActionResign
+	case BreakerActionResign, BreakerActionRandom:
+	default:
+		return nil, fmt.Errorf("LLM_CIRCUIT_BREAKER_ACTION must be '%s' or '%s', got '%s'",
+			BreakerActionResign, BreakerActionRandom, cfg.LLMCircuitBreakerAction)
+	}
+
+	if cfg.PreloadModels, err = getEnvBool("PRELOAD_MODELS", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.LLMExtraHeaders, err = parseHeaderList(os.Getenv("OPENROUTER_HEADERS")); err != nil {
+		return nil, fmt.Errorf("invalid OPENROUTER_HEADERS: %v", err)
+	}
+
+	if cfg.ExplainMoves, err = getEnvBool("EXPLAIN_MOVES", false); err != nil {
+		return nil, err
+	}
+	if cfg.MaxExplanationLength, err = getEnvInt("MAX_EXPLANATION_LENGTH", defaultMaxExplanationLength); err != nil {
+		return nil, err
+	}
+
+	cfg.ValidatorModel = os.Getenv("VALIDATOR_MODEL")
+
+	if cfg.LLMChainOfThought, err = getEnvBool("THINK_BEFORE_MOVE", false); err != nil {
+		return nil, err
+	}
+
+	if path := os.Getenv("GAME_TAG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GAME_TAG_FILE: %v", err)
+		}
+		if err := json.Unmarshal(data, &cfg.GameTags); err != nil {
+			return nil, fmt.Errorf("GAME_TAG_FILE must contain a JSON object of strings: %v", err)
+		}
+	}
+
+	if cfg.KeepAliveTimeoutSeconds, err = getEnvInt("KEEPALIVE_TIMEOUT_S", defaultKeepAliveTimeoutSecs); err != nil {
+		return nil, err
+	}
+
+	cfg.GamePGNDir = os.Getenv("GAME_PGN_DIR")
+	if cfg.CompressAfterDays, err = getEnvInt("COMPRESS_AFTER_DAYS", defaultCompressAfterDays); err != nil {
+		return nil, err
+	}
+
+	cfg.Engine = os.Getenv("ENGINE")
+	switch cfg.Engine {
+	case "":
+		cfg.Engine = EngineLLM
+	case EngineLLM, EngineStockfish:
+	default:
+		return nil, fmt.Errorf("ENGINE must be '%s' or '%s', got '%s'", EngineLLM, EngineStockfish, cfg.Engine)
+	}
+
+	cfg.StockfishPath = os.Getenv("STOCKFISH_PATH")
+	if cfg.StockfishPath == "" {
+		cfg.StockfishPath = defaultStockfishPath
+	}
+	if cfg.StockfishDepth, err = getEnvInt("STOCKFISH_DEPTH", defaultStockfishDepth); err != nil {
+		return nil, err
+	}
+
+	cfg.HealthcheckPingURL = strings.TrimSuffix(os.Getenv("HEALTHCHECK_PING_URL"), "/")
+	if cfg.HealthcheckIntervalSeconds, err = getEnvInt("HEALTHCHECK_INTERVAL_S", defaultHealthcheckInterval); err != nil {
+		return nil, err
+	}
+	if cfg.HealthcheckPingURL != "" && cfg.HealthcheckIntervalSeconds == 0 {
+		return nil, fmt.Errorf("HEALTHCHECK_INTERVAL_S must be greater than 0")
+	}
+
+	if cfg.ChallengeProcessorPoolSize, err = getEnvInt("CHALLENGE_PROCESSOR_POOL_SIZE", defaultChallengePoolSize); err != nil {
+		return nil, err
+	}
+	if cfg.ChallengeProcessorPoolSize == 0 {
+		return nil, fmt.Errorf("CHALLENGE_PROCESSOR_POOL_SIZE must be greater than 0")
+	}
+
+	cfg.MoveLogCSV = os.Getenv("MOVE_LOG_CSV")
+
+	if cfg.PuzzleMode, err = getEnvBool("ENABLE_PUZZLE_MODE", false); err != nil {
+		return nil, err
+	}
+	cfg.PuzzleCron = os.Getenv("PUZZLE_CRON")
+	if cfg.PuzzleCron == "" {
+		cfg.PuzzleCron = defaultPuzzleCron
+	}
+	if len(strings.Fields(cfg.PuzzleCron)) != 5 {
+		return nil, fmt.Errorf("PUZZLE_CRON must be a 5-field cron expression, got '%s'", cfg.PuzzleCron)
+	}
+
+	if cfg.AutoSeek, err = getEnvBool("AUTO_SEEK", false); err != nil {
+		return nil, err
+	}
+	if cfg.MinActiveGames, err = getEnvInt("MIN_ACTIVE_GAMES", defaultMinActiveGames); err != nil {
+		return nil, err
+	}
+	cfg.SeekTimeControl = os.Getenv("SEEK_TIME_CONTROL")
+	if cfg.SeekTimeControl == "" {
+		cfg.SeekTimeControl = defaultSeekTimeControl
+	}
+	if _, _, err := parseSeekTimeControl(cfg.SeekTimeControl); err != nil {
+		return nil, fmt.Errorf("invalid SEEK_TIME_CONTROL: %v", err)
+	}
+	cfg.SeekRatingRange = os.Getenv("SEEK_RATING_RANGE")
+	if cfg.SeekRatingRange != "" {
+		if _, _, err := parseRatingRange(cfg.SeekRatingRange); err != nil {
+			return nil, fmt.Errorf("invalid SEEK_RATING_RANGE: %v", err)
+		}
+	}
+	if cfg.SeekRated, err = getEnvBool("SEEK_RATED", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxConcurrentGames, err = getEnvInt("MAX_CONCURRENT_GAMES", 0); err != nil {
+		return nil, err
+	}
+	if cfg.ChallengeQueueSize, err = getEnvInt("CHALLENGE_QUEUE_SIZE", 0); err != nil {
+		return nil, err
+	}
+
+	if cfg.LLMMultiStep, err = getEnvBool("MULTI_STEP_PROMPT", false); err != nil {
+		return nil, err
+	}
+
+	cfg.Personality = os.Getenv("PERSONALITY")
+	if cfg.Personality != "" && !isValidPersonality(cfg.Personality) {
+		return nil, fmt.Errorf("PERSONALITY must be one of '%s', '%s', '%s' or '%s', got '%s'",
+			PersonalityAggressive, PersonalityPositional, PersonalityEndgameSpecialist, PersonalityRomantic, cfg.Personality)
+	}
+
+	if cfg.LLMResponseFormatJSON, err = getEnvBool("RESPONSE_FORMAT_JSON", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.GameMinClockSeconds, err = getEnvInt("GAME_MIN_CLOCK_SECONDS", 0); err != nil {
+		return nil, err
+	}
+
+	cfg.ChatAdmins = getEnvList("CHAT_ADMIN_USERNAMES")
+	if len(cfg.ChatAdmins) == 0 {
+		cfg.ChatAdmins = getEnvList("ADMIN_USERNAMES")
+	}
+
+	if cfg.VerboseLLMLogs, err = getEnvBool("VERBOSE_LLM_LOGGING", false); err != nil {
+		return nil, err
+	}
+
+	cfg.StudyOpponents = getEnvList("STUDY_OPPONENTS")
+	cfg.StudyRecordFile = os.Getenv("STUDY_RECORD_FILE")
+	if cfg.StudyRecordFile == "" {
+		cfg.StudyRecordFile = defaultStudyRecordFile
+	}
+
+	if cfg.BatchWindowMS, err = getEnvInt("BATCH_WINDOW_MS", defaultBatchWindowMS); err != nil {
+		return nil, err
+	}
+	if cfg.BatchWindowMS < 0 {
+		return nil, fmt.Errorf("BATCH_WINDOW_MS must not be negative, got %d", cfg.BatchWindowMS)
+	}
+	if cfg.BatchMaxParallel, err = getEnvInt("LLM_BATCH_MAX_PARALLEL", defaultBatchMaxParallel); err != nil {
+		return nil, err
+	}
+	if cfg.BatchMaxParallel <= 0 {
+		return nil, fmt.Errorf("LLM_BATCH_MAX_PARALLEL must be positive, got %d", cfg.BatchMaxParallel)
+	}
+
+	cfg.PrewarmPGNFile = os.Getenv("PREWARM_PGN_FILE")
+
+	cfg.DiscordWebhookURL = os.Getenv("DISCORD_WEBHOOK_URL")
+
+	if cfg.LLMUseSAN, err = getEnvBool("LLM_USE_SAN", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.MonthlyBudgetUSD, err = getEnvFloat("OPENROUTER_MONTHLY_BUDGET_USD", 0); err != nil {
+		return nil, err
+	}
+	cfg.LLMSpendFile = os.Getenv("OPENROUTER_SPEND_FILE")
+	if cfg.LLMSpendFile == "" {
+		cfg.LLMSpendFile = defaultLLMSpendFile
+	}
+
+	if cfg.AutoplayDelayAfterOpponentMoveMS, err = getEnvInt("GAME_AUTOPLAY_DELAY_AFTER_OPPONENT_MOVE_MS", 0); err != nil {
+		return nil, err
+	}
+	if cfg.MinAutoplayDelayMS, err = getEnvInt("MIN_AUTOPLAY_DELAY_MS", cfg.AutoplayDelayAfterOpponentMoveMS); err != nil {
+		return nil, err
+	}
+	if cfg.MaxAutoplayDelayMS, err = getEnvInt("MAX_AUTOPLAY_DELAY_MS", cfg.MinAutoplayDelayMS); err != nil {
+		return nil, err
+	}
+	if cfg.MaxAutoplayDelayMS < cfg.MinAutoplayDelayMS {
+		return nil, fmt.Errorf("MAX_AUTOPLAY_DELAY_MS (%d) must not be less than MIN_AUTOPLAY_DELAY_MS (%d)",
+			cfg.MaxAutoplayDelayMS, cfg.MinAutoplayDelayMS)
+	}
+
+	if cfg.TimeScrambleThresholdMS, err = getEnvInt("TIME_SCRAMBLE_THRESHOLD_MS", defaultTimeScrambleMS); err != nil {
+		return nil, err
+	}
+
+	if cfg.ChallengeAcceptProbability, err = getEnvFloat("CHALLENGE_ACCEPT_PROBABILITY", 1.0); err != nil {
+		return nil, err
+	}
+	if cfg.ChallengeAcceptProbability > 1 {
+		return nil, fmt.Errorf("CHALLENGE_ACCEPT_PROBABILITY must be between 0.0 and 1.0, got %v", cfg.ChallengeAcceptProbability)
+	}
+
+	if cfg.MaxIllegalMoveRetries, err = getEnvInt("MAX_ILLEGAL_MOVE_RETRIES", defaultMaxIllegalRetries); err != nil {
+		return nil, err
+	}
+	if cfg.MaxIllegalMoveRetries < 1 || cfg.MaxIllegalMoveRetries > maxIllegalMoveRetries {
+		return nil, fmt.Errorf("MAX_ILLEGAL_MOVE_RETRIES must be between 1 and %d, got %d", maxIllegalMoveRetries, cfg.MaxIllegalMoveRetries)
+	}
+
+	if raw := os.Getenv("OPENROUTER_MODEL_ALIASES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.LLMModelAliases); err != nil {
+			return nil, fmt.Errorf("OPENROUTER_MODEL_ALIASES must be a JSON object of strings: %v", err)
+		}
+	}
+	for _, model := range []*string{&cfg.OpenRouterModel, &cfg.FastModel, &cfg.ValidatorModel, &cfg.SimulateWhiteModel, &cfg.SimulateBlackModel} {
+		*model = cfg.ResolveModel(*model)
+	}
+
+	// Check required fields and provide defaults. Simulations never talk to Lichess.
+	if cfg.LichessToken == "" && !cfg.SimulateOpponent {
 		return nil, fmt.Errorf("LICHESS_TOKEN environment variable not set")
 	}
 
@@ -66,10 +705,131 @@ func LoadConfig() (*BotConfig, error) {
 	return cfg, nil
 }
 
-// loadDotEnv loads variables from a .env file into the environment
+// ConfiguredModels returns every distinct LLM model the configuration refers to
+func (c *BotConfig) ConfiguredModels() []string {
+	var models []string
+	seen := make(map[string]bool)
+	for _, model := range []string{c.OpenRouterModel, c.FastModel, c.ValidatorModel, c.SimulateWhiteModel, c.SimulateBlackModel} {
+		if model != "" && !seen[model] {
+			seen[model] = true
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// ResolveModel returns the full model ID for an alias from OPENROUTER_MODEL_ALIASES;
+// names that are not aliases are returned unchanged
+func (c *BotConfig) ResolveModel(name string) string {
+	if full, ok := c.LLMModelAliases[name]; ok && full != "" {
+		return full
+	}
+	return name
+}
+
+// IsDebugGame reports whether verbose logging is enabled for the given game.
+func (c *BotConfig) IsDebugGame(gameID string) bool {
+	return c.DebugGameID != "" && c.DebugGameID == gameID
+}
+
+// getEnvInt reads a non-negative integer from the environment,
+// returning def if the variable is not set
+func getEnvInt(key string, def int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	val, err := strconv.Atoi(raw)
+	if err != nil || val < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got '%s'", key, raw)
+	}
+	return val, nil
+}
+
+// getEnvList reads a comma-separated list of strings from the environment, skipping empty items
+func getEnvList(key string) []string {
+	var vals []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			vals = append(vals, part)
+		}
+	}
+	return vals
+}
+
+// getEnvIntList reads a comma-separated list of non-negative integers from the environment
+func getEnvIntList(key string) ([]int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil, nil
+	}
+	var vals []int
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		val, err := strconv.Atoi(part)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("%s must be a comma-separated list of non-negative integers, got '%s'", key, raw)
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
+
+// getEnvFloat reads a finite, non-negative number from the environment, returning def if the variable is not set
+func getEnvFloat(key string, def float64) (float64, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	val, err := strconv.ParseFloat(raw, 64)
+	// NaN would slip through every later range check, so reject it along with infinities
+	if err != nil || val < 0 || math.IsNaN(val) || math.IsInf(val, 0) {
+		return 0, fmt.Errorf("%s must be a non-negative number, got '%s'", key, raw)
+	}
+	return val, nil
+}
+
+// parseHeaderList parses "key1:value1,key2:value2" into a header map
+func parseHeaderList(raw string) (map[string]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("expected key:value, got '%s'", strings.TrimSpace(pair))
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// getEnvBool reads a boolean from the environment, returning def if the variable is not set
+func getEnvBool(key string, def bool) (bool, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	val, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean, got '%s'", key, raw)
+	}
+	return val, nil
+}
+
+// loadDotEnv loads variables from a .env file (and any files it imports) into the environment
 func loadDotEnv() error {
 	// Load .env file
-	err := godotenv.Load()
+	envVars, err := readEnvFileWithImports(".env")
 	if err != nil {
 		if os.IsNotExist(err) {
 			log.Printf("No .env file found, using system environment variables")
@@ -79,11 +839,11 @@ func loadDotEnv() error {
 		return err
 	}
 
-	// Проверяем, сколько переменных было загружено
-	envVars, readErr := godotenv.Read()
-	if readErr != nil {
-		log.Printf("Error reading .env file content: %v", readErr)
-		return nil // Игнорируем ошибку чтения, т.к. Load() уже отработал успешно
+	// Variables already set in the system environment take precedence
+	for key, val := range envVars {
+		if _, isSet := os.LookupEnv(key); !isSet {
+			os.Setenv(key, val)
+		}
 	}
 
 	if len(envVars) == 0 {
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// sensitiveConfigFields are never printed in config change reports. Webhook and
+// ping URLs often carry a token in the path or query, so they count as secrets.
+var sensitiveConfigFields = map[string]bool{
+	"LichessToken":       true,
+	"OpenRouterAPIKey":   true,
+	"LLMExtraHeaders":    true,
+	"WebhookSecret":      true,
+	"AdminToken":         true,
+	"WebhookURL":         true,
+	"DiscordWebhookURL":  true,
+	"HealthcheckPingURL": true,
+}
+
+// Diff returns human-readable descriptions of the fields that differ between
+// c and other, e.g. "Port changed from 8080 to 9090". Secrets are redacted and
+// maps, such as the chat messages, are summarised by their size.
+func (c *BotConfig) Diff(other *BotConfig) []string {
+	if c == nil || other == nil {
+		return nil
+	}
+
+	var changes []string
+	oldVal := reflect.ValueOf(*c)
+	newVal := reflect.ValueOf(*other)
+	configType := oldVal.Type()
+
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		before := oldVal.Field(i).Interface()
+		after := newVal.Field(i).Interface()
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+
+		if sensitiveConfigFields[field.Name] {
+			changes = append(changes, fmt.Sprintf("%s changed (value redacted)", field.Name))
+			continue
+		}
+		if field.Type.Kind() == reflect.Map {
+			changes = append(changes, fmt.Sprintf("%s changed (%d entries, was %d)",
+				field.Name, newVal.Field(i).Len(), oldVal.Field(i).Len()))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s changed from %v to %v", field.Name, before, after))
+	}
+	return changes
+}
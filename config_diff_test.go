@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBotConfig_Diff(t *testing.T) {
+	before := &BotConfig{
+		LichessToken:     "old_token",
+		OpenRouterAPIKey: "key",
+		Port:             "8080",
+		AnalysisMode:     false,
+		TestMoveSequence: []string{"e2e4"},
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(cfg *BotConfig)
+		expected []string
+	}{
+		{
+			name:     "no changes",
+			modify:   func(cfg *BotConfig) {},
+			expected: nil,
+		},
+		{
+			name:     "plain field",
+			modify:   func(cfg *BotConfig) { cfg.Port = "9090" },
+			expected: []string{"Port changed from 8080 to 9090"},
+		},
+		{
+			name:     "bool and slice fields",
+			modify:   func(cfg *BotConfig) { cfg.AnalysisMode = true; cfg.TestMoveSequence = []string{"d2d4"} },
+			expected: []string{"AnalysisMode changed from false to true", "TestMoveSequence changed from [e2e4] to [d2d4]"},
+		},
+		{
+			name:     "sensitive field is redacted",
+			modify:   func(cfg *BotConfig) { cfg.LichessToken = "new_token" },
+			expected: []string{"LichessToken changed (value redacted)"},
+		},
+		{
+			name: "URLs with tokens are redacted",
+			modify: func(cfg *BotConfig) {
+				cfg.WebhookURL = "https://example.com/hook?token=new_token"
+				cfg.HealthcheckPingURL = "https://hc-ping.com/new_token"
+			},
+			expected: []string{"WebhookURL changed (value redacted)", "HealthcheckPingURL changed (value redacted)"},
+		},
+		{
+			name:     "maps are summarised",
+			modify:   func(cfg *BotConfig) { cfg.ChatResponseMap = map[string]string{"hello": "Hi!", "gg": "Good game!"} },
+			expected: []string{"ChatResponseMap changed (2 entries, was 0)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := *before
+			tt.modify(&after)
+
+			changes := before.Diff(&after)
+			if strings.Join(changes, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected %q, got %q", tt.expected, changes)
+			}
+			for _, change := range changes {
+				if strings.Contains(change, "old_token") || strings.Contains(change, "new_token") {
+					t.Errorf("Secret leaked in change report: %s", change)
+				}
+			}
+		})
+	}
+}
+
+func TestBotConfig_Diff_RedactsExtraHeaders(t *testing.T) {
+	before := &BotConfig{LLMExtraHeaders: map[string]string{"X-Gateway-Key": "old_secret"}}
+	after := &BotConfig{LLMExtraHeaders: map[string]string{"X-Gateway-Key": "new_secret"}}
+
+	changes := before.Diff(after)
+	if len(changes) != 1 || changes[0] != "LLMExtraHeaders changed (value redacted)" {
+		t.Errorf("Expected redacted header change, got %q", changes)
+	}
+}
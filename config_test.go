@@ -496,3 +496,604 @@ VALID_KEY=valid_value
 		t.Errorf("Expected VALID_KEY to be 'valid_value', got '%s'", val)
 	}
 }
+
+func TestLoadConfig_DebugGameID(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_debug",
+		"OPENROUTER_API_KEY": "key_debug",
+		"PORT":               "8081",
+		"DEBUG_GAME_ID":      "abcd1234",
+	})
+	defer cleanupEnv()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if cfg.DebugGameID != "abcd1234" {
+		t.Errorf("Expected DebugGameID 'abcd1234', got '%s'", cfg.DebugGameID)
+	}
+	if !cfg.IsDebugGame("abcd1234") {
+		t.Errorf("Expected IsDebugGame to be true for 'abcd1234'")
+	}
+	if cfg.IsDebugGame("other") {
+		t.Errorf("Expected IsDebugGame to be false for 'other'")
+	}
+}
+
+func TestLoadConfig_MoveTimeoutThresholds(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":                      "token_timeout",
+		"OPENROUTER_API_KEY":                 "key_timeout",
+		"PORT":                               "8081",
+		"MOVE_TIMEOUT_RESIGN_BELOW_MS":       "2000",
+		"MOVE_TIMEOUT_REQUEST_FAST_MODEL_MS": "15000",
+	})
+	defer cleanupEnv()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if cfg.MoveTimeoutResignBelowMS != 2000 {
+		t.Errorf("Expected MoveTimeoutResignBelowMS 2000, got %d", cfg.MoveTimeoutResignBelowMS)
+	}
+	if cfg.MoveTimeoutRequestFastModelMS != 15000 {
+		t.Errorf("Expected MoveTimeoutRequestFastModelMS 15000, got %d", cfg.MoveTimeoutRequestFastModelMS)
+	}
+	if cfg.FastModel != defaultFastModel {
+		t.Errorf("Expected default FastModel '%s' with a fast model threshold, got '%s'", defaultFastModel, cfg.FastModel)
+	}
+}
+
+func TestLoadConfig_InvalidInteger(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":                "token_invalid",
+		"OPENROUTER_API_KEY":           "key_invalid",
+		"PORT":                         "8081",
+		"MOVE_TIMEOUT_RESIGN_BELOW_MS": "soon",
+	})
+	defer cleanupEnv()
+
+	_, err := LoadConfig()
+	if err == nil {
+		t.Fatal("Expected error for non-numeric MOVE_TIMEOUT_RESIGN_BELOW_MS, but got nil")
+	}
+	if !strings.Contains(err.Error(), "MOVE_TIMEOUT_RESIGN_BELOW_MS") {
+		t.Errorf("Expected error message to mention MOVE_TIMEOUT_RESIGN_BELOW_MS, got '%s'", err.Error())
+	}
+}
+
+func TestLoadConfig_SimulateOpponent(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":        "token_simulate",
+		"OPENROUTER_API_KEY":   "key_simulate",
+		"PORT":                 "8081",
+		"SIMULATE_OPPONENT":    "true",
+		"SIMULATE_WHITE_MODEL": "openai/gpt-4o",
+		"SIMULATE_BLACK_MODEL": "anthropic/claude-3-5-sonnet",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("SIMULATE_MOVES")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if !cfg.SimulateOpponent {
+		t.Errorf("Expected SimulateOpponent to be true")
+	}
+	if cfg.SimulateMoves != defaultSimulateMoves {
+		t.Errorf("Expected default SimulateMoves %d, got %d", defaultSimulateMoves, cfg.SimulateMoves)
+	}
+	if cfg.SimulateWhiteModel != "openai/gpt-4o" {
+		t.Errorf("Expected SimulateWhiteModel 'openai/gpt-4o', got '%s'", cfg.SimulateWhiteModel)
+	}
+	if cfg.SimulateBlackModel != "anthropic/claude-3-5-sonnet" {
+		t.Errorf("Expected SimulateBlackModel 'anthropic/claude-3-5-sonnet', got '%s'", cfg.SimulateBlackModel)
+	}
+}
+
+func TestLoadConfig_ClockWarningThresholds(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":               "token_clock",
+		"OPENROUTER_API_KEY":          "key_clock",
+		"PORT":                        "8081",
+		"CLOCK_WARNING_THRESHOLDS_MS": "60000, 30000,10000",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("CLOCK_WARNING_MESSAGE")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	expected := []int{60000, 30000, 10000}
+	if len(cfg.ClockWarningThresholdsMS) != len(expected) {
+		t.Fatalf("Expected thresholds %v, got %v", expected, cfg.ClockWarningThresholdsMS)
+	}
+	for i, v := range expected {
+		if cfg.ClockWarningThresholdsMS[i] != v {
+			t.Errorf("Expected threshold %d at index %d, got %d", v, i, cfg.ClockWarningThresholdsMS[i])
+		}
+	}
+	if cfg.ClockWarningMessage != defaultClockWarningMessage {
+		t.Errorf("Expected default ClockWarningMessage, got '%s'", cfg.ClockWarningMessage)
+	}
+}
+
+func TestLoadConfig_ChatResponseMap(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_chat",
+		"OPENROUTER_API_KEY": "key_chat",
+		"PORT":               "8081",
+		"CHAT_RESPONSE_MAP":  `{"good game": "Thanks, good game!"}`,
+	})
+	defer cleanupEnv()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.ChatResponseMap["good game"] != "Thanks, good game!" {
+		t.Errorf("Expected response for 'good game', got %v", cfg.ChatResponseMap)
+	}
+
+	os.Setenv("CHAT_RESPONSE_MAP", "not json")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected error for malformed CHAT_RESPONSE_MAP, but got nil")
+	}
+}
+
+func TestLoadConfig_StopSequences(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_stop",
+		"OPENROUTER_API_KEY": "key_stop",
+		"PORT":               "8081",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("OPENROUTER_STOP_SEQUENCES")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(cfg.LLMStopSequences) != 2 || cfg.LLMStopSequences[0] != "\n" || cfg.LLMStopSequences[1] != " " {
+		t.Errorf("Expected default stop sequences, got %q", cfg.LLMStopSequences)
+	}
+
+	os.Setenv("OPENROUTER_STOP_SEQUENCES", `["\n", "."]`)
+	defer os.Unsetenv("OPENROUTER_STOP_SEQUENCES")
+
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(cfg.LLMStopSequences) != 2 || cfg.LLMStopSequences[1] != "." {
+		t.Errorf("Expected custom stop sequences, got %q", cfg.LLMStopSequences)
+	}
+}
+
+func TestLoadConfig_LichessBaseURL(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_base_url",
+		"OPENROUTER_API_KEY": "key_base_url",
+		"PORT":               "8081",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("LICHESS_BASE_URL")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.LichessBaseURL != defaultLichessBaseURL {
+		t.Errorf("Expected default LichessBaseURL '%s', got '%s'", defaultLichessBaseURL, cfg.LichessBaseURL)
+	}
+
+	os.Setenv("LICHESS_BASE_URL", "http://localhost:9999/")
+	defer os.Unsetenv("LICHESS_BASE_URL")
+
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.LichessBaseURL != "http://localhost:9999" {
+		t.Errorf("Expected LichessBaseURL 'http://localhost:9999', got '%s'", cfg.LichessBaseURL)
+	}
+}
+
+func TestLoadConfig_TestMoveSequence(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_sequence",
+		"OPENROUTER_API_KEY": "key_sequence",
+		"PORT":               "8081",
+		"TEST_MOVE_SEQUENCE": "e2e4, g1f3,,f1c4",
+	})
+	defer cleanupEnv()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	expected := []string{"e2e4", "g1f3", "f1c4"}
+	if strings.Join(cfg.TestMoveSequence, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected TestMoveSequence %v, got %v", expected, cfg.TestMoveSequence)
+	}
+}
+
+func TestLoadConfig_DisableLLM(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_disable_llm",
+		"OPENROUTER_API_KEY": "key_disable_llm",
+		"PORT":               "8081",
+		"DISABLE_LLM":        "true",
+	})
+	defer cleanupEnv()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if !cfg.DisableLLM {
+		t.Error("Expected DisableLLM to be true")
+	}
+
+	os.Setenv("DISABLE_LLM", "maybe")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected error for invalid DISABLE_LLM value, but got nil")
+	}
+}
+
+func TestLoadConfig_AllowedIPRanges(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_ip",
+		"OPENROUTER_API_KEY": "key_ip",
+		"PORT":               "8081",
+		"ALLOWED_IP_RANGES":  "10.0.0.0/8, 2001:db8::/32",
+	})
+	defer cleanupEnv()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(cfg.AllowedIPRanges) != 2 || cfg.AllowedIPRanges[1].String() != "2001:db8::/32" {
+		t.Errorf("Unexpected AllowedIPRanges %v", cfg.AllowedIPRanges)
+	}
+
+	os.Setenv("ALLOWED_IP_RANGES", "10.0.0.0/33")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected error for invalid CIDR, but got nil")
+	}
+}
+
+func TestLoadConfig_OpenRouterModel(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_model",
+		"OPENROUTER_API_KEY": "key_model",
+		"PORT":               "8081",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("OPENROUTER_MODEL")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.OpenRouterModel != defaultOpenRouterModel {
+		t.Errorf("Expected default OpenRouterModel '%s', got '%s'", defaultOpenRouterModel, cfg.OpenRouterModel)
+	}
+	if models := cfg.ConfiguredModels(); len(models) != 1 || models[0] != defaultOpenRouterModel {
+		t.Errorf("Expected ConfiguredModels [%s], got %v", defaultOpenRouterModel, models)
+	}
+}
+
+func TestParseHeaderList(t *testing.T) {
+	headers, err := parseHeaderList("X-Tenant-ID:chess-team, X-Trace: a:b ,")
+	if err != nil {
+		t.Fatalf("parseHeaderList() failed: %v", err)
+	}
+	if len(headers) != 2 || headers["X-Tenant-ID"] != "chess-team" || headers["X-Trace"] != "a:b" {
+		t.Errorf("Unexpected headers %v", headers)
+	}
+
+	if headers, err := parseHeaderList(""); err != nil || headers != nil {
+		t.Errorf("Expected nil headers for empty input, got %v (err=%v)", headers, err)
+	}
+
+	for _, bad := range []string{"no-colon", ":value"} {
+		if _, err := parseHeaderList(bad); err == nil {
+			t.Errorf("Expected error for '%s', but got nil", bad)
+		}
+	}
+}
+
+func TestLoadConfig_GameTagFile(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	tagFile := filepath.Join(t.TempDir(), "tags.json")
+	if err := os.WriteFile(tagFile, []byte(`{"style": "aggressive", "tested": "true"}`), 0600); err != nil {
+		t.Fatalf("Failed to write tag file: %v", err)
+	}
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_tags",
+		"OPENROUTER_API_KEY": "key_tags",
+		"PORT":               "8081",
+		"GAME_TAG_FILE":      tagFile,
+	})
+	defer cleanupEnv()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.GameTags["style"] != "aggressive" || cfg.GameTags["tested"] != "true" {
+		t.Errorf("Unexpected GameTags %v", cfg.GameTags)
+	}
+
+	os.Setenv("GAME_TAG_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected error for missing GAME_TAG_FILE, but got nil")
+	}
+}
+
+func TestLoadConfig_ChatAdmins(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_admins",
+		"OPENROUTER_API_KEY": "key_admins",
+		"PORT":               "8081",
+		"ADMIN_USERNAMES":    "owner,helper",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("CHAT_ADMIN_USERNAMES")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(cfg.ChatAdmins) != 2 || cfg.ChatAdmins[0] != "owner" {
+		t.Errorf("Expected ChatAdmins to fall back to ADMIN_USERNAMES, got %v", cfg.ChatAdmins)
+	}
+
+	os.Setenv("CHAT_ADMIN_USERNAMES", "chatmod")
+	defer os.Unsetenv("CHAT_ADMIN_USERNAMES")
+	if cfg, err = LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if len(cfg.ChatAdmins) != 1 || cfg.ChatAdmins[0] != "chatmod" {
+		t.Errorf("Expected ChatAdmins [chatmod], got %v", cfg.ChatAdmins)
+	}
+}
+
+func TestLoadConfig_DrawAfterNMoves(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":           "token_draw",
+		"OPENROUTER_API_KEY":      "key_draw",
+		"PORT":                    "8081",
+		"OFFER_DRAW_AFTER_MOVE_N": "60",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("DRAW_AFTER_N_MOVES")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.OfferDrawAfterNMoves != 60 {
+		t.Errorf("Expected OFFER_DRAW_AFTER_MOVE_N fallback 60, got %d", cfg.OfferDrawAfterNMoves)
+	}
+
+	os.Setenv("DRAW_AFTER_N_MOVES", "80")
+	defer os.Unsetenv("DRAW_AFTER_N_MOVES")
+	if cfg, err = LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.OfferDrawAfterNMoves != 80 {
+		t.Errorf("Expected DRAW_AFTER_N_MOVES 80 to take precedence, got %d", cfg.OfferDrawAfterNMoves)
+	}
+}
+
+func TestLoadConfig_AutoplayDelay(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_delay",
+		"OPENROUTER_API_KEY": "key_delay",
+		"PORT":               "8081",
+		"GAME_AUTOPLAY_DELAY_AFTER_OPPONENT_MOVE_MS": "800",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("MIN_AUTOPLAY_DELAY_MS")
+	os.Unsetenv("MAX_AUTOPLAY_DELAY_MS")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.MinAutoplayDelayMS != 800 || cfg.MaxAutoplayDelayMS != 800 {
+		t.Errorf("Expected a fixed 800ms delay, got %d-%d", cfg.MinAutoplayDelayMS, cfg.MaxAutoplayDelayMS)
+	}
+
+	os.Setenv("MIN_AUTOPLAY_DELAY_MS", "500")
+	os.Setenv("MAX_AUTOPLAY_DELAY_MS", "2000")
+	defer os.Unsetenv("MIN_AUTOPLAY_DELAY_MS")
+	defer os.Unsetenv("MAX_AUTOPLAY_DELAY_MS")
+	if cfg, err = LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.MinAutoplayDelayMS != 500 || cfg.MaxAutoplayDelayMS != 2000 {
+		t.Errorf("Expected a 500-2000ms delay, got %d-%d", cfg.MinAutoplayDelayMS, cfg.MaxAutoplayDelayMS)
+	}
+
+	os.Setenv("MAX_AUTOPLAY_DELAY_MS", "100")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected error when MAX_AUTOPLAY_DELAY_MS is below MIN_AUTOPLAY_DELAY_MS")
+	}
+}
+
+func TestLoadConfig_MaxIllegalMoveRetries(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_retries",
+		"OPENROUTER_API_KEY": "key_retries",
+		"PORT":               "8081",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("MAX_ILLEGAL_MOVE_RETRIES")
+	defer os.Unsetenv("MAX_ILLEGAL_MOVE_RETRIES")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.MaxIllegalMoveRetries != 3 {
+		t.Errorf("Expected default of 3 retries, got %d", cfg.MaxIllegalMoveRetries)
+	}
+
+	for value, wantErr := range map[string]bool{"1": false, "10": false, "0": true, "11": true} {
+		os.Setenv("MAX_ILLEGAL_MOVE_RETRIES", value)
+		_, err := LoadConfig()
+		if wantErr && err == nil {
+			t.Errorf("Expected error for MAX_ILLEGAL_MOVE_RETRIES=%s", value)
+		}
+		if !wantErr && err != nil {
+			t.Errorf("Expected MAX_ILLEGAL_MOVE_RETRIES=%s to be accepted, got %v", value, err)
+		}
+	}
+}
+
+func TestLoadConfig_NonFiniteFloats(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "token_floats",
+		"OPENROUTER_API_KEY": "key_floats",
+		"PORT":               "8081",
+	})
+	defer cleanupEnv()
+
+	for _, key := range []string{"CHALLENGE_ACCEPT_PROBABILITY", "OPENROUTER_FALLBACK_TEMPERATURE", "OPENROUTER_MONTHLY_BUDGET_USD"} {
+		for _, value := range []string{"NaN", "Inf", "+Inf"} {
+			os.Setenv(key, value)
+			_, err := LoadConfig()
+			os.Unsetenv(key)
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("Expected error mentioning %s for %s=%s, got %v", key, key, value, err)
+			}
+		}
+	}
+}
+
+func TestBotConfig_ResolveModel(t *testing.T) {
+	cfg := &BotConfig{LLMModelAliases: map[string]string{
+		"gpt4o":  "openai/gpt-4o",
+		"claude": "anthropic/claude-3-5-sonnet",
+		"empty":  "",
+	}}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"gpt4o", "openai/gpt-4o"},
+		{"claude", "anthropic/claude-3-5-sonnet"},
+		{"openai/gpt-4o-mini", "openai/gpt-4o-mini"},
+		{"GPT4O", "GPT4O"},
+		{"empty", "empty"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.ResolveModel(tt.name); got != tt.expected {
+			t.Errorf("ResolveModel(%q): expected '%s', got '%s'", tt.name, tt.expected, got)
+		}
+	}
+
+	if got := (&BotConfig{}).ResolveModel("gpt4o"); got != "gpt4o" {
+		t.Errorf("Expected names to pass through without aliases, got '%s'", got)
+	}
+}
+
+func TestLoadConfig_ModelAliases(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "")
+	defer cleanupWD()
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":            "token_aliases",
+		"OPENROUTER_API_KEY":       "key_aliases",
+		"PORT":                     "8081",
+		"OPENROUTER_MODEL":         "gpt4o",
+		"VALIDATOR_MODEL":          "claude",
+		"OPENROUTER_MODEL_ALIASES": `{"gpt4o": "openai/gpt-4o", "claude": "anthropic/claude-3-5-sonnet"}`,
+	})
+	defer cleanupEnv()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.OpenRouterModel != "openai/gpt-4o" {
+		t.Errorf("Expected OPENROUTER_MODEL alias to resolve to 'openai/gpt-4o', got '%s'", cfg.OpenRouterModel)
+	}
+	if cfg.ValidatorModel != "anthropic/claude-3-5-sonnet" {
+		t.Errorf("Expected VALIDATOR_MODEL alias to resolve, got '%s'", cfg.ValidatorModel)
+	}
+
+	os.Setenv("OPENROUTER_MODEL_ALIASES", `["not", "an", "object"]`)
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected error for invalid OPENROUTER_MODEL_ALIASES")
+	}
+}
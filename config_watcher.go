@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const configWatchDebounce = 100 * time.Millisecond
+
+// ConfigWatcher reloads the bot configuration when the .env file changes
+type ConfigWatcher struct {
+	path string
+
+	mu         sync.Mutex
+	fileValues map[string]string // values last applied from the file
+	current    *BotConfig
+}
+
+// NewConfigWatcher creates a watcher for the given .env file, starting from the current config
+func NewConfigWatcher(path string, current *BotConfig) *ConfigWatcher {
+	values, err := readEnvFileWithImports(path)
+	if err != nil {
+		values = map[string]string{}
+	}
+	return &ConfigWatcher{path: path, fileValues: values, current: current}
+}
+
+// Watch blocks until ctx is cancelled, calling onChange with the reloaded
+// configuration every time the .env file changes and the new config is valid.
+func (w *ConfigWatcher) Watch(ctx context.Context, onChange func(*BotConfig)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	// Watch the directory rather than the file, editors often replace the file on save
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %v", w.path, err)
+	}
+
+	target := filepath.Clean(w.path)
+	var debounce *time.Timer
+	defer func() {
+		if debounce != nil {
+			debounce.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != target || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			// Wait for writes to settle before re-reading the file
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = time.AfterFunc(configWatchDebounce, func() {
+				w.reload(onChange)
+			})
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Config watcher error: %v", err)
+		}
+	}
+}
+
+// reload applies the current file contents to the environment and rebuilds the config
+func (w *ConfigWatcher) reload(onChange func(*BotConfig)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	values, err := readEnvFileWithImports(w.path)
+	if err != nil {
+		log.Printf("Config reload skipped: failed to read %s: %v", w.path, err)
+		return
+	}
+
+	w.applyFileValues(values)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		log.Printf("Config reload skipped: %v", err)
+		return
+	}
+
+	changes := w.current.Diff(cfg)
+	if w.current != nil && len(changes) == 0 {
+		log.Printf("Config file %s changed, but no settings changed", w.path)
+		return
+	}
+	for _, change := range changes {
+		log.Printf("Config reload: %s", change)
+	}
+	w.current = cfg
+	onChange(cfg)
+}
+
+// applyFileValues updates environment variables that came from the file.
+// Variables set in the system environment keep precedence over the file.
+func (w *ConfigWatcher) applyFileValues(values map[string]string) {
+	for key, oldVal := range w.fileValues {
+		if _, stillSet := values[key]; !stillSet && os.Getenv(key) == oldVal {
+			os.Unsetenv(key)
+		}
+	}
+	for key, val := range values {
+		current, isSet := os.LookupEnv(key)
+		if !isSet || current == w.fileValues[key] {
+			os.Setenv(key, val)
+		}
+	}
+	w.fileValues = values
+}
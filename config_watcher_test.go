@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher_Watch(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	initial := "LICHESS_TOKEN=watch_token\nOPENROUTER_API_KEY=watch_key\nPORT=7000\n"
+	if err := os.WriteFile(envFile, []byte(initial), 0600); err != nil {
+		t.Fatalf("Failed to write .env file: %v", err)
+	}
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "",
+		"OPENROUTER_API_KEY": "",
+		"PORT":               "",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("LICHESS_TOKEN")
+	os.Unsetenv("OPENROUTER_API_KEY")
+	os.Unsetenv("PORT")
+
+	// Simulate the initial startup load
+	if err := loadEnvFromFile(envFile); err != nil {
+		t.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	initialCfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	watcher := NewConfigWatcher(envFile, initialCfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan *BotConfig, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Watch(ctx, func(cfg *BotConfig) {
+			select {
+			case changes <- cfg:
+			default:
+			}
+		})
+	}()
+
+	// Give the watcher time to register before modifying the file
+	time.Sleep(50 * time.Millisecond)
+	updated := "LICHESS_TOKEN=watch_token\nOPENROUTER_API_KEY=watch_key\nPORT=7001\n"
+	if err := os.WriteFile(envFile, []byte(updated), 0600); err != nil {
+		t.Fatalf("Failed to update .env file: %v", err)
+	}
+
+	select {
+	case cfg := <-changes:
+		if cfg.Port != "7001" {
+			t.Errorf("Expected reloaded Port '7001', got '%s'", cfg.Port)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected onChange to be called after modifying the .env file")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch() returned error: %v", err)
+	}
+}
+
+func TestConfigWatcher_SystemEnvTakesPrecedence(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("PORT=7000\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env file: %v", err)
+	}
+
+	cleanupEnv := setEnvVars(t, map[string]string{"PORT": "9999"})
+	defer cleanupEnv()
+
+	watcher := NewConfigWatcher(envFile, nil)
+	watcher.applyFileValues(map[string]string{"PORT": "7001"})
+
+	if port := os.Getenv("PORT"); port != "9999" {
+		t.Errorf("Expected system PORT '9999' to be kept, got '%s'", port)
+	}
+}
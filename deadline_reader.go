@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrStreamIdle is returned by DeadlineReader when the stream was silent for too long
+var ErrStreamIdle = errors.New("stream idle timeout exceeded")
+
+// DeadlineReader wraps a streaming response body and closes it when no data
+// (not even a keep-alive newline) arrives within the timeout. Each successful
+// read resets the deadline. This detects dead connections that were never closed.
+type DeadlineReader struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+
+	mu      sync.Mutex
+	expired bool
+}
+
+// NewDeadlineReader starts the idle deadline for body
+func NewDeadlineReader(body io.ReadCloser, timeout time.Duration) *DeadlineReader {
+	r := &DeadlineReader{body: body, timeout: timeout}
+	r.timer = time.AfterFunc(timeout, r.expire)
+	return r
+}
+
+func (r *DeadlineReader) expire() {
+	r.mu.Lock()
+	r.expired = true
+	r.mu.Unlock()
+	// Closing the body unblocks a pending Read
+	r.body.Close()
+}
+
+// Read reads from the underlying body, resetting the idle deadline on success
+func (r *DeadlineReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+
+	r.mu.Lock()
+	expired := r.expired
+	r.mu.Unlock()
+	if expired {
+		return n, ErrStreamIdle
+	}
+
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
+}
+
+// Close stops the deadline and closes the underlying body
+func (r *DeadlineReader) Close() error {
+	r.timer.Stop()
+	return r.body.Close()
+}
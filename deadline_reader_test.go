@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDeadlineReader_KeepAlivesResetDeadline(t *testing.T) {
+	pr, pw := io.Pipe()
+	reader := NewDeadlineReader(pr, 100*time.Millisecond)
+	defer reader.Close()
+
+	go func() {
+		// Keep-alive lines arrive more often than the timeout
+		for i := 0; i < 4; i++ {
+			time.Sleep(40 * time.Millisecond)
+			pw.Write([]byte("\n"))
+		}
+		pw.Write([]byte(`{"type":"gameState"}` + "\n"))
+		pw.Close()
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Expected stream to end cleanly, got %v", err)
+	}
+	if len(lines) != 5 || lines[4] != `{"type":"gameState"}` {
+		t.Errorf("Unexpected lines %q", lines)
+	}
+}
+
+func TestDeadlineReader_SilentStreamTimesOut(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	reader := NewDeadlineReader(pr, 50*time.Millisecond)
+	defer reader.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := reader.Read(make([]byte, 64))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrStreamIdle) {
+			t.Errorf("Expected ErrStreamIdle, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Read to be unblocked by the idle timeout")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// discordMinInterval keeps game summaries well inside Discord's webhook rate limits
+const discordMinInterval = 5 * time.Second
+
+// Embed colours for each outcome
+const (
+	discordColorWin  = 0x2ecc71
+	discordColorLoss = 0xe74c3c
+	discordColorDraw = 0x95a5a6
+)
+
+// GameSummary is the information posted to Discord after a game
+type GameSummary struct {
+	GameReport
+	TimeControl string   // e.g. "3+2"
+	BotColor    string   // "white" or "black"
+	Moves       []string // UCI moves, used to name the opening
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title  string              `json:"title"`
+	URL    string              `json:"url,omitempty"`
+	Color  int                 `json:"color"`
+	Fields []discordEmbedField `json:"fields"`
+}
+
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+// DiscordNotifier posts game summaries to a Discord webhook, at most one per discordMinInterval
+type DiscordNotifier struct {
+	URL    string
+	Client *http.Client
+
+	mu       sync.Mutex
+	lastSent time.Time
+	now      func() time.Time
+	sleep    func(time.Duration)
+}
+
+// NewDiscordNotifier creates a notifier for the given Discord webhook URL
+func NewDiscordNotifier(url string) *DiscordNotifier {
+	return &DiscordNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: defaultWebhookTimeout},
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// openingName names the opening from the first moves of a game, using the
+// longest matching line in studyOpenings
+func openingName(moves []string) string {
+	name, matched := "Unknown", 0
+	for _, opening := range studyOpenings {
+		if len(opening.Moves) <= matched || len(opening.Moves) > len(moves) {
+			continue
+		}
+		if strings.Join(moves[:len(opening.Moves)], " ") == strings.Join(opening.Moves, " ") {
+			name, matched = opening.Name, len(opening.Moves)
+		}
+	}
+	return name
+}
+
+// discordSummaryMessage builds the embed for a finished game
+func discordSummaryMessage(summary GameSummary) discordMessage {
+	color, result := discordColorDraw, "Draw"
+	switch summary.Outcome {
+	case OutcomeWin:
+		color, result = discordColorWin, "Win"
+	case OutcomeLoss:
+		color, result = discordColorLoss, "Loss"
+	}
+
+	embed := discordEmbed{
+		Title: fmt.Sprintf("%s vs %s", result, summary.Opponent),
+		URL:   summary.URL,
+		Color: color,
+		Fields: []discordEmbedField{
+			{Name: "Opponent", Value: summary.Opponent, Inline: true},
+			{Name: "Outcome", Value: result, Inline: true},
+			{Name: "Moves", Value: strconv.Itoa(summary.MoveCount), Inline: true},
+			{Name: "Time control", Value: summary.TimeControl, Inline: true},
+			{Name: "Bot color", Value: summary.BotColor, Inline: true},
+			{Name: "Opening", Value: openingName(summary.Moves), Inline: true},
+		},
+	}
+	// Discord rejects embeds with empty field values
+	fields := embed.Fields[:0]
+	for _, field := range embed.Fields {
+		if field.Value != "" {
+			fields = append(fields, field)
+		}
+	}
+	embed.Fields = fields
+	return discordMessage{Embeds: []discordEmbed{embed}}
+}
+
+// Post sends the summary, first waiting out the rest of discordMinInterval
+// if the previous message was sent less than that long ago
+func (d *DiscordNotifier) Post(summary GameSummary) error {
+	payload, err := json.Marshal(discordSummaryMessage(summary))
+	if err != nil {
+		return fmt.Errorf("failed to marshal Discord message: %v", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.lastSent.IsZero() {
+		if wait := discordMinInterval - d.now().Sub(d.lastSent); wait > 0 {
+			d.sleep(wait)
+		}
+	}
+	d.lastSent = d.now()
+
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Discord request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Discord request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Discord answers 204 No Content unless ?wait=true is set
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Discord returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpeningName(t *testing.T) {
+	tests := []struct {
+		moves    []string
+		expected string
+	}{
+		{[]string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5", "a7a6"}, "Ruy Lopez"},
+		{[]string{"e2e4", "c7c5", "g1f3"}, "Sicilian Defence"},
+		{[]string{"d2d4", "d7d5", "c2c4", "e7e6"}, "Queen's Gambit"},
+		{[]string{"e2e4", "e7e5", "g1f3"}, "Unknown"},
+		{nil, "Unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := openingName(tt.moves); got != tt.expected {
+			t.Errorf("Expected opening '%s' for %v, got '%s'", tt.expected, tt.moves, got)
+		}
+	}
+}
+
+func TestDiscordNotifier_Post(t *testing.T) {
+	var received []discordMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected Content-Type 'application/json', got '%s'", ct)
+		}
+		var msg discordMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("Failed to decode Discord message: %v", err)
+		}
+		received = append(received, msg)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clock := time.Unix(1700000000, 0)
+	var slept []time.Duration
+	notifier := NewDiscordNotifier(server.URL)
+	notifier.now = func() time.Time { return clock }
+	notifier.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+	}
+
+	summary := GameSummary{
+		GameReport: GameReport{
+			GameID:    "abcd1234",
+			Outcome:   OutcomeWin,
+			Opponent:  "alice",
+			MoveCount: 42,
+			URL:       "https://lichess.org/abcd1234",
+		},
+		TimeControl: "3+2",
+		BotColor:    "white",
+		Moves:       []string{"e2e4", "c7c5"},
+	}
+	if err := notifier.Post(summary); err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	clock = clock.Add(2 * time.Second)
+	summary.Outcome = OutcomeLoss
+	if err := notifier.Post(summary); err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+
+	if len(slept) != 1 || slept[0] != 3*time.Second {
+		t.Errorf("Expected one 3s wait between messages, got %v", slept)
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(received))
+	}
+
+	embed := received[0].Embeds[0]
+	if embed.Title != "Win vs alice" || embed.URL != "https://lichess.org/abcd1234" || embed.Color != discordColorWin {
+		t.Errorf("Unexpected embed %+v", embed)
+	}
+	fields := map[string]string{}
+	for _, f := range embed.Fields {
+		fields[f.Name] = f.Value
+	}
+	expected := map[string]string{
+		"Opponent":     "alice",
+		"Outcome":      "Win",
+		"Moves":        "42",
+		"Time control": "3+2",
+		"Bot color":    "white",
+		"Opening":      "Sicilian Defence",
+	}
+	for name, value := range expected {
+		if fields[name] != value {
+			t.Errorf("Expected field %s '%s', got '%s'", name, value, fields[name])
+		}
+	}
+	if received[1].Embeds[0].Color != discordColorLoss {
+		t.Errorf("Expected loss colour for the second message, got %#x", received[1].Embeds[0].Color)
+	}
+}
+
+func TestDiscordNotifier_PostError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	if err := NewDiscordNotifier(server.URL).Post(GameSummary{GameReport: GameReport{Opponent: "bob"}}); err == nil {
+		t.Error("Expected error for a rejected message, but got nil")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log"
+	"sync"
+)
+
+// drawOfferMaxEvalCP is the largest evaluation (in centipawns, either side) at which
+// the position still counts as equal enough to offer a draw
+const drawOfferMaxEvalCP = 30
+
+// DrawOfferTracker decides when to offer a draw in a game, at most once per game
+type DrawOfferTracker struct {
+	mu            sync.Mutex
+	afterNMoves   int
+	DrawOfferSent bool
+}
+
+// NewDrawOfferTracker creates a tracker offering a draw after afterNMoves moves (0 disables)
+func NewDrawOfferTracker(afterNMoves int) *DrawOfferTracker {
+	return &DrawOfferTracker{afterNMoves: afterNMoves}
+}
+
+// ShouldOffer reports whether the next move should carry a draw offer. evaluate is
+// only called once the move threshold is reached and should return the engine
+// evaluation in centipawns; a failed evaluation means no offer.
+func (d *DrawOfferTracker) ShouldOffer(moveCount int, evaluate func() (int, error)) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.afterNMoves <= 0 || d.DrawOfferSent || moveCount < d.afterNMoves {
+		return false
+	}
+
+	eval, err := evaluate()
+	if err != nil {
+		log.Printf("Not offering a draw, position evaluation failed: %v", err)
+		return false
+	}
+	if eval < -drawOfferMaxEvalCP || eval > drawOfferMaxEvalCP {
+		return false
+	}
+
+	d.DrawOfferSent = true
+	return true
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDrawOfferTracker(t *testing.T) {
+	evalCalls := 0
+	eval := func(cp int) func() (int, error) {
+		return func() (int, error) {
+			evalCalls++
+			return cp, nil
+		}
+	}
+
+	d := NewDrawOfferTracker(40)
+	if d.ShouldOffer(39, eval(0)) {
+		t.Error("Expected no offer before the move threshold")
+	}
+	if evalCalls != 0 {
+		t.Error("Expected no evaluation before the move threshold")
+	}
+	if d.ShouldOffer(40, eval(55)) {
+		t.Error("Expected no offer in an unbalanced position")
+	}
+	if d.ShouldOffer(41, eval(-31)) {
+		t.Error("Expected no offer when the bot is clearly worse or better")
+	}
+	if !d.ShouldOffer(42, eval(-30)) {
+		t.Error("Expected an offer in an equal position after the threshold")
+	}
+	if !d.DrawOfferSent {
+		t.Error("Expected DrawOfferSent to be set")
+	}
+	if d.ShouldOffer(43, eval(0)) {
+		t.Error("Expected only one draw offer per game")
+	}
+}
+
+func TestDrawOfferTracker_DisabledAndEvalFailure(t *testing.T) {
+	zero := func() (int, error) { return 0, nil }
+	if NewDrawOfferTracker(0).ShouldOffer(100, zero) {
+		t.Error("Expected no offer when disabled")
+	}
+
+	d := NewDrawOfferTracker(10)
+	if d.ShouldOffer(10, func() (int, error) { return 0, errors.New("engine missing") }) {
+		t.Error("Expected no offer when evaluation fails")
+	}
+	if !d.ShouldOffer(11, zero) {
+		t.Error("Expected a later offer once evaluation works")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	envImportDirective = "#IMPORT"
+	maxEnvImportDepth  = 3
+)
+
+// readEnvFileWithImports parses a .env file, resolving "#IMPORT other.env" directives.
+// Imported variables are applied at the position of the directive, so later lines in
+// the importing file override them. Relative import paths are resolved against the
+// directory of the importing file.
+func readEnvFileWithImports(path string) (map[string]string, error) {
+	vars := make(map[string]string)
+	if err := readEnvFileInto(path, vars, 0, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+func readEnvFileInto(path string, vars map[string]string, depth int, visiting map[string]bool) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %v", path, err)
+	}
+	if visiting[absPath] {
+		return fmt.Errorf("circular import of %s", path)
+	}
+	visiting[absPath] = true
+	defer delete(visiting, absPath)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if depth == 0 {
+			return err // keep os.IsNotExist working for the top-level file
+		}
+		return fmt.Errorf("failed to read imported file %s: %v", path, err)
+	}
+
+	// Parse the plain lines between import directives as separate chunks
+	var chunk strings.Builder
+	flush := func() error {
+		parsed, err := godotenv.Unmarshal(chunk.String())
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+		for k, v := range parsed {
+			vars[k] = v
+		}
+		chunk.Reset()
+		return nil
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, envImportDirective+" ") {
+			chunk.WriteString(line)
+			chunk.WriteString("\n")
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+
+		importPath := strings.TrimSpace(strings.TrimPrefix(trimmed, envImportDirective))
+		if depth >= maxEnvImportDepth {
+			return fmt.Errorf("import of %s from %s exceeds maximum import depth %d", importPath, path, maxEnvImportDepth)
+		}
+		if !filepath.IsAbs(importPath) {
+			importPath = filepath.Join(filepath.Dir(path), importPath)
+		}
+		if err := readEnvFileInto(importPath, vars, depth+1, visiting); err != nil {
+			return err
+		}
+	}
+
+	return flush()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeEnvFiles writes the given files into a temp dir and returns the dir
+func writeEnvFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestReadEnvFileWithImports_Chain(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{
+		".env":        "A=main\n#IMPORT common.env\nB=main_override\n",
+		"common.env":  "B=common\nC=common\n#IMPORT secrets.env\n",
+		"secrets.env": "D=secret\nA=secret\n",
+	})
+
+	vars, err := readEnvFileWithImports(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("readEnvFileWithImports() failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"A": "secret",        // set before the import, so the import overrides it
+		"B": "main_override", // set after the import, so it wins
+		"C": "common",
+		"D": "secret",
+	}
+	for key, val := range expected {
+		if vars[key] != val {
+			t.Errorf("Expected %s='%s', got '%s'", key, val, vars[key])
+		}
+	}
+}
+
+func TestReadEnvFileWithImports_Circular(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{
+		".env":  "#IMPORT a.env\n",
+		"a.env": "A=1\n#IMPORT b.env\n",
+		"b.env": "B=1\n#IMPORT a.env\n",
+	})
+
+	_, err := readEnvFileWithImports(filepath.Join(dir, ".env"))
+	if err == nil {
+		t.Fatal("Expected error for circular import, but got nil")
+	}
+	if !strings.Contains(err.Error(), "circular import") {
+		t.Errorf("Expected circular import error, got '%s'", err.Error())
+	}
+}
+
+func TestReadEnvFileWithImports_DepthLimit(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{
+		".env":  "#IMPORT 1.env\n",
+		"1.env": "#IMPORT 2.env\n",
+		"2.env": "#IMPORT 3.env\n",
+		"3.env": "#IMPORT 4.env\n",
+		"4.env": "TOO_DEEP=1\n",
+	})
+
+	_, err := readEnvFileWithImports(filepath.Join(dir, ".env"))
+	if err == nil {
+		t.Fatal("Expected error when exceeding the import depth, but got nil")
+	}
+	if !strings.Contains(err.Error(), "maximum import depth") {
+		t.Errorf("Expected depth limit error, got '%s'", err.Error())
+	}
+}
+
+func TestReadEnvFileWithImports_MissingImport(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{
+		".env": "A=1\n#IMPORT missing.env\n",
+	})
+
+	_, err := readEnvFileWithImports(filepath.Join(dir, ".env"))
+	if err == nil {
+		t.Fatal("Expected error for missing import, but got nil")
+	}
+	if os.IsNotExist(err) {
+		t.Error("Missing import must not be reported as a missing .env file")
+	}
+}
+
+func TestLoadConfig_EnvFileWithImport(t *testing.T) {
+	_, cleanupWD := createTempEnvFile(t, "#IMPORT secrets.env\nPORT=7171\n")
+	defer cleanupWD()
+	if err := os.WriteFile("secrets.env", []byte("LICHESS_TOKEN=imported_token\nOPENROUTER_API_KEY=imported_key\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secrets.env: %v", err)
+	}
+
+	cleanupEnv := setEnvVars(t, map[string]string{
+		"LICHESS_TOKEN":      "",
+		"OPENROUTER_API_KEY": "",
+		"PORT":               "",
+	})
+	defer cleanupEnv()
+	os.Unsetenv("LICHESS_TOKEN")
+	os.Unsetenv("OPENROUTER_API_KEY")
+	os.Unsetenv("PORT")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.LichessToken != "imported_token" || cfg.OpenRouterAPIKey != "imported_key" {
+		t.Errorf("Expected imported credentials, got token '%s' key '%s'", cfg.LichessToken, cfg.OpenRouterAPIKey)
+	}
+	if cfg.Port != "7171" {
+		t.Errorf("Expected Port '7171', got '%s'", cfg.Port)
+	}
+}
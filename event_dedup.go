@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+const defaultEventDedupTTL = 60 * time.Second
+
+// EventDeduper detects events that Lichess replays after a stream reconnect.
+// Events are keyed by a hash of their raw JSON line and forgotten after the TTL.
+type EventDeduper struct {
+	ttl  time.Duration
+	now  func() time.Time
+	seen sync.Map // [32]byte -> time.Time (first seen)
+}
+
+// NewEventDeduper creates a deduper that remembers events for ttl
+func NewEventDeduper(ttl time.Duration) *EventDeduper {
+	return &EventDeduper{ttl: ttl, now: time.Now}
+}
+
+// IsDuplicate records the event and reports whether the same event was already seen within the TTL
+func (d *EventDeduper) IsDuplicate(line []byte) bool {
+	key := sha256.Sum256(line)
+	now := d.now()
+
+	previous, loaded := d.seen.LoadOrStore(key, now)
+	if !loaded {
+		return false
+	}
+	if now.Sub(previous.(time.Time)) < d.ttl {
+		return true
+	}
+	// The earlier copy has expired, treat this one as new
+	d.seen.Store(key, now)
+	return false
+}
+
+// evictExpired removes entries older than the TTL
+func (d *EventDeduper) evictExpired() {
+	cutoff := d.now().Add(-d.ttl)
+	d.seen.Range(func(key, value interface{}) bool {
+		if value.(time.Time).Before(cutoff) {
+			d.seen.Delete(key)
+		}
+		return true
+	})
+}
+
+// StartEviction evicts expired entries every interval until ctx is cancelled
+func (d *EventDeduper) StartEviction(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.evictExpired()
+			}
+		}
+	}()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEventDeduper_SameEventStartsOneGame(t *testing.T) {
+	deduper := NewEventDeduper(defaultEventDedupTTL)
+	event := []byte(`{"type":"gameStart","game":{"gameId":"abcd1234","color":"white"}}`)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	started := make(map[string]int)
+
+	// Simulate the event loop receiving the same event twice (replay after reconnect)
+	for _, line := range [][]byte{event, event} {
+		if deduper.IsDuplicate(line) {
+			continue
+		}
+		var parsed struct {
+			Game struct {
+				GameID string `json:"gameId"`
+			} `json:"game"`
+		}
+		if err := json.Unmarshal(line, &parsed); err != nil {
+			t.Fatalf("Failed to parse event: %v", err)
+		}
+		wg.Add(1)
+		go func(gameID string) {
+			defer wg.Done()
+			mu.Lock()
+			started[gameID]++
+			mu.Unlock()
+		}(parsed.Game.GameID)
+	}
+	wg.Wait()
+
+	if started["abcd1234"] != 1 {
+		t.Errorf("Expected exactly one game goroutine, got %d", started["abcd1234"])
+	}
+}
+
+func TestEventDeduper_TTL(t *testing.T) {
+	current := time.Unix(1700000000, 0)
+	deduper := NewEventDeduper(time.Minute)
+	deduper.now = func() time.Time { return current }
+
+	event := []byte(`{"type":"challenge","challenge":{"id":"c1"}}`)
+	if deduper.IsDuplicate(event) {
+		t.Fatal("First occurrence must not be a duplicate")
+	}
+
+	current = current.Add(30 * time.Second)
+	if !deduper.IsDuplicate(event) {
+		t.Error("Expected replay within the TTL to be a duplicate")
+	}
+	if deduper.IsDuplicate([]byte(`{"type":"challenge","challenge":{"id":"c2"}}`)) {
+		t.Error("Different event must not be a duplicate")
+	}
+
+	current = current.Add(31 * time.Second)
+	if deduper.IsDuplicate(event) {
+		t.Error("Expected event to be new again after the TTL")
+	}
+}
+
+func TestEventDeduper_Eviction(t *testing.T) {
+	current := time.Unix(1700000000, 0)
+	var mu sync.Mutex
+	deduper := NewEventDeduper(time.Minute)
+	deduper.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	}
+
+	deduper.IsDuplicate([]byte(`{"type":"gameStart"}`))
+
+	mu.Lock()
+	current = current.Add(2 * time.Minute)
+	mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	deduper.StartEviction(ctx, 5*time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		empty := true
+		deduper.seen.Range(func(key, value interface{}) bool {
+			empty = false
+			return false
+		})
+		if empty {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("Expected expired entries to be evicted")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// Event priorities, highest first. Game events carry moves and clocks, so they
+// are handled before challenges that can safely wait a moment.
+const (
+	PriorityGame      = 2
+	PriorityChallenge = 1
+	PriorityOther     = 0
+)
+
+// eventPriority returns the priority of an event type
+func eventPriority(eventType string) int {
+	switch eventType {
+	case "gameFull", "gameState", EventGameStart, EventGameFinish:
+		return PriorityGame
+	case EventChallenge, EventChallengeCanceled, EventChallengeDeclined:
+		return PriorityChallenge
+	default:
+		return PriorityOther
+	}
+}
+
+type queuedEvent struct {
+	event    *StreamEvent
+	priority int
+	seq      uint64 // keeps events of equal priority in arrival order
+}
+
+// eventHeap implements heap.Interface, ordered by priority and then arrival
+type eventHeap []queuedEvent
+
+func (h eventHeap) Len() int { return len(h) }
+func (h eventHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h eventHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *eventHeap) Push(x any)   { *h = append(*h, x.(queuedEvent)) }
+func (h *eventHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// LichessEventQueue hands events to the dispatcher by priority, so game events
+// waiting behind a burst of challenges are still processed first
+type LichessEventQueue struct {
+	mu     sync.Mutex
+	events eventHeap
+	seq    uint64
+	ready  chan struct{}
+}
+
+// NewLichessEventQueue creates an empty queue
+func NewLichessEventQueue() *LichessEventQueue {
+	return &LichessEventQueue{ready: make(chan struct{}, 1)}
+}
+
+// Push adds an event
+func (q *LichessEventQueue) Push(event *StreamEvent) {
+	q.mu.Lock()
+	q.seq++
+	heap.Push(&q.events, queuedEvent{event: event, priority: eventPriority(event.Type), seq: q.seq})
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// TryPop returns the highest priority event without waiting
+func (q *LichessEventQueue) TryPop() (*StreamEvent, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.events.Len() == 0 {
+		return nil, false
+	}
+	return heap.Pop(&q.events).(queuedEvent).event, true
+}
+
+// Pop waits for an event and returns the one with the highest priority.
+// It returns ctx.Err() once ctx is cancelled.
+func (q *LichessEventQueue) Pop(ctx context.Context) (*StreamEvent, error) {
+	for {
+		if event, ok := q.TryPop(); ok {
+			return event, nil
+		}
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Len returns the number of queued events
+func (q *LichessEventQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.events.Len()
+}
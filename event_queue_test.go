@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLichessEventQueue_GameEventsFirst(t *testing.T) {
+	q := NewLichessEventQueue()
+	lines := []string{
+		`{"type":"challenge","challenge":{"id":"c1"}}`,
+		`{"type":"gameState","moves":"e2e4"}`,
+		`{"type":"challenge","challenge":{"id":"c2"}}`,
+		`{"type":"somethingNew"}`,
+		`{"type":"gameStart","game":{"gameId":"g1"}}`,
+		`{"type":"challengeCanceled","challenge":{"id":"c1"}}`,
+		`{"type":"gameState","moves":"e2e4 e7e5"}`,
+	}
+	for _, line := range lines {
+		event, err := ParseStreamEvent(line)
+		if err != nil {
+			t.Fatalf("ParseStreamEvent(%s) failed: %v", line, err)
+		}
+		q.Push(event)
+	}
+	if q.Len() != len(lines) {
+		t.Fatalf("Expected %d queued events, got %d", len(lines), q.Len())
+	}
+
+	// Game events first, then challenges, then the rest; arrival order within each level
+	expected := []string{
+		`{"type":"gameState","moves":"e2e4"}`,
+		`gameStart`,
+		`{"type":"gameState","moves":"e2e4 e7e5"}`,
+		`challenge c1`,
+		`challenge c2`,
+		`challengeCanceled c1`,
+		`{"type":"somethingNew"}`,
+	}
+	for i, want := range expected {
+		event, err := q.Pop(context.Background())
+		if err != nil {
+			t.Fatalf("Pop() failed: %v", err)
+		}
+		got := string(event.Data)
+		switch event.Type {
+		case EventChallenge, EventChallengeCanceled:
+			ce, _ := event.Challenge()
+			got = event.Type + " " + ce.Challenge.ID
+		case EventGameStart:
+			got = event.Type
+		}
+		if got != want {
+			t.Errorf("Event %d: expected %s, got %s", i, want, got)
+		}
+	}
+	if _, ok := q.TryPop(); ok {
+		t.Error("Expected the queue to be empty")
+	}
+}
+
+func TestLichessEventQueue_PopWaits(t *testing.T) {
+	q := NewLichessEventQueue()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Push(&StreamEvent{Type: "gameState"})
+	}()
+
+	event, err := q.Pop(context.Background())
+	if err != nil || event.Type != "gameState" {
+		t.Errorf("Expected the pushed event, got %+v (%v)", event, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Pop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded on an empty queue, got %v", err)
+	}
+}
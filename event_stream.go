@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// streamLichessEvents follows the bot's /api/stream/event stream until it fails for good
+// or ctx is done. Incoming challenges are handed to challenges and every gameStart event
+// starts playing the game.
+func streamLichessEvents(ctx context.Context, cfg *BotConfig, bot *Bot, challenges *ChallengeProcessor) error {
+	stream := openLichessStream(ctx, cfg, "/api/stream/event")
+	defer stream.Close()
+
+	scanner := newNDJSONScanner(stream)
+	for scanner.Scan() {
+		event, err := ParseStreamEvent(scanner.Text())
+		if err != nil {
+			log.Printf("Skipping event: %v", err)
+			continue
+		}
+		switch event.Type {
+		case EventChallenge:
+			ce, err := event.Challenge()
+			if err != nil {
+				log.Printf("Skipping challenge event: %v", err)
+				continue
+			}
+			// The bot's own challenges are announced on the stream as well
+			if strings.EqualFold(ce.Challenge.Challenger.ID, bot.botID) {
+				continue
+			}
+			log.Printf("Challenge %s from %s (%s, %s)", ce.Challenge.ID, ce.Challenge.Challenger.Name,
+				ce.Challenge.Variant, ce.Challenge.TimeControlString())
+			challenges.Submit(ce.Challenge)
+		case EventGameStart:
+			gs, err := event.GameStart()
+			if err != nil {
+				log.Printf("Skipping gameStart event: %v", err)
+				continue
+			}
+			if bot.StartGame(ctx, gs.GameID) {
+				log.Printf("Game %s started against %s", gs.GameID, gs.Opponent.Username)
+			}
+		default:
+			log.Printf("Received %s event", event.Type)
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("event stream failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStreamLichessEvents(t *testing.T) {
+	bot, mock := newTestBot(t)
+	bot.cfg.ChallengeAcceptProbability = 1
+	bot.cfg.GameMinClockSeconds = 60
+	pipeline := newChallengePipeline(bot.cfg, func() float64 { return 0 })
+	challenges := NewChallengeProcessor(2, func(challenge ChallengeData) {
+		if err := handleChallenge(bot.cfg, pipeline, challenge); err != nil {
+			t.Errorf("handleChallenge(%s) failed: %v", challenge.ID, err)
+		}
+	})
+
+	addChallenge := func(id, challenger string, limit int) {
+		mock.AddChallenge(map[string]interface{}{
+			"id":          id,
+			"challenger":  map[string]interface{}{"id": challenger, "name": challenger},
+			"timeControl": map[string]interface{}{"type": "clock", "limit": limit, "increment": 0},
+		})
+	}
+	addChallenge("blitz", "player", 180)
+	addChallenge("bullet", "player", 30)
+	addChallenge("outgoing", "mockbot", 180)
+	mock.InjectEvent(map[string]interface{}{"type": "gameStart", "game": map[string]interface{}{"gameId": "game1"}})
+	mock.InjectGameEvent("game1", testGameFull("game1", "white", ""))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- streamLichessEvents(ctx, bot.cfg, bot, challenges) }()
+
+	waitUntil(t, "the challenges to be answered", func() bool {
+		return len(mock.AcceptedChallenges()) == 1 && len(mock.DeclinedChallenges()) == 1
+	})
+	waitUntil(t, "the bot's first move", func() bool { return len(mock.Moves("game1")) == 1 })
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("streamLichessEvents() failed: %v", err)
+	}
+	challenges.Close()
+
+	if accepted := mock.AcceptedChallenges(); accepted[0] != "blitz" {
+		t.Errorf("Expected 'blitz' accepted, got %v", accepted)
+	}
+	if reason := mock.DeclinedChallenges()["bullet"]; reason != DeclineTooFast {
+		t.Errorf("Expected 'bullet' declined as '%s', got '%s'", DeclineTooFast, reason)
+	}
+}
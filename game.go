@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// startposFEN is the initialFen value Lichess sends for games from the standard position
+const startposFEN = "startpos"
+
+// GamePlayer is one side of a game as described in the gameFull event
+type GamePlayer struct {
+	ID     string
+	Name   string
+	Title  string
+	Rating int
+}
+
+// Game is the bot's view of a running game. It is created from the gameFull event
+// of the game stream and updated from every gameState event.
+type Game struct {
+	ID    string
+	Color string // "white" or "black", the side the bot plays
+	// InitialFEN is "startpos" or the FEN of the custom position the game started from
+	InitialFEN string
+	Speed      string
+	// TimeControl is "minutes+increment" such as "3+2" (empty for games without a clock)
+	TimeControl string
+	// ClockLimit and ClockIncrement are the clock in seconds (both 0 without a clock)
+	ClockLimit     int
+	ClockIncrement int
+	Rated          bool
+	Opponent       GamePlayer
+	BotRating      int
+	StartedAt      time.Time
+
+	whiteStarts bool
+	// timeScrambleMS is TIME_SCRAMBLE_THRESHOLD_MS (0 disables time scramble detection)
+	timeScrambleMS int
+
+	// personality is the PERSONALITY style for this game (empty for none)
+	personality string
+	// feedback is added to the move prompts (only set for /api/moves/suggest)
+	feedback string
+	// logger receives the game's messages instead of the global log when GAME_LOG_DIR is set
+	logger *slog.Logger
+
+	// PonderCache holds replies pondered while the opponent thinks (nil unless PONDER_MODE is set)
+	PonderCache *PonderCache
+
+	// chat picks the automatic replies to opponent chat (nil without CHAT_RESPONSE_MAP)
+	chat *ChatResponder
+	// drawOffer makes the DRAW_AFTER_N_MOVES offer once (nil when disabled)
+	drawOffer *DrawOfferTracker
+	// clockMonitor remembers the clock warnings already sent (nil without thresholds)
+	clockMonitor *ClockMonitor
+	// scripted holds the rest of TEST_MOVE_SEQUENCE (nil when none is set)
+	scripted *ScriptedMoves
+	// positions is the bot's PREWARM_PGN_FILE position cache (nil when unset)
+	positions *PositionCache
+	// batch is the bot's BatchLLMClient for move requests (nil to call OpenRouter directly)
+	batch *BatchLLMClient
+	// llmCalls counts the LLM calls made for the game across all moves (nil for no limit)
+	llmCalls *LLMCallBreaker
+
+	mu         sync.Mutex
+	moves      []string
+	wtime      int
+	btime      int
+	hasClock   bool
+	status     string
+	winner     string
+	lastMoveAt time.Time
+	// replies describes the LLM answers that chose the bot's moves, by ply
+	replies map[int]llmReply
+}
+
+// llmReply describes how the LLM arrived at one of the bot's moves
+type llmReply struct {
+	// Reasoning is the model's step-by-step analysis when THINK_BEFORE_MOVE is enabled
+	Reasoning string
+	// Attempts is the number of answers needed to get a legal move
+	Attempts int
+}
+
+// newGameFromFull builds a game from a gameFull event. botID is the bot's lowercase
+// Lichess ID, used to find out which side the bot plays.
+func newGameFromFull(event map[string]interface{}, botID string) (*Game, error) {
+	if event["type"] != "gameFull" {
+		return nil, fmt.Errorf("expected gameFull event, got %v", event["type"])
+	}
+	id, err := stringField(event, "id")
+	if err != nil || id == "" {
+		return nil, fmt.Errorf("gameFull event has no game id")
+	}
+
+	game := &Game{ID: id, StartedAt: time.Now()}
+	if game.InitialFEN, err = stringField(event, "initialFen"); err != nil {
+		return nil, err
+	}
+	if game.InitialFEN == "" {
+		game.InitialFEN = startposFEN
+	}
+	start, err := positionAfter(nil, game.InitialFEN)
+	if err != nil {
+		return nil, fmt.Errorf("game %s has an invalid initial FEN: %v", id, err)
+	}
+	game.whiteStarts = start.WhiteToMove
+
+	if game.Speed, err = stringField(event, "speed"); err != nil {
+		return nil, err
+	}
+	if game.Rated, err = boolField(event, "rated"); err != nil {
+		return nil, err
+	}
+	clock, err := objectField(event, "clock")
+	if err != nil {
+		return nil, err
+	}
+	if clock != nil {
+		initial, _ := intField(clock, "initial")
+		increment, _ := intField(clock, "increment")
+		game.ClockLimit, game.ClockIncrement = initial/1000, increment/1000
+		game.TimeControl = formatTimeControl(game.ClockLimit, game.ClockIncrement)
+	}
+
+	white, err := parseGamePlayer(event, "white")
+	if err != nil {
+		return nil, err
+	}
+	black, err := parseGamePlayer(event, "black")
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(botID) {
+	case white.ID:
+		game.Color, game.Opponent, game.BotRating = "white", black, white.Rating
+	case black.ID:
+		game.Color, game.Opponent, game.BotRating = "black", white, black.Rating
+	default:
+		return nil, fmt.Errorf("bot %s does not play in game %s", botID, id)
+	}
+
+	state, err := objectField(event, "state")
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("gameFull event for %s has no state", id)
+	}
+	if err := game.Update(state); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// parseGamePlayer reads the player object stored under key in a gameFull event.
+// AI opponents have no ID and are named after their level.
+func parseGamePlayer(event map[string]interface{}, key string) (GamePlayer, error) {
+	raw, err := objectField(event, key)
+	if err != nil || raw == nil {
+		return GamePlayer{}, fmt.Errorf("gameFull event has no %s player", key)
+	}
+	var p GamePlayer
+	if p.ID, err = stringField(raw, "id"); err != nil {
+		return p, err
+	}
+	if p.Name, err = stringField(raw, "name"); err != nil {
+		return p, err
+	}
+	if p.Title, err = stringField(raw, "title"); err != nil {
+		return p, err
+	}
+	if p.Rating, err = intField(raw, "rating"); err != nil {
+		return p, err
+	}
+	if level, err := intField(raw, "aiLevel"); err == nil && level > 0 && p.Name == "" {
+		p.Name = fmt.Sprintf("Stockfish level %d", level)
+	}
+	p.ID = strings.ToLower(p.ID)
+	return p, nil
+}
+
+// Update applies a gameState event (or the state of a gameFull event) to the game
+func (g *Game) Update(state map[string]interface{}) error {
+	if err := validateGameStateEvent(state); err != nil {
+		return err
+	}
+	moves := strings.Fields(state["moves"].(string))
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(moves) != len(g.moves) || g.lastMoveAt.IsZero() {
+		g.lastMoveAt = time.Now()
+	}
+	g.moves = moves
+	if wtime, ok := state["wtime"].(float64); ok {
+		g.wtime = int(wtime)
+		g.hasClock = true
+	}
+	if btime, ok := state["btime"].(float64); ok {
+		g.btime = int(btime)
+	}
+	g.status = state["status"].(string)
+	g.winner, _ = state["winner"].(string)
+	return nil
+}
+
+// callLLM sends a move request through the bot's batch client when there is one
+func (g *Game) callLLM(cfg *BotConfig, request openRouterRequest) (string, error) {
+	if g.batch == nil {
+		return callOpenRouter(cfg, request)
+	}
+	return g.batch.Call(request)
+}
+
+// logf logs a message about the game to its own log file, or to the global log
+func (g *Game) logf(format string, args ...any) {
+	if g.logger == nil {
+		log.Printf(format, args...)
+		return
+	}
+	g.logger.Info(fmt.Sprintf(format, args...))
+}
+
+func (g *Game) setLLMReply(ply int, reply llmReply) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.replies == nil {
+		g.replies = make(map[int]llmReply)
+	}
+	g.replies[ply] = reply
+}
+
+// LLMReply returns how the LLM chose the bot's move at ply (the number of moves
+// played before it). It is empty for moves chosen without asking the LLM.
+func (g *Game) LLMReply(ply int) llmReply {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.replies[ply]
+}
+
+// Moves returns a copy of the moves played so far, in UCI notation
+func (g *Game) Moves() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.moves...)
+}
+
+// MoveCount returns the number of moves (plies) played so far
+func (g *Game) MoveCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.moves)
+}
+
+// SideToMove returns "white" or "black"
+func (g *Game) SideToMove() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if (len(g.moves)%2 == 0) == g.whiteStarts {
+		return "white"
+	}
+	return "black"
+}
+
+// IsBotTurn reports whether the bot has to move in a running game
+func (g *Game) IsBotTurn() bool {
+	return !g.IsOver() && g.SideToMove() == g.Color
+}
+
+// HasClock reports whether the game's state events carry clock times
+func (g *Game) HasClock() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.hasClock
+}
+
+// BotClockMS returns the bot's remaining time in milliseconds
+func (g *Game) BotClockMS() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.Color == "white" {
+		return g.wtime
+	}
+	return g.btime
+}
+
+// IsTimeScramble reports whether remainingMs is below the game's TIME_SCRAMBLE_THRESHOLD_MS
+func (g *Game) IsTimeScramble(remainingMs int) bool {
+	return isTimeScramble(remainingMs, g.timeScrambleMS)
+}
+
+// OpponentClockMS returns the opponent's remaining time in milliseconds
+func (g *Game) OpponentClockMS() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.Color == "white" {
+		return g.btime
+	}
+	return g.wtime
+}
+
+// LastMoveAt returns when the move list last changed (the game start before the first move)
+func (g *Game) LastMoveAt() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastMoveAt
+}
+
+// Status returns the Lichess game status and the winner's color (empty unless decided)
+func (g *Game) Status() (status, winner string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status, g.winner
+}
+
+// Outcome returns the bot's result (OutcomeWin, OutcomeLoss or OutcomeDraw) from the
+// status and winner of the last state, or "" while the game runs or after an abort
+func (g *Game) Outcome() string {
+	status, winner := g.Status()
+	return gameOutcome(status, winner, g.Color)
+}
+
+// IsOver reports whether the game has finished or was aborted
+func (g *Game) IsOver() bool {
+	status, _ := g.Status()
+	return status != "created" && status != "started"
+}
@@ -0,0 +1,93 @@
+package main
+
+import "fmt"
+
+// knownGameStatuses are the game status values Lichess sends in gameState events
+var knownGameStatuses = map[string]bool{
+	"created":       true,
+	"started":       true,
+	"aborted":       true,
+	"mate":          true,
+	"resign":        true,
+	"stalemate":     true,
+	"timeout":       true,
+	"draw":          true,
+	"outoftime":     true,
+	"cheat":         true,
+	"noStart":       true,
+	"unknownFinish": true,
+	"variantEnd":    true,
+}
+
+// validateGameStateEvent checks that a gameState event has the shape the bot relies on,
+// so malformed events can be logged and skipped instead of corrupting game state
+func validateGameStateEvent(event map[string]interface{}) error {
+	moves, ok := event["moves"]
+	if !ok {
+		return fmt.Errorf("gameState event has no moves field")
+	}
+	if _, ok := moves.(string); !ok {
+		return fmt.Errorf("gameState moves must be a string, got %T", moves)
+	}
+
+	status, ok := event["status"].(string)
+	if !ok {
+		return fmt.Errorf("gameState event has no status string")
+	}
+	if !knownGameStatuses[status] {
+		return fmt.Errorf("gameState has unknown status '%s'", status)
+	}
+
+	if raw, present := event["winner"]; present {
+		winner, ok := raw.(string)
+		if !ok || (winner != "white" && winner != "black") {
+			return fmt.Errorf("gameState winner must be 'white' or 'black', got %v", raw)
+		}
+	}
+
+	for _, clock := range []string{"wtime", "btime"} {
+		raw, present := event[clock]
+		if !present {
+			continue
+		}
+		val, ok := raw.(float64)
+		if !ok {
+			return fmt.Errorf("gameState %s must be a number, got %T", clock, raw)
+		}
+		if val < 0 {
+			return fmt.Errorf("gameState %s must not be negative, got %v", clock, val)
+		}
+	}
+
+	return nil
+}
+
+// Game outcomes from the bot's point of view, as reported to the webhook
+const (
+	OutcomeWin  = "win"
+	OutcomeLoss = "loss"
+	OutcomeDraw = "draw"
+)
+
+// gameOutcome maps a finished game's status and winner ("white", "black" or empty)
+// to the bot's outcome. It returns "" for games that are still running or were
+// aborted before a result, and for decisive statuses without a winner.
+func gameOutcome(status, winner, botColor string) string {
+	switch status {
+	case "created", "started", "aborted":
+		return ""
+	case "stalemate", "draw":
+		return OutcomeDraw
+	}
+	// Timeouts with insufficient mating material are draws and carry no winner
+	if winner == "" {
+		if status == "outoftime" || status == "timeout" {
+			return OutcomeDraw
+		}
+		return ""
+	}
+	if winner == botColor {
+		return OutcomeWin
+	}
+	return OutcomeLoss
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateGameStateEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		wantErr bool
+	}{
+		{"valid in progress", `{"type":"gameState","moves":"e2e4 e7e5","wtime":60000,"btime":59000,"status":"started"}`, false},
+		{"valid empty moves", `{"type":"gameState","moves":"","status":"started"}`, false},
+		{"valid finished", `{"type":"gameState","moves":"f2f3 e7e5 g2g4 d8h4","status":"mate","winner":"black"}`, false},
+		{"zero clock", `{"type":"gameState","moves":"e2e4","wtime":0,"btime":1000,"status":"outoftime"}`, false},
+		{"missing moves", `{"type":"gameState","status":"started"}`, true},
+		{"moves not a string", `{"type":"gameState","moves":["e2e4"],"status":"started"}`, true},
+		{"null moves", `{"type":"gameState","moves":null,"status":"started"}`, true},
+		{"missing status", `{"type":"gameState","moves":"e2e4"}`, true},
+		{"status not a string", `{"type":"gameState","moves":"e2e4","status":20}`, true},
+		{"unknown status", `{"type":"gameState","moves":"e2e4","status":"exploded"}`, true},
+		{"negative wtime", `{"type":"gameState","moves":"e2e4","wtime":-5,"status":"started"}`, true},
+		{"bad winner", `{"type":"gameState","moves":"e2e4","status":"resign","winner":"red"}`, true},
+		{"btime not a number", `{"type":"gameState","moves":"e2e4","btime":"lots","status":"started"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.event), &event); err != nil {
+				t.Fatalf("Invalid test event JSON: %v", err)
+			}
+
+			err := validateGameStateEvent(event)
+			if tt.wantErr && err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGameOutcome(t *testing.T) {
+	tests := []struct {
+		status   string
+		winner   string
+		botColor string
+		expected string
+	}{
+		{"mate", "white", "white", OutcomeWin},
+		{"mate", "black", "white", OutcomeLoss},
+		{"resign", "black", "black", OutcomeWin},
+		{"resign", "white", "black", OutcomeLoss},
+		{"outoftime", "white", "black", OutcomeLoss},
+		{"outoftime", "", "white", OutcomeDraw},
+		{"timeout", "black", "black", OutcomeWin},
+		{"variantEnd", "white", "white", OutcomeWin},
+		{"cheat", "black", "white", OutcomeLoss},
+		{"stalemate", "", "white", OutcomeDraw},
+		{"draw", "", "black", OutcomeDraw},
+		{"started", "", "white", ""},
+		{"aborted", "", "white", ""},
+		{"noStart", "black", "white", OutcomeLoss},
+		{"unknownFinish", "", "white", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status+"_"+tt.winner+"_"+tt.botColor, func(t *testing.T) {
+			if got := gameOutcome(tt.status, tt.winner, tt.botColor); got != tt.expected {
+				t.Errorf("Expected outcome '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
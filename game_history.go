@@ -0,0 +1,260 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const gameHistoryCompressInterval = 24 * time.Hour
+
+// CompressGameHistory gzips .pgn files in dir last modified before olderThan,
+// replacing each with a .pgn.gz file. It returns the number of files compressed.
+func CompressGameHistory(dir string, olderThan time.Time) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read game history dir: %v", err)
+	}
+
+	compressed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pgn" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(olderThan) {
+			continue
+		}
+
+		if err := gzipFile(filepath.Join(dir, entry.Name())); err != nil {
+			log.Printf("Failed to compress game record %s: %v", entry.Name(), err)
+			continue
+		}
+		compressed++
+	}
+	return compressed, nil
+}
+
+// startGameHistoryCompressor compresses old game records once a day until ctx is cancelled
+func startGameHistoryCompressor(ctx context.Context, dir string, afterDays int) {
+	compress := func() {
+		n, err := CompressGameHistory(dir, time.Now().AddDate(0, 0, -afterDays))
+		if err != nil {
+			log.Printf("Game history compression failed: %v", err)
+			return
+		}
+		if n > 0 {
+			log.Printf("Compressed %d game records older than %d days", n, afterDays)
+		}
+	}
+
+	go func() {
+		compress()
+		ticker := time.NewTicker(gameHistoryCompressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				compress()
+			}
+		}
+	}()
+}
+
+// gzipReadCloser closes both the gzip reader and the file underneath it
+type gzipReadCloser struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// openGameRecord opens a .pgn or .pgn.gz game record, decompressing the latter transparently
+func openGameRecord(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to decompress %s: %v", path, err)
+	}
+	return &gzipReadCloser{Reader: gz, file: file}, nil
+}
+
+// GameHistory is the store of finished game records in GAME_PGN_DIR: a .pgn file per
+// game, or a .pgn.gz file once CompressGameHistory has compressed it
+type GameHistory struct {
+	dir string
+}
+
+// NewGameHistory creates a store reading the game records in dir
+func NewGameHistory(dir string) *GameHistory {
+	return &GameHistory{dir: dir}
+}
+
+// gameRecord is a stored game's file and the date from its PGN Date tag
+type gameRecord struct {
+	path string
+	date time.Time
+}
+
+// Records returns the stored games played on or after from (the zero time for all),
+// newest first and at most limit of them (0 for no limit). Games are dated by their
+// PGN Date tag, since compressing a record changes its file time.
+func (h *GameHistory) Records(from time.Time, limit int) ([]gameRecord, error) {
+	entries, err := os.ReadDir(h.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read game history dir: %v", err)
+	}
+
+	var records []gameRecord
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".pgn") || strings.HasSuffix(name, ".pgn.gz")) {
+			continue
+		}
+		path := filepath.Join(h.dir, name)
+		date, err := gameRecordDate(path)
+		if err != nil {
+			log.Printf("Skipping game record %s: %v", name, err)
+			continue
+		}
+		if !from.IsZero() && date.Before(from) {
+			continue
+		}
+		records = append(records, gameRecord{path: path, date: date})
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].date.Equal(records[j].date) {
+			return records[i].date.After(records[j].date)
+		}
+		return records[i].path < records[j].path
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// gameRecordDate reads the Date tag of a game record. Unknown dates ("????.??.??") are the zero time.
+func gameRecordDate(path string) (time.Time, error) {
+	r, err := openGameRecord(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer r.Close()
+	games, err := parsePGN(r)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(games) == 0 {
+		return time.Time{}, fmt.Errorf("no game in record")
+	}
+	date, err := time.Parse("2006.01.02", games[0].Tags["Date"])
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return date, nil
+}
+
+// parseExportFrom parses the from parameter of the game export, an ISO date
+// ("2026-10-01") or timestamp ("2026-10-01T12:00:00Z")
+func parseExportFrom(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	// PGN dates have no time of day, so a timestamp selects the games from its day on
+	ts, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("from must be an ISO date such as 2026-10-01")
+	}
+	return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// ServeHTTP implements GET /api/games/export?limit=N&from=<ISO date>, streaming the
+// stored games newest first as one PGN file with the games separated by blank lines
+func (h *GameHistory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	var from time.Time
+	if value := query.Get("from"); value != "" {
+		var err error
+		if from, err = parseExportFrom(value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := h.Records(from, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-chess-pgn")
+	flusher, _ := w.(http.Flusher)
+	for i, record := range records {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		if err := writeGameRecord(w, record.path); err != nil {
+			// The response has started, so all that is left is to stop
+			log.Printf("Game export stopped at %s: %v", record.path, err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// writeGameRecord copies a game record to w, ending it with a newline
+func writeGameRecord(w io.Writer, path string) error {
+	r, err := openGameRecord(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	content = []byte(strings.TrimRight(string(content), "\n") + "\n")
+	_, err = w.Write(content)
+	return err
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressGameHistory(t *testing.T) {
+	dir := t.TempDir()
+	oldPGN := "[Event \"Old game\"]\n\n1. e4 e5 1-0\n"
+	newPGN := "[Event \"New game\"]\n\n1. d4 d5 1/2-1/2\n"
+
+	oldPath := filepath.Join(dir, "old.pgn")
+	newPath := filepath.Join(dir, "new.pgn")
+	for path, content := range map[string]string{oldPath: oldPGN, newPath: newPGN, filepath.Join(dir, "notes.txt"): "x"} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	tenDaysAgo := time.Now().AddDate(0, 0, -10)
+	if err := os.Chtimes(oldPath, tenDaysAgo, tenDaysAgo); err != nil {
+		t.Fatalf("Failed to set file time: %v", err)
+	}
+
+	n, err := CompressGameHistory(dir, time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("CompressGameHistory() failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 file compressed, got %d", n)
+	}
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("Expected original old.pgn to be removed, got %v", err)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("Expected recent new.pgn to be kept: %v", err)
+	}
+
+	// Both compressed and plain records read back the same way
+	for path, expected := range map[string]string{oldPath + ".gz": oldPGN, newPath: newPGN} {
+		r, err := openGameRecord(path)
+		if err != nil {
+			t.Fatalf("openGameRecord(%s) failed: %v", path, err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if string(content) != expected {
+			t.Errorf("Unexpected content of %s: %q", path, content)
+		}
+	}
+}
+
+func TestGameHistory_Export(t *testing.T) {
+	dir := t.TempDir()
+	records := map[string]string{
+		"aaa.pgn": "[Event \"First\"]\n[Date \"2026.09.20\"]\n\n1. e4 e5 1-0\n",
+		"bbb.pgn": "[Event \"Second\"]\n[Date \"2026.10.02\"]\n\n1. d4 d5 1/2-1/2\n",
+		"ccc.pgn": "[Event \"Third\"]\n[Date \"2026.10.10\"]\n\n1. c4 e5 0-1\n",
+	}
+	for name, content := range records {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	// Compressed records are exported as well
+	if err := gzipFile(filepath.Join(dir, "bbb.pgn")); err != nil {
+		t.Fatalf("Failed to compress bbb.pgn: %v", err)
+	}
+	history := NewGameHistory(dir)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"all newest first", "", records["ccc.pgn"] + "\n" + records["bbb.pgn"] + "\n" + records["aaa.pgn"]},
+		{"limit", "?limit=1", records["ccc.pgn"]},
+		{"from date", "?from=2026-10-01", records["ccc.pgn"] + "\n" + records["bbb.pgn"]},
+		{"from timestamp", "?from=2026-10-02T15:00:00Z&limit=5", records["ccc.pgn"] + "\n" + records["bbb.pgn"]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			history.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/games/export"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/x-chess-pgn" {
+				t.Errorf("Unexpected Content-Type '%s'", ct)
+			}
+			if body := rec.Body.String(); body != tt.expected {
+				t.Errorf("Unexpected export:\n%s\nexpected:\n%s", body, tt.expected)
+			}
+			games, err := parsePGN(strings.NewReader(rec.Body.String()))
+			if err != nil || len(games) != strings.Count(tt.expected, "[Event ") {
+				t.Errorf("Expected the export to parse as %d games, got %d (%v)", strings.Count(tt.expected, "[Event "), len(games), err)
+			}
+		})
+	}
+
+	for _, query := range []string{"?limit=-1", "?limit=x", "?from=yesterday"} {
+		rec := httptest.NewRecorder()
+		history.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/games/export"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
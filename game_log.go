@@ -0,0 +1,82 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// GameLogger writes log messages for a single game to {dir}/{gameID}.log
+type GameLogger struct {
+	*slog.Logger
+	path string
+	file *os.File
+}
+
+// newGameLogger creates the log file for a game and a logger writing to it
+func newGameLogger(dir, gameID string) (*GameLogger, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create game log dir: %v", err)
+	}
+
+	path := filepath.Join(dir, gameID+".log")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open game log %s: %v", path, err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug})).
+		With("game_id", gameID)
+	return &GameLogger{Logger: logger, path: path, file: file}, nil
+}
+
+// Close closes the log file and compresses it to {gameID}.log.gz, since the game is over
+func (g *GameLogger) Close() error {
+	if err := g.file.Close(); err != nil {
+		return fmt.Errorf("failed to close game log %s: %v", g.path, err)
+	}
+	return gzipFile(g.path)
+}
+
+// gzipFile compresses path into path.gz and removes the original
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpPath := path + ".gz.tmp"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	gz.Name = filepath.Base(path)
+	_, copyErr := io.Copy(gz, src)
+	gzErr := gz.Close()
+	closeErr := dst.Close()
+	if err := firstError(copyErr, gzErr, closeErr); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to compress %s: %v", path, err)
+	}
+
+	if err := os.Rename(tmpPath, path+".gz"); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to compress %s: %v", path, err)
+	}
+	return os.Remove(path)
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
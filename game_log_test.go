@@ -0,0 +1,53 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGameLogger(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "games")
+
+	logger, err := newGameLogger(dir, "abcd1234")
+	if err != nil {
+		t.Fatalf("newGameLogger() failed: %v", err)
+	}
+
+	logPath := filepath.Join(dir, "abcd1234.log")
+	if _, err := os.Stat(logPath); err != nil {
+		t.Fatalf("Expected log file %s to be created: %v", logPath, err)
+	}
+
+	logger.Info("LLM query", "model", "openai/gpt-4o", "move", "e2e4")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Errorf("Expected uncompressed log to be removed after Close, got %v", err)
+	}
+
+	f, err := os.Open(logPath + ".gz")
+	if err != nil {
+		t.Fatalf("Expected compressed log file: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to open gzip reader: %v", err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to read compressed log: %v", err)
+	}
+
+	for _, expected := range []string{"game_id=abcd1234", `msg="LLM query"`, "move=e2e4"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected log to contain '%s', got: %s", expected, content)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// openLichessStream opens a reconnecting NDJSON stream such as /api/stream/event.
+// With KEEP_ALIVE_TIMEOUT_SECONDS set, a connection that stays completely silent that
+// long is dropped and reopened.
+func openLichessStream(ctx context.Context, cfg *BotConfig, path string) *ReconnectingReader {
+	connect := lichessStreamConnector(ctx, cfg, path)
+	if cfg.KeepAliveTimeoutSeconds > 0 {
+		timeout := time.Duration(cfg.KeepAliveTimeoutSeconds) * time.Second
+		plain := connect
+		connect = func() (io.ReadCloser, error) {
+			body, err := plain()
+			if err != nil {
+				return nil, err
+			}
+			return NewDeadlineReader(body, timeout), nil
+		}
+	}
+	return NewReconnectingReader(ctx, connect)
+}
+
+// streamGameEvents follows the game stream of gameID and passes every event to handle
+// until handle returns false, the stream fails for good or ctx is done. Events are logged
+// to logger, in full when it is enabled for debug messages.
+func streamGameEvents(ctx context.Context, cfg *BotConfig, gameID string, logger *slog.Logger, handle func(event map[string]interface{}) bool) error {
+	stream := openLichessStream(ctx, cfg, "/api/bot/game/stream/"+gameID)
+	defer stream.Close()
+
+	scanner := newNDJSONScanner(stream)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var event map[string]interface{}
+		if err := json.Unmarshal(line, &event); err != nil {
+			logger.Warn("skipping malformed game event", "error", err, "line", string(line))
+			continue
+		}
+		if logger.Enabled(ctx, slog.LevelDebug) {
+			logger.Debug("game event", "event", string(line))
+		} else {
+			logger.Info("game event", gameEventSummary(event)...)
+		}
+		if !handle(event) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("game stream %s failed: %v", gameID, err)
+	}
+	return nil
+}
+
+// gameEventLogger returns the structured logger for a game's events. The game named by
+// DEBUG_GAME_ID gets a debug-level logger marked with debug=true that logs every event
+// in full; all other games log a one-line summary per event at info level.
+func gameEventLogger(cfg *BotConfig, gameID string) *slog.Logger {
+	if cfg.IsDebugGame(gameID) {
+		handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+		return slog.New(handler).With("game_id", gameID, "debug", true)
+	}
+	return slog.With("game_id", gameID)
+}
+
+// gameEventSummary returns the log fields summarising a game stream event
+func gameEventSummary(event map[string]interface{}) []any {
+	state := event
+	if event["type"] == "gameFull" {
+		state, _ = event["state"].(map[string]interface{})
+	}
+	attrs := []any{"type", event["type"]}
+	if state == nil {
+		return attrs
+	}
+	if moves, ok := state["moves"].(string); ok {
+		attrs = append(attrs, "moves", len(strings.Fields(moves)))
+	}
+	if status, ok := state["status"].(string); ok {
+		attrs = append(attrs, "status", status)
+	}
+	return attrs
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestGameEventLogger_DebugGame(t *testing.T) {
+	cfg := &BotConfig{DebugGameID: "debug1"}
+	ctx := context.Background()
+	if !gameEventLogger(cfg, "debug1").Enabled(ctx, slog.LevelDebug) {
+		t.Error("Expected debug logging for the DEBUG_GAME_ID game")
+	}
+	if gameEventLogger(cfg, "other").Enabled(ctx, slog.LevelDebug) {
+		t.Error("Expected no debug logging for other games")
+	}
+	if gameEventLogger(&BotConfig{}, "").Enabled(ctx, slog.LevelDebug) {
+		t.Error("Expected no debug logging without DEBUG_GAME_ID")
+	}
+}
+
+func TestGameEventSummary(t *testing.T) {
+	tests := []struct {
+		event    map[string]interface{}
+		expected []any
+	}{
+		{
+			map[string]interface{}{"type": "gameState", "moves": "e2e4 e7e5", "status": "started", "wtime": 1000.0},
+			[]any{"type", "gameState", "moves", 2, "status", "started"},
+		},
+		{
+			map[string]interface{}{"type": "gameFull", "id": "g", "state": map[string]interface{}{"moves": "", "status": "created"}},
+			[]any{"type", "gameFull", "moves", 0, "status", "created"},
+		},
+		{
+			map[string]interface{}{"type": "chatLine", "username": "a", "text": "hi"},
+			[]any{"type", "chatLine"},
+		},
+	}
+	for _, tt := range tests {
+		if got := gameEventSummary(tt.event); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("gameEventSummary(%v): expected %v, got %v", tt.event, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gameFullEvent decodes a gameFull event the way it arrives on the game stream
+func gameFullEvent(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatalf("Invalid test event: %v", err)
+	}
+	return event
+}
+
+func TestNewGameFromFull(t *testing.T) {
+	event := gameFullEvent(t, `{"type":"gameFull","id":"abc123","speed":"blitz","rated":true,"initialFen":"startpos",
+		"clock":{"initial":180000,"increment":2000},
+		"white":{"id":"alice","name":"Alice","title":"FM","rating":2300},
+		"black":{"id":"mybot","name":"MyBot","title":"BOT","rating":1800},
+		"state":{"type":"gameState","moves":"e2e4 e7e5 g1f3","wtime":170000,"btime":175000,"status":"started"}}`)
+
+	game, err := newGameFromFull(event, "MyBot")
+	if err != nil {
+		t.Fatalf("newGameFromFull() failed: %v", err)
+	}
+	if game.ID != "abc123" || game.Color != "black" || game.InitialFEN != "startpos" || game.Speed != "blitz" || !game.Rated {
+		t.Errorf("Unexpected game fields: %+v", game)
+	}
+	if game.ClockLimit != 180 || game.ClockIncrement != 2 || game.TimeControl != "3+2" {
+		t.Errorf("Unexpected clock %d+%d (%s)", game.ClockLimit, game.ClockIncrement, game.TimeControl)
+	}
+	expectedOpponent := GamePlayer{ID: "alice", Name: "Alice", Title: "FM", Rating: 2300}
+	if game.Opponent != expectedOpponent {
+		t.Errorf("Expected opponent %+v, got %+v", expectedOpponent, game.Opponent)
+	}
+	if moves := game.Moves(); !reflect.DeepEqual(moves, []string{"e2e4", "e7e5", "g1f3"}) {
+		t.Errorf("Unexpected moves %v", moves)
+	}
+	if !game.IsBotTurn() {
+		t.Error("Expected black (the bot) to move after three moves")
+	}
+	if game.BotClockMS() != 175000 || game.OpponentClockMS() != 170000 {
+		t.Errorf("Unexpected clocks: bot %d, opponent %d", game.BotClockMS(), game.OpponentClockMS())
+	}
+}
+
+func TestNewGameFromFull_CustomInitialFEN(t *testing.T) {
+	// Black to move in the starting position, so black moves after an even number of moves
+	fen := "8/8/8/4k3/8/8/4P3/4K3 b - - 0 1"
+	event := gameFullEvent(t, `{"type":"gameFull","id":"endgame1","initialFen":"`+fen+`",
+		"white":{"id":"mybot","name":"MyBot"},"black":{"aiLevel":3},
+		"state":{"type":"gameState","moves":"","wtime":60000,"btime":60000,"status":"started"}}`)
+
+	game, err := newGameFromFull(event, "mybot")
+	if err != nil {
+		t.Fatalf("newGameFromFull() failed: %v", err)
+	}
+	if game.InitialFEN != fen {
+		t.Errorf("Expected initial FEN '%s', got '%s'", fen, game.InitialFEN)
+	}
+	if game.Opponent.Name != "Stockfish level 3" {
+		t.Errorf("Expected AI opponent name, got '%s'", game.Opponent.Name)
+	}
+	if game.SideToMove() != "black" || game.IsBotTurn() {
+		t.Errorf("Expected black to move first from '%s'", fen)
+	}
+
+	if err := game.Update(map[string]interface{}{"type": "gameState", "moves": "e5d4", "status": "started"}); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if !game.IsBotTurn() {
+		t.Error("Expected the bot (white) to move after black's first move")
+	}
+}
+
+func TestNewGameFromFull_Errors(t *testing.T) {
+	tests := map[string]string{
+		"not gameFull":   `{"type":"gameState","moves":"","status":"started"}`,
+		"missing id":     `{"type":"gameFull","white":{"id":"mybot"},"black":{"id":"x"},"state":{"moves":"","status":"started"}}`,
+		"bad FEN":        `{"type":"gameFull","id":"g","initialFen":"not a fen","white":{"id":"mybot"},"black":{"id":"x"},"state":{"moves":"","status":"started"}}`,
+		"bot not player": `{"type":"gameFull","id":"g","white":{"id":"a"},"black":{"id":"b"},"state":{"moves":"","status":"started"}}`,
+		"missing state":  `{"type":"gameFull","id":"g","white":{"id":"mybot"},"black":{"id":"x"}}`,
+		"invalid state":  `{"type":"gameFull","id":"g","white":{"id":"mybot"},"black":{"id":"x"},"state":{"moves":"","status":"bogus"}}`,
+	}
+	for name, raw := range tests {
+		if _, err := newGameFromFull(gameFullEvent(t, raw), "mybot"); err == nil {
+			t.Errorf("%s: expected an error, got nil", name)
+		}
+	}
+}
+
+func TestGame_IsOver(t *testing.T) {
+	event := gameFullEvent(t, `{"type":"gameFull","id":"g","white":{"id":"mybot"},"black":{"id":"x"},
+		"state":{"moves":"e2e4","status":"started"}}`)
+	game, err := newGameFromFull(event, "mybot")
+	if err != nil {
+		t.Fatalf("newGameFromFull() failed: %v", err)
+	}
+	if game.IsOver() {
+		t.Error("Expected a started game not to be over")
+	}
+
+	game.Update(map[string]interface{}{"moves": "e2e4", "status": "resign", "winner": "white"})
+	if !game.IsOver() || game.IsBotTurn() {
+		t.Error("Expected a resigned game to be over")
+	}
+	if status, winner := game.Status(); status != "resign" || winner != "white" {
+		t.Errorf("Expected status resign won by white, got %s/%s", status, winner)
+	}
+}
+
+func TestGame_Outcome(t *testing.T) {
+	tests := []struct {
+		state    string
+		expected string
+	}{
+		{`{"moves":"e2e4","status":"started"}`, ""},
+		{`{"moves":"","status":"aborted"}`, ""},
+		{`{"moves":"f2f3 e7e5 g2g4 d8h4","status":"mate","winner":"black"}`, OutcomeLoss},
+		{`{"moves":"e2e4","status":"resign","winner":"white"}`, OutcomeWin},
+		{`{"moves":"e2e4","status":"outoftime","winner":"black"}`, OutcomeLoss},
+		{`{"moves":"e2e4","status":"outoftime"}`, OutcomeDraw},
+		{`{"moves":"e2e4","status":"draw"}`, OutcomeDraw},
+		{`{"moves":"e2e4","status":"stalemate"}`, OutcomeDraw},
+	}
+	for _, tt := range tests {
+		game := &Game{Color: "white"}
+		if err := game.Update(gameFullEvent(t, tt.state)); err != nil {
+			t.Fatalf("Update(%s) failed: %v", tt.state, err)
+		}
+		if got := game.Outcome(); got != tt.expected {
+			t.Errorf("Outcome() after %s = '%s', expected '%s'", tt.state, got, tt.expected)
+		}
+	}
+}
+
+func TestGame_IsTimeScramble(t *testing.T) {
+	tests := []struct {
+		thresholdMs int
+		remainingMs int
+		expected    bool
+	}{
+		{10000, 9999, true},
+		{10000, 10000, false},
+		{10000, 60000, false},
+		{10000, 0, true},
+		{0, 500, false},
+	}
+	for _, tt := range tests {
+		game := &Game{timeScrambleMS: tt.thresholdMs}
+		if got := game.IsTimeScramble(tt.remainingMs); got != tt.expected {
+			t.Errorf("IsTimeScramble(%d) with threshold %d: expected %v, got %v", tt.remainingMs, tt.thresholdMs, tt.expected, got)
+		}
+	}
+}
+
+func TestGame_MoveCount(t *testing.T) {
+	game := &Game{Color: "white", whiteStarts: true}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		moves := ""
+		for _, move := range []string{"e2e4", "e7e5", "g1f3", "b8c6"} {
+			moves = strings.TrimSpace(moves + " " + move)
+			game.Update(map[string]interface{}{"moves": moves, "status": "started"})
+		}
+	}()
+	// Run with -race: reads concurrent with updates must not race
+	for i := 0; i < 100; i++ {
+		if n := game.MoveCount(); n < 0 || n > 4 {
+			t.Fatalf("Unexpected move count %d", n)
+		}
+	}
+	<-done
+	if n := game.MoveCount(); n != 4 {
+		t.Errorf("Expected 4 moves, got %d", n)
+	}
+}
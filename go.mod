@@ -2,4 +2,10 @@ module lichess-bot-agent
 
 go 1.24.3
 
-require github.com/joho/godotenv v1.5.1 // indirect
+require (
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/joho/godotenv v1.5.1
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+)
+
+require golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GameHandoverState is the state of an active game saved for another bot instance
+type GameHandoverState struct {
+	GameID  string              `json:"game_id"`
+	Color   string              `json:"color"`
+	Moves   []string            `json:"moves"`
+	History []openRouterMessage `json:"history,omitempty"`
+}
+
+// saveHandoverState writes the game state to {dir}/{gameID}.json
+func saveHandoverState(dir string, state GameHandoverState) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create handover dir: %v", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal handover state for game %s: %v", state.GameID, err)
+	}
+
+	// Write to a temp file first so a reader never sees a partial file
+	path := filepath.Join(dir, state.GameID+".json")
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write handover state for game %s: %v", state.GameID, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save handover state for game %s: %v", state.GameID, err)
+	}
+	return nil
+}
+
+// loadHandoverStates reads all saved game states from dir.
+// Files that cannot be parsed are logged and skipped.
+func loadHandoverStates(dir string) ([]GameHandoverState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read handover dir: %v", err)
+	}
+
+	var states []GameHandoverState
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			log.Printf("Warning: failed to read handover file %s: %v", entry.Name(), err)
+			continue
+		}
+
+		var state GameHandoverState
+		if err := json.Unmarshal(data, &state); err != nil || state.GameID == "" {
+			log.Printf("Warning: skipping invalid handover file %s", entry.Name())
+			continue
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// removeHandoverState deletes the saved state of a game once it has been resumed
+func removeHandoverState(dir, gameID string) error {
+	err := os.Remove(filepath.Join(dir, gameID+".json"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHandoverState_SaveAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "handover")
+
+	state := GameHandoverState{
+		GameID: "abcd1234",
+		Color:  "white",
+		Moves:  []string{"e2e4", "e7e5"},
+		History: []openRouterMessage{
+			{Role: "user", Content: "Your move"},
+			{Role: "assistant", Content: "g1f3"},
+		},
+	}
+	if err := saveHandoverState(dir, state); err != nil {
+		t.Fatalf("saveHandoverState() failed: %v", err)
+	}
+
+	// A corrupt file must not prevent loading the others
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatalf("Failed to write broken file: %v", err)
+	}
+
+	states, err := loadHandoverStates(dir)
+	if err != nil {
+		t.Fatalf("loadHandoverStates() failed: %v", err)
+	}
+	if len(states) != 1 || !reflect.DeepEqual(states[0], state) {
+		t.Fatalf("Expected [%+v], got %+v", state, states)
+	}
+
+	if err := removeHandoverState(dir, "abcd1234"); err != nil {
+		t.Fatalf("removeHandoverState() failed: %v", err)
+	}
+	states, err = loadHandoverStates(dir)
+	if err != nil {
+		t.Fatalf("loadHandoverStates() failed: %v", err)
+	}
+	if len(states) != 0 {
+		t.Errorf("Expected no states after removal, got %+v", states)
+	}
+}
+
+func TestLoadHandoverStates_MissingDir(t *testing.T) {
+	states, err := loadHandoverStates(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("Expected no error for missing dir, got %v", err)
+	}
+	if len(states) != 0 {
+		t.Errorf("Expected no states, got %+v", states)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+// healthcheckHTTPClient is used for pings to the external monitoring service
+var healthcheckHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// pingHealthcheck sends a GET request to url. Failures are only logged: a missed
+// ping is exactly what the monitoring service is there to notice.
+func pingHealthcheck(url string) {
+	resp, err := healthcheckHTTPClient.Get(url)
+	if err != nil {
+		log.Printf("Healthcheck ping failed: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Healthcheck ping to %s returned status %d", url, resp.StatusCode)
+	}
+}
+
+// startHealthcheckPinger pings url immediately and then every interval until ctx is cancelled
+func startHealthcheckPinger(ctx context.Context, url string, interval time.Duration) {
+	go func() {
+		pingHealthcheck(url)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pingHealthcheck(url)
+			}
+		}
+	}()
+}
+
+// reportHealthcheckFailure signals an abnormal shutdown via the {url}/fail endpoint.
+// It is meant to be deferred in main alongside recover().
+func reportHealthcheckFailure(url string) {
+	pingHealthcheck(url + "/fail")
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHealthcheckPinger(t *testing.T) {
+	var mu sync.Mutex
+	paths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	startHealthcheckPinger(ctx, server.URL+"/ping/abc", 20*time.Millisecond)
+	time.Sleep(70 * time.Millisecond)
+	cancel()
+
+	mu.Lock()
+	pings := len(paths)
+	mu.Unlock()
+	if pings < 2 {
+		t.Errorf("Expected at least 2 pings, got %d", pings)
+	}
+
+	reportHealthcheckFailure(server.URL + "/ping/abc")
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range paths {
+		if path == "/ping/abc/fail" {
+			return
+		}
+	}
+	t.Errorf("Expected failure ping to '/ping/abc/fail', got %v", paths)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// isLegalMove reports whether move is legal after moves from initialFEN
+// (the standard starting position when empty)
+func isLegalMove(moves []string, initialFEN, move string) bool {
+	pos, err := positionAfter(moves, initialFEN)
+	if err != nil {
+		return false
+	}
+	return pos.checkLegal(move) == nil
+}
+
+// requestLegalMove asks propose for a move up to cfg.MaxIllegalMoveRetries times until
+// it returns one that is legal after moves from initialFEN, completing a missing queen
+// promotion first. propose gets the attempt number (0 for the first try), which
+// newOpenRouterRequest uses to raise the temperature on retries.
+func requestLegalMove(cfg *BotConfig, moves []string, initialFEN string, propose func(attempt int) (string, error)) (string, error) {
+	var lastErr error
+	for attempt := 0; attempt < cfg.MaxIllegalMoveRetries; attempt++ {
+		move, err := propose(attempt)
+		if err != nil {
+			lastErr = err
+			log.Printf("Move request failed (attempt %d/%d): %v", attempt+1, cfg.MaxIllegalMoveRetries, err)
+			continue
+		}
+		move = autoAppendPromotion(moves, initialFEN, move)
+		if isLegalMove(moves, initialFEN, move) {
+			return move, nil
+		}
+		lastErr = fmt.Errorf("illegal move '%s'", move)
+		log.Printf("LLM proposed illegal move '%s' (attempt %d/%d)", move, attempt+1, cfg.MaxIllegalMoveRetries)
+	}
+	return "", fmt.Errorf("no legal move after %d attempts: %v", cfg.MaxIllegalMoveRetries, lastErr)
+}
+
+// randomLegalMove picks one of the legal moves after moves from initialFEN.
+// random returns a value in [0, n), normally rand.Intn.
+func randomLegalMove(moves []string, initialFEN string, random func(n int) int) (string, error) {
+	pos, err := positionAfter(moves, initialFEN)
+	if err != nil {
+		return "", err
+	}
+	legal := pos.legalMoves()
+	if len(legal) == 0 {
+		return "", fmt.Errorf("no legal moves in position %s", pos.FEN())
+	}
+	return legal[random(len(legal))], nil
+}
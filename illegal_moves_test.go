@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsLegalMove(t *testing.T) {
+	tests := []struct {
+		initialFEN string
+		moves      []string
+		move       string
+		expected   bool
+	}{
+		{"", nil, "e2e4", true},
+		{"", nil, "e2e5", false},
+		{"", nil, "e7e5", false},
+		{"", []string{"e2e4"}, "e7e5", true},
+		{"", []string{"e2e4", "e7e5"}, "e1e2", true},
+		{"", []string{"e2e4", "e7e5"}, "f1c4", true},
+		{"", []string{"e2e4", "e7e5"}, "f1b5", true},
+		{"", []string{"e2e4", "e7e5"}, "f1a6", true},
+		{"", []string{"e2e4", "e7e5"}, "f1a7", false},
+		{"", nil, "e1g1", false},
+		{"", nil, "g1f3q", false},
+		{"", nil, "xyz", false},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", nil, "e1g1", true},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", nil, "e2e4", false},
+		{"4k3/8/8/8/8/8/8/4K2R b - - 0 1", nil, "e8d8", true},
+	}
+
+	for _, tt := range tests {
+		if got := isLegalMove(tt.moves, tt.initialFEN, tt.move); got != tt.expected {
+			t.Errorf("isLegalMove(%q, %v, %s): expected %v, got %v", tt.initialFEN, tt.moves, tt.move, tt.expected, got)
+		}
+	}
+}
+
+func TestRequestLegalMove_StopsAtConfiguredAttempts(t *testing.T) {
+	for _, retries := range []int{1, 3, 5, 10} {
+		cfg := &BotConfig{MaxIllegalMoveRetries: retries}
+		var attempts []int
+		_, err := requestLegalMove(cfg, nil, "", func(attempt int) (string, error) {
+			attempts = append(attempts, attempt)
+			return "e2e5", nil
+		})
+		if err == nil {
+			t.Errorf("retries=%d: expected error when every move is illegal", retries)
+		}
+		if len(attempts) != retries {
+			t.Errorf("retries=%d: expected exactly %d attempts, got %d", retries, retries, len(attempts))
+		}
+		for i, attempt := range attempts {
+			if attempt != i {
+				t.Errorf("retries=%d: expected attempt numbers 0..%d, got %v", retries, retries-1, attempts)
+				break
+			}
+		}
+	}
+}
+
+func TestRequestLegalMove_RetriesUntilLegal(t *testing.T) {
+	cfg := &BotConfig{MaxIllegalMoveRetries: 3}
+	answers := []struct {
+		move string
+		err  error
+	}{
+		{"", errors.New("timeout")},
+		{"e2e5", nil},
+		{"e2e4", nil},
+	}
+	calls := 0
+	move, err := requestLegalMove(cfg, nil, "", func(attempt int) (string, error) {
+		calls++
+		return answers[attempt].move, answers[attempt].err
+	})
+	if err != nil || move != "e2e4" {
+		t.Errorf("Expected 'e2e4' on the third attempt, got '%s' (%v)", move, err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRequestLegalMove_AppendsPromotion(t *testing.T) {
+	cfg := &BotConfig{MaxIllegalMoveRetries: 1}
+	move, err := requestLegalMove(cfg, nil, "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", func(attempt int) (string, error) {
+		return "e7e8", nil
+	})
+	if err != nil || move != "e7e8q" {
+		t.Errorf("Expected 'e7e8q', got '%s' (%v)", move, err)
+	}
+}
+
+func TestRandomLegalMove(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		move, err := randomLegalMove([]string{"e2e4"}, "", func(n int) int { return i % n })
+		if err != nil {
+			t.Fatalf("randomLegalMove() failed: %v", err)
+		}
+		if !isLegalMove([]string{"e2e4"}, "", move) {
+			t.Errorf("randomLegalMove() returned illegal move %s", move)
+		}
+	}
+
+	mate := "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3"
+	if _, err := randomLegalMove(nil, mate, func(n int) int { return 0 }); err == nil {
+		t.Error("Expected an error when there is no legal move")
+	}
+}
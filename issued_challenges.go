@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// issuedChallengeTTL is how long an unanswered outgoing challenge is tracked
+const issuedChallengeTTL = 60 * time.Second
+
+// IssuedChallenge is a challenge the bot sent to another player
+type IssuedChallenge struct {
+	ID             string    `json:"id"`
+	Opponent       string    `json:"opponent"`
+	Rated          bool      `json:"rated"`
+	ClockLimit     int       `json:"clock_limit"`
+	ClockIncrement int       `json:"clock_increment"`
+	TimeControl    string    `json:"time_control"` // "minutes+increment", filled in by PendingChallenges.Add
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PendingChallenges tracks outgoing challenges until they are answered or expire
+type PendingChallenges struct {
+	mu                sync.Mutex
+	pendingChallenges map[string]IssuedChallenge
+	ttl               time.Duration
+	now               func() time.Time
+}
+
+// NewPendingChallenges creates an empty tracker
+func NewPendingChallenges() *PendingChallenges {
+	return &PendingChallenges{
+		pendingChallenges: make(map[string]IssuedChallenge),
+		ttl:               issuedChallengeTTL,
+		now:               time.Now,
+	}
+}
+
+// Add starts tracking an issued challenge, stamping it with the current time
+func (p *PendingChallenges) Add(c IssuedChallenge) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c.CreatedAt = p.now()
+	c.TimeControl = formatTimeControl(c.ClockLimit, c.ClockIncrement)
+	p.pendingChallenges[c.ID] = c
+}
+
+// Remove stops tracking a challenge, e.g. once it was accepted or declined
+func (p *PendingChallenges) Remove(challengeID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pendingChallenges, challengeID)
+}
+
+// HandleEvent removes challenges answered according to an event stream event
+func (p *PendingChallenges) HandleEvent(event *StreamEvent) {
+	switch event.Type {
+	case EventChallengeDeclined, EventChallengeCanceled:
+		if ce, err := event.Challenge(); err == nil {
+			p.Remove(ce.Challenge.ID)
+		}
+	}
+}
+
+// List drops expired challenges and returns the remaining ones, oldest first
+func (p *PendingChallenges) List() []IssuedChallenge {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cutoff := p.now().Add(-p.ttl)
+	list := make([]IssuedChallenge, 0, len(p.pendingChallenges))
+	for id, c := range p.pendingChallenges {
+		if c.CreatedAt.Before(cutoff) {
+			delete(p.pendingChallenges, id)
+			continue
+		}
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// ServeHTTP implements GET /api/challenges/pending
+func (p *PendingChallenges) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.List())
+}
+
+// createChallenge challenges username to a game with the given clock (in seconds)
+// and returns the ID of the new challenge
+func createChallenge(cfg *BotConfig, username string, clockLimit, clockIncrement int, rated bool) (string, error) {
+	return postChallenge(cfg, username, challengeForm(clockLimit, clockIncrement, rated))
+}
+
+// challengeForm holds the clock and rating parameters shared by all challenges
+func challengeForm(clockLimit, clockIncrement int, rated bool) url.Values {
+	return url.Values{
+		"clock.limit":     {strconv.Itoa(clockLimit)},
+		"clock.increment": {strconv.Itoa(clockIncrement)},
+		"rated":           {strconv.FormatBool(rated)},
+	}
+}
+
+// postChallenge sends a challenge request with the given form parameters
+func postChallenge(cfg *BotConfig, username string, form url.Values) (string, error) {
+	req, err := newLichessRequest(cfg, http.MethodPost, "/api/challenge/"+url.PathEscape(username),
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to challenge %s: %v", username, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("challenge to %s rejected with status %d: %s", username, resp.StatusCode, body)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode challenge to %s: %v", username, err)
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("no challenge ID returned for challenge to %s", username)
+	}
+	return created.ID, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPendingChallenges_Expiry(t *testing.T) {
+	now := time.Now()
+	p := NewPendingChallenges()
+	p.now = func() time.Time { return now }
+
+	p.Add(IssuedChallenge{ID: "old", Opponent: "alice"})
+	now = now.Add(30 * time.Second)
+	p.Add(IssuedChallenge{ID: "new", Opponent: "bob"})
+
+	if list := p.List(); len(list) != 2 || list[0].ID != "old" {
+		t.Fatalf("Expected both challenges oldest first, got %+v", list)
+	}
+
+	now = now.Add(issuedChallengeTTL - 20*time.Second)
+	list := p.List()
+	if len(list) != 1 || list[0].ID != "new" {
+		t.Errorf("Expected only 'new' after 'old' expired, got %+v", list)
+	}
+
+	now = now.Add(issuedChallengeTTL)
+	if list := p.List(); len(list) != 0 {
+		t.Errorf("Expected all challenges expired, got %+v", list)
+	}
+}
+
+func TestPendingChallenges_HandleEvent(t *testing.T) {
+	p := NewPendingChallenges()
+	p.Add(IssuedChallenge{ID: "c1"})
+	p.Add(IssuedChallenge{ID: "c2"})
+
+	for _, line := range []string{
+		`{"type":"challengeDeclined","challenge":{"id":"c1","declineReason":"I'm not accepting challenges at the moment."}}`,
+		`{"type":"gameFinish","game":{"gameId":"c2"}}`,
+	} {
+		event, err := ParseStreamEvent(line)
+		if err != nil {
+			t.Fatalf("ParseStreamEvent() failed: %v", err)
+		}
+		p.HandleEvent(event)
+	}
+
+	list := p.List()
+	if len(list) != 1 || list[0].ID != "c2" {
+		t.Errorf("Expected only 'c2' to remain, got %+v", list)
+	}
+}
+
+func TestPendingChallenges_ServeHTTP(t *testing.T) {
+	p := NewPendingChallenges()
+	p.Add(IssuedChallenge{ID: "c1", Opponent: "alice", ClockLimit: 180, ClockIncrement: 2})
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/challenges/pending", nil))
+
+	var list []IssuedChallenge
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode response %s: %v", rec.Body.String(), err)
+	}
+	if len(list) != 1 || list[0].Opponent != "alice" || list[0].ClockLimit != 180 || list[0].TimeControl != "3+2" {
+		t.Errorf("Unexpected response %+v", list)
+	}
+
+	rec = httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/challenges/pending", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for POST, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestCreateChallenge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/challenge/alice" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		r.ParseForm()
+		if r.Form.Get("clock.limit") != "300" || r.Form.Get("clock.increment") != "3" || r.Form.Get("rated") != "false" {
+			t.Errorf("Unexpected form %v", r.Form)
+		}
+		w.Write([]byte(`{"id":"NEWCHAL1","status":"created"}`))
+	}))
+	defer server.Close()
+
+	id, err := createChallenge(newTestLichessConfig(server.URL), "alice", 300, 3, false)
+	if err != nil {
+		t.Fatalf("createChallenge() failed: %v", err)
+	}
+	if id != "NEWCHAL1" {
+		t.Errorf("Expected challenge ID 'NEWCHAL1', got '%s'", id)
+	}
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// lichessHTTPClient is used for regular (non-streaming) Lichess API calls
+var lichessHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// lichessStreamClient has no timeout, for long-lived streaming responses
+var lichessStreamClient = &http.Client{}
+
+// newLichessRequest creates an authenticated request to the Lichess API
+func newLichessRequest(cfg *BotConfig, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, cfg.LichessBaseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.LichessToken)
+	return req, nil
+}
+
+// makeMove submits a move in UCI notation, optionally offering a draw with it
+func makeMove(cfg *BotConfig, gameID, move string, offerDraw bool) error {
+	path := fmt.Sprintf("/api/bot/game/%s/move/%s", url.PathEscape(gameID), url.PathEscape(move))
+	if offerDraw {
+		path += "?offeringDraw=true"
+	}
+
+	req, err := newLichessRequest(cfg, http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to submit move %s in game %s: %v", move, gameID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("move %s in game %s rejected with status %d: %s", move, gameID, resp.StatusCode, body)
+	}
+
+	gameRate := botMetrics.RecordMove(gameID)
+	if cfg.MaxMovesPerMinute > 0 && gameRate > float64(cfg.MaxMovesPerMinute) {
+		log.Printf("ALERT: game %s is playing %.0f moves per minute (limit %d), possible runaway loop",
+			gameID, gameRate, cfg.MaxMovesPerMinute)
+	}
+	return nil
+}
+
+// submitMove logs the move in SAN and submits it with makeMove. moves are the game's
+// moves so far from initialFEN, used only to describe the move.
+func submitMove(cfg *BotConfig, gameID string, moves []string, initialFEN, move string, offerDraw bool) error {
+	log.Printf("Submitting move %s in game %s", describeMove(moves, initialFEN, move), gameID)
+	return makeMove(cfg, gameID, move, offerDraw)
+}
+
+// abortGame aborts a game, which Lichess only allows before both players have moved
+func abortGame(cfg *BotConfig, gameID string) error {
+	req, err := newLichessRequest(cfg, http.MethodPost, fmt.Sprintf("/api/bot/game/%s/abort", url.PathEscape(gameID)), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to abort game %s: %v", gameID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("abort of game %s rejected with status %d: %s", gameID, resp.StatusCode, body)
+	}
+	log.Printf("Aborted game %s", gameID)
+	return nil
+}
+
+// resignGame resigns a game the bot is playing
+func resignGame(cfg *BotConfig, gameID string) error {
+	req, err := newLichessRequest(cfg, http.MethodPost, fmt.Sprintf("/api/bot/game/%s/resign", url.PathEscape(gameID)), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to resign game %s: %v", gameID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("resignation of game %s rejected with status %d: %s", gameID, resp.StatusCode, body)
+	}
+	log.Printf("Resigned game %s", gameID)
+	return nil
+}
+
+// getLichessGameStatus fetches the current status of a game ("started", "mate", "resign", ...)
+// from the game export API, e.g. to decide whether a dropped stream is worth reconnecting
+func getLichessGameStatus(cfg *BotConfig, gameID string) (string, error) {
+	path := fmt.Sprintf("/game/export/%s?literate=false&evals=false&moves=false", url.PathEscape(gameID))
+	req, err := newLichessRequest(cfg, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch status of game %s: %v", gameID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("fetching status of game %s failed with status %d: %s", gameID, resp.StatusCode, body)
+	}
+
+	var game struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&game); err != nil {
+		return "", fmt.Errorf("failed to decode status of game %s: %v", gameID, err)
+	}
+	if game.Status == "" {
+		return "", fmt.Errorf("no status returned for game %s", gameID)
+	}
+	return game.Status, nil
+}
+
+// getPendingChallenges returns up to limit incoming challenges that are still waiting
+// for an answer, oldest first as returned by Lichess (limit <= 0 means no limit)
+func getPendingChallenges(cfg *BotConfig, limit int) ([]map[string]interface{}, error) {
+	req, err := newLichessRequest(cfg, http.MethodGet, "/api/challenge", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending challenges: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("fetching pending challenges failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var challenges struct {
+		In []map[string]interface{} `json:"in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&challenges); err != nil {
+		return nil, fmt.Errorf("failed to decode pending challenges: %v", err)
+	}
+
+	if limit > 0 && len(challenges.In) > limit {
+		log.Printf("Found %d pending challenges, only processing the first %d", len(challenges.In), limit)
+		return challenges.In[:limit], nil
+	}
+	return challenges.In, nil
+}
+
+// acceptChallenge accepts an incoming challenge. Lichess then starts the game and
+// announces it with a gameStart event.
+func acceptChallenge(cfg *BotConfig, challengeID string) error {
+	req, err := newLichessRequest(cfg, http.MethodPost, fmt.Sprintf("/api/challenge/%s/accept", url.PathEscape(challengeID)), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to accept challenge %s: %v", challengeID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("acceptance of challenge %s rejected with status %d: %s", challengeID, resp.StatusCode, body)
+	}
+	log.Printf("Accepted challenge %s", challengeID)
+	return nil
+}
+
+// declineChallenge declines an incoming challenge with one of the Decline* reasons,
+// which Lichess shows to the challenger
+func declineChallenge(cfg *BotConfig, challengeID, reason string) error {
+	form := url.Values{"reason": {reason}}
+	req, err := newLichessRequest(cfg, http.MethodPost, fmt.Sprintf("/api/challenge/%s/decline", url.PathEscape(challengeID)),
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to decline challenge %s: %v", challengeID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("decline of challenge %s rejected with status %d: %s", challengeID, resp.StatusCode, body)
+	}
+	log.Printf("Declined challenge %s (%s)", challengeID, reason)
+	return nil
+}
+
+// sendChatMessage posts a message to the "player" or "spectator" chat room of a game
+func sendChatMessage(cfg *BotConfig, gameID, room, text string) error {
+	form := url.Values{"room": {room}, "text": {text}}
+	req, err := newLichessRequest(cfg, http.MethodPost, fmt.Sprintf("/api/bot/game/%s/chat", url.PathEscape(gameID)),
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to send chat message in game %s: %v", gameID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("chat message in game %s rejected with status %d: %s", gameID, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// BotAccount holds the account details of the bot user
+type BotAccount struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Title    string `json:"title"`
+}
+
+// getBotAccountDetails fetches the account behind the token and checks it is a bot account,
+// since the bot API rejects regular accounts with a less helpful 403 later on
+func getBotAccountDetails(cfg *BotConfig) (*BotAccount, error) {
+	req, err := newLichessRequest(cfg, http.MethodGet, "/api/account", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account details: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("fetching account details failed with status %d: %s", resp.StatusCode, body)
+		// A bad token will not get better by retrying
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, Permanent(err)
+		}
+		return nil, err
+	}
+
+	var account BotAccount
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return nil, fmt.Errorf("failed to decode account details: %v", err)
+	}
+
+	if account.Title != "BOT" {
+		return nil, Permanent(fmt.Errorf("account %s is not a bot account. Upgrade it (this cannot be undone, "+
+			"and the account must not have played any games) with:\n"+
+			"  curl -d '' %s/api/bot/account/upgrade -H \"Authorization: Bearer $LICHESS_TOKEN\"",
+			account.Username, cfg.LichessBaseURL))
+	}
+	return &account, nil
+}
+
+// requiredTokenScopes are the OAuth scopes the bot cannot work without
+var requiredTokenScopes = []string{"bot:play"}
+
+// validateTokenScopes checks that the token was granted every required scope, so a
+// wrongly created token fails at startup instead of with 401s in the middle of a game
+func validateTokenScopes(cfg *BotConfig) error {
+	req, err := newLichessRequest(cfg, http.MethodPost, "/api/token/test", strings.NewReader(cfg.LichessToken))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to check token scopes: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("checking token scopes failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	// The response maps each tested token to its details, or to null if it is invalid
+	var tokens map[string]*struct {
+		Scopes string `json:"scopes"`
+		UserID string `json:"userId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return fmt.Errorf("failed to decode token scopes: %v", err)
+	}
+	info := tokens[cfg.LichessToken]
+	if info == nil {
+		return fmt.Errorf("LICHESS_TOKEN is invalid or has expired")
+	}
+
+	granted := map[string]bool{}
+	for _, scope := range strings.Split(info.Scopes, ",") {
+		granted[strings.TrimSpace(scope)] = true
+	}
+	var missing []string
+	for _, scope := range requiredTokenScopes {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("LICHESS_TOKEN for %s is missing required scopes: %s. Create a new token at "+
+			"%s/account/oauth/token with these scopes enabled", info.UserID, strings.Join(missing, ", "), cfg.LichessBaseURL)
+	}
+	return nil
+}
+
+// ongoingGamesTimeout bounds the startup reconciliation so a slow Lichess does not delay the event stream
+const ongoingGamesTimeout = 10 * time.Second
+
+// OngoingGame is a game in progress as listed by /api/account/playing
+type OngoingGame struct {
+	GameID   string `json:"gameId"`
+	Color    string `json:"color"`
+	FEN      string `json:"fen"`
+	LastMove string `json:"lastMove"`
+	IsMyTurn bool   `json:"isMyTurn"`
+	Opponent struct {
+		Username string `json:"username"`
+	} `json:"opponent"`
+}
+
+// getLichessOngoingGames lists the games the bot is currently playing
+func getLichessOngoingGames(ctx context.Context, cfg *BotConfig) ([]OngoingGame, error) {
+	req, err := newLichessRequest(cfg, http.MethodGet, "/api/account/playing", nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ongoing games: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("fetching ongoing games failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var playing struct {
+		NowPlaying []OngoingGame `json:"nowPlaying"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&playing); err != nil {
+		return nil, fmt.Errorf("failed to decode ongoing games: %v", err)
+	}
+	return playing.NowPlaying, nil
+}
+
+// reconcileOngoingGames resumes games left running by a previous bot process.
+// resume is called for every ongoing game isActive does not already know about;
+// it returns the number of games resumed.
+func reconcileOngoingGames(cfg *BotConfig, isActive func(gameID string) bool, resume func(OngoingGame)) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ongoingGamesTimeout)
+	defer cancel()
+
+	games, err := getLichessOngoingGames(ctx, cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	resumed := 0
+	for _, game := range games {
+		if isActive(game.GameID) {
+			continue
+		}
+		log.Printf("Resuming ongoing game %s against %s", game.GameID, game.Opponent.Username)
+		resume(game)
+		resumed++
+	}
+	return resumed, nil
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"lichess-bot-agent/lichessmock"
+)
+
+func newTestLichessConfig(baseURL string) *BotConfig {
+	return &BotConfig{LichessToken: "test_token", LichessBaseURL: baseURL}
+}
+
+func TestMakeMove(t *testing.T) {
+	tests := []struct {
+		name          string
+		offerDraw     bool
+		expectedQuery string
+	}{
+		{"without draw offer", false, ""},
+		{"with draw offer", true, "offeringDraw=true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("Expected POST, got %s", r.Method)
+				}
+				if r.URL.Path != "/api/bot/game/abcd1234/move/e2e4" {
+					t.Errorf("Unexpected path '%s'", r.URL.Path)
+				}
+				if r.URL.RawQuery != tt.expectedQuery {
+					t.Errorf("Expected query '%s', got '%s'", tt.expectedQuery, r.URL.RawQuery)
+				}
+				if auth := r.Header.Get("Authorization"); auth != "Bearer test_token" {
+					t.Errorf("Unexpected Authorization header '%s'", auth)
+				}
+				w.Write([]byte(`{"ok":true}`))
+			}))
+			defer server.Close()
+
+			if err := makeMove(newTestLichessConfig(server.URL), "abcd1234", "e2e4", tt.offerDraw); err != nil {
+				t.Errorf("makeMove() failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestMakeMove_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Not your turn, or game already over"}`))
+	}))
+	defer server.Close()
+
+	if err := makeMove(newTestLichessConfig(server.URL), "abcd1234", "e2e4", false); err == nil {
+		t.Error("Expected error for rejected move, but got nil")
+	}
+}
+
+func TestAbortGame(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if r.Method != http.MethodPost || r.URL.Path != "/api/bot/game/abcd1234/abort" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	if err := abortGame(newTestLichessConfig(server.URL), "abcd1234"); err != nil {
+		t.Errorf("abortGame() failed: %v", err)
+	}
+	if !called {
+		t.Error("Expected abort endpoint to be called")
+	}
+}
+
+func TestResignGame(t *testing.T) {
+	mock := lichessmock.NewServer()
+	defer mock.Close()
+	cfg := newTestLichessConfig(mock.URL())
+
+	if err := resignGame(cfg, "game1"); err != nil {
+		t.Fatalf("resignGame() failed: %v", err)
+	}
+	if resigned := mock.ResignedGames(); len(resigned) != 1 || resigned[0] != "game1" {
+		t.Errorf("Expected game1 to be resigned, got %v", resigned)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Not your game"}`))
+	}))
+	defer server.Close()
+	if err := resignGame(newTestLichessConfig(server.URL), "game2"); err == nil {
+		t.Error("Expected error for rejected resignation, but got nil")
+	}
+}
+
+func TestDeclineChallenge(t *testing.T) {
+	mock := lichessmock.NewServer()
+	defer mock.Close()
+	cfg := newTestLichessConfig(mock.URL())
+
+	for _, id := range []string{"chal1", "chal2", "chal3"} {
+		mock.AddChallenge(map[string]interface{}{"id": id})
+	}
+	reasons := map[string]string{"chal1": DeclineGeneric, "chal2": DeclineTooFast, "chal3": DeclineLater}
+	for id, reason := range reasons {
+		if err := declineChallenge(cfg, id, reason); err != nil {
+			t.Fatalf("declineChallenge(%s) failed: %v", id, err)
+		}
+	}
+
+	declined := mock.DeclinedChallenges()
+	for id, reason := range reasons {
+		if declined[id] != reason {
+			t.Errorf("Expected challenge %s declined as '%s', got '%s'", id, reason, declined[id])
+		}
+	}
+
+	if err := declineChallenge(cfg, "chal1", DeclineGeneric); err == nil {
+		t.Error("Expected error declining a challenge that is no longer pending, but got nil")
+	}
+}
+
+func TestGetLichessGameStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/game/export/abcd1234" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+		if accept := r.Header.Get("Accept"); accept != "application/json" {
+			t.Errorf("Expected Accept 'application/json', got '%s'", accept)
+		}
+		if r.URL.Query().Get("evals") != "false" || r.URL.Query().Get("literate") != "false" {
+			t.Errorf("Unexpected query '%s'", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"id":"abcd1234","rated":false,"status":"started"}`))
+	}))
+	defer server.Close()
+
+	status, err := getLichessGameStatus(newTestLichessConfig(server.URL), "abcd1234")
+	if err != nil {
+		t.Fatalf("getLichessGameStatus() failed: %v", err)
+	}
+	if status != "started" {
+		t.Errorf("Expected status 'started', got '%s'", status)
+	}
+}
+
+func TestGetLichessGameStatus_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if _, err := getLichessGameStatus(newTestLichessConfig(server.URL), "missing"); err == nil {
+		t.Error("Expected error for unknown game, but got nil")
+	}
+}
+
+func TestGetPendingChallenges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/challenge" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"in":[{"id":"c1"},{"id":"c2"},{"id":"c3"}],"out":[{"id":"mine"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestLichessConfig(server.URL)
+
+	challenges, err := getPendingChallenges(cfg, 0)
+	if err != nil {
+		t.Fatalf("getPendingChallenges() failed: %v", err)
+	}
+	if len(challenges) != 3 || challenges[0]["id"] != "c1" {
+		t.Errorf("Expected 3 incoming challenges, got %v", challenges)
+	}
+
+	challenges, err = getPendingChallenges(cfg, 2)
+	if err != nil {
+		t.Fatalf("getPendingChallenges() failed: %v", err)
+	}
+	if len(challenges) != 2 || challenges[1]["id"] != "c2" {
+		t.Errorf("Expected first 2 challenges, got %v", challenges)
+	}
+}
+
+func TestSendChatMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/bot/game/abcd1234/chat" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		if room := r.PostForm.Get("room"); room != "player" {
+			t.Errorf("Expected room 'player', got '%s'", room)
+		}
+		if text := r.PostForm.Get("text"); text != "Good luck & have fun!" {
+			t.Errorf("Unexpected text '%s'", text)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	if err := sendChatMessage(newTestLichessConfig(server.URL), "abcd1234", "player", "Good luck & have fun!"); err != nil {
+		t.Errorf("sendChatMessage() failed: %v", err)
+	}
+}
+
+func TestGetBotAccountDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantErr  bool
+	}{
+		{"bot account", `{"id":"mybot","username":"MyBot","title":"BOT"}`, false},
+		{"regular account", `{"id":"someone","username":"Someone"}`, true},
+		{"titled player", `{"id":"gm","username":"GM","title":"GM"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/account" {
+					t.Errorf("Unexpected path '%s'", r.URL.Path)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			account, err := getBotAccountDetails(newTestLichessConfig(server.URL))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error for non-bot account, but got nil")
+				}
+				if !strings.Contains(err.Error(), "/api/bot/account/upgrade") {
+					t.Errorf("Expected error to explain how to upgrade, got '%s'", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getBotAccountDetails() failed: %v", err)
+			}
+			if account.ID != "mybot" || account.Username != "MyBot" {
+				t.Errorf("Unexpected account %+v", account)
+			}
+		})
+	}
+}
+
+func TestValidateTokenScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantErr  string
+	}{
+		{"all scopes", `{"test_token":{"scopes":"challenge:read,bot:play,board:play","userId":"mybot"}}`, ""},
+		{"missing bot:play", `{"test_token":{"scopes":"challenge:read,board:play","userId":"mybot"}}`, "bot:play"},
+		{"no scopes", `{"test_token":{"scopes":"","userId":"mybot"}}`, "bot:play"},
+		{"invalid token", `{"test_token":null}`, "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/api/token/test" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			err := validateTokenScopes(newTestLichessConfig(server.URL))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateTokenScopes() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error mentioning '%s', got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReconcileOngoingGames(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/account/playing" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+		w.Write([]byte(`{"nowPlaying":[
+			{"gameId":"known1","color":"white","isMyTurn":true,"opponent":{"username":"alice"}},
+			{"gameId":"lost2","color":"black","isMyTurn":false,"lastMove":"e2e4","opponent":{"username":"bob"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	var resumed []OngoingGame
+	n, err := reconcileOngoingGames(newTestLichessConfig(server.URL),
+		func(gameID string) bool { return gameID == "known1" },
+		func(g OngoingGame) { resumed = append(resumed, g) })
+	if err != nil {
+		t.Fatalf("reconcileOngoingGames() failed: %v", err)
+	}
+	if n != 1 || len(resumed) != 1 || resumed[0].GameID != "lost2" {
+		t.Fatalf("Expected only 'lost2' to be resumed, got %+v", resumed)
+	}
+	if resumed[0].Color != "black" || resumed[0].LastMove != "e2e4" || resumed[0].Opponent.Username != "bob" {
+		t.Errorf("Unexpected game details %+v", resumed[0])
+	}
+}
+
+func TestGetLichessOngoingGames_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := getLichessOngoingGames(ctx, newTestLichessConfig(server.URL)); err == nil {
+		t.Error("Expected error when Lichess does not answer in time, but got nil")
+	}
+}
+
+func TestLichessClient_AgainstMock(t *testing.T) {
+	mock := lichessmock.NewServer()
+	defer mock.Close()
+	cfg := newTestLichessConfig(mock.URL())
+
+	account, err := getBotAccountDetails(cfg)
+	if err != nil {
+		t.Fatalf("getBotAccountDetails() failed: %v", err)
+	}
+	if account.Title != "BOT" {
+		t.Errorf("Expected BOT account, got %+v", account)
+	}
+
+	mock.AddChallenge(map[string]interface{}{"id": "chal1"})
+	challenges, err := getPendingChallenges(cfg, 0)
+	if err != nil {
+		t.Fatalf("getPendingChallenges() failed: %v", err)
+	}
+	if len(challenges) != 1 || challenges[0]["id"] != "chal1" {
+		t.Errorf("Unexpected pending challenges %v", challenges)
+	}
+
+	for _, move := range []string{"e2e4", "g1f3"} {
+		if err := makeMove(cfg, "game1", move, false); err != nil {
+			t.Fatalf("makeMove() failed: %v", err)
+		}
+	}
+	mock.AssertMoves(t, "game1", "e2e4", "g1f3")
+
+	if err := sendChatMessage(cfg, "game1", "player", "Good game!"); err != nil {
+		t.Fatalf("sendChatMessage() failed: %v", err)
+	}
+	if chat := mock.ChatMessages("game1"); len(chat) != 1 || chat[0] != "player: Good game!" {
+		t.Errorf("Unexpected chat messages %v", chat)
+	}
+
+	if err := abortGame(cfg, "game2"); err != nil {
+		t.Fatalf("abortGame() failed: %v", err)
+	}
+	if aborted := mock.AbortedGames(); len(aborted) != 1 || aborted[0] != "game2" {
+		t.Errorf("Unexpected aborted games %v", aborted)
+	}
+}
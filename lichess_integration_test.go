@@ -0,0 +1,166 @@
+//go:build integration
+
+// Integration tests against a real Lichess server, such as a local lila instance.
+// They need two BOT accounts, the bot under test and an opponent that challenges it:
+//
+//	INTEGRATION_BASE_URL=http://localhost:9663 \
+//	INTEGRATION_LICHESS_TOKEN=<bot token> \
+//	INTEGRATION_OPPONENT_TOKEN=<opponent bot token> \
+//	go test -tags integration -run Integration .
+//
+// Tests are skipped when the variables they need are unset.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+)
+
+// integrationTimeout bounds a whole integration game
+const integrationTimeout = 2 * time.Minute
+
+// integrationConfig returns a config for the server and token named by key, skipping
+// the test when either is unset
+func integrationConfig(t *testing.T, tokenKey string) *BotConfig {
+	t.Helper()
+	baseURL, token := os.Getenv("INTEGRATION_BASE_URL"), os.Getenv(tokenKey)
+	if baseURL == "" || token == "" {
+		t.Skipf("INTEGRATION_BASE_URL and %s must be set", tokenKey)
+	}
+	return &BotConfig{
+		LichessToken:               token,
+		LichessBaseURL:             baseURL,
+		DisableLLM:                 true,
+		MaxIllegalMoveRetries:      1,
+		ChallengeAcceptProbability: 1,
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after timeout
+func waitFor(t *testing.T, what string, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+func TestIntegration_Account(t *testing.T) {
+	cfg := integrationConfig(t, "INTEGRATION_LICHESS_TOKEN")
+
+	account, err := getBotAccountDetails(cfg)
+	if err != nil {
+		t.Fatalf("getBotAccountDetails() failed: %v", err)
+	}
+	if account.Title != "BOT" {
+		t.Errorf("Expected a BOT account, got %+v", account)
+	}
+	if err := validateTokenScopes(cfg); err != nil {
+		t.Errorf("validateTokenScopes() failed: %v", err)
+	}
+}
+
+// TestIntegration_PlayGame has the opponent challenge the bot, checks that the bot
+// accepts and plays, then has the opponent resign and checks that the bot sees the
+// game end
+func TestIntegration_PlayGame(t *testing.T) {
+	cfg := integrationConfig(t, "INTEGRATION_LICHESS_TOKEN")
+	opponentCfg := integrationConfig(t, "INTEGRATION_OPPONENT_TOKEN")
+	account, err := getBotAccountDetails(cfg)
+	if err != nil {
+		t.Fatalf("getBotAccountDetails() failed: %v", err)
+	}
+	opponent, err := getBotAccountDetails(opponentCfg)
+	if err != nil {
+		t.Fatalf("getBotAccountDetails() for the opponent failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), integrationTimeout)
+	defer cancel()
+	bot := NewBot(cfg, account)
+	pipeline := newChallengePipeline(cfg, rand.Float64)
+	challenges := NewChallengeProcessor(1, func(challenge ChallengeData) {
+		if err := handleChallenge(cfg, pipeline, challenge); err != nil {
+			t.Errorf("handleChallenge(%s) failed: %v", challenge.ID, err)
+		}
+	})
+	go streamLichessEvents(ctx, cfg, bot, challenges)
+
+	// An accepted challenge becomes a game with the same ID
+	gameID, err := createChallenge(opponentCfg, account.Username, 300, 0, false)
+	if err != nil {
+		t.Fatalf("createChallenge() failed: %v", err)
+	}
+	waitFor(t, "the bot to accept the challenge and start the game", integrationTimeout, func() bool {
+		return bot.IsActive(gameID)
+	})
+
+	// The opponent plays random moves and resigns once both sides have moved twice
+	const pliesBeforeResign = 4
+	resigned := make(chan error, 1)
+	go func() {
+		var game *Game
+		failure := fmt.Errorf("game stream ended before the opponent resigned")
+		err := streamGameEvents(ctx, opponentCfg, gameID, slog.Default(), func(event map[string]interface{}) bool {
+			var err error
+			switch event["type"] {
+			case "gameFull":
+				game, err = newGameFromFull(event, opponent.ID)
+			case "gameState":
+				err = game.Update(event)
+			default:
+				return true
+			}
+			if err == nil && game.IsBotTurn() {
+				if game.MoveCount() >= pliesBeforeResign {
+					failure = resignGame(opponentCfg, gameID)
+					return false
+				}
+				var move string
+				if move, err = randomLegalMove(game.Moves(), game.InitialFEN, rand.Intn); err == nil {
+					err = makeMove(opponentCfg, gameID, move, false)
+				}
+			}
+			if err != nil {
+				failure = err
+				return false
+			}
+			return !game.IsOver()
+		})
+		if err != nil {
+			failure = err
+		}
+		resigned <- failure
+	}()
+
+	select {
+	case err := <-resigned:
+		if err != nil {
+			t.Fatalf("Opponent failed: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for the game to be played")
+	}
+	waitFor(t, "the bot to notice the game end", integrationTimeout, func() bool {
+		return !bot.IsActive(gameID)
+	})
+
+	status, err := getLichessGameStatus(cfg, gameID)
+	if err != nil {
+		t.Fatalf("getLichessGameStatus() failed: %v", err)
+	}
+	if status != "resign" {
+		t.Errorf("Expected the game to end by resignation, got status '%s'", status)
+	}
+	cancel()
+	challenges.Close()
+	bot.Wait()
+}
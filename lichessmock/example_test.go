@@ -0,0 +1,63 @@
+package lichessmock_test
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"lichess-bot-agent/lichessmock"
+)
+
+func ExampleMockLichessServer_Moves() {
+	mock := lichessmock.NewServer()
+	defer mock.Close()
+
+	// The code under test submits moves against mock.URL()
+	for _, move := range []string{"e2e4", "g1f3"} {
+		resp, err := http.Post(mock.URL()+"/api/bot/game/game1/move/"+move, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		resp.Body.Close()
+	}
+
+	fmt.Println(mock.Moves("game1"))
+	// Output: [e2e4 g1f3]
+}
+
+func ExampleMockLichessServer_InjectGameEvent() {
+	mock := lichessmock.NewServer()
+	defer mock.Close()
+
+	mock.InjectGameEvent("game1", map[string]interface{}{"type": "gameState", "moves": "e2e4", "status": "started"})
+
+	resp, err := http.Get(mock.URL() + "/api/bot/game/stream/game1")
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	fmt.Print(line)
+	// Output: {"moves":"e2e4","status":"started","type":"gameState"}
+}
+
+func ExampleMockLichessServer_DeclinedChallenges() {
+	mock := lichessmock.NewServer()
+	defer mock.Close()
+
+	mock.AddChallenge(map[string]interface{}{"id": "chal1", "speed": "bullet"})
+
+	form := url.Values{"reason": {"tooFast"}}
+	resp, err := http.Post(mock.URL()+"/api/challenge/chal1/decline", "application/x-www-form-urlencoded",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		panic(err)
+	}
+	resp.Body.Close()
+
+	fmt.Println(mock.DeclinedChallenges())
+	// Output: map[chal1:tooFast]
+}
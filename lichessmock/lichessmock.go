@@ -0,0 +1,356 @@
+// Package lichessmock provides an in-process fake of the Lichess bot API for tests.
+//
+// A MockLichessServer serves the event and game streams from events injected by the
+// test, records moves, draw offers, challenge answers, chat messages and game actions,
+// and lets the test configure pending challenges and the bot account:
+//
+//	mock := lichessmock.NewServer()
+//	defer mock.Close()
+//	cfg.LichessBaseURL = mock.URL()
+//	mock.InjectGameEvent("game1", map[string]interface{}{"type": "gameState", "moves": "e2e4"})
+//	...
+//	mock.AssertMoves(t, "game1", "e7e5")
+package lichessmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const streamBufferSize = 100
+
+// MockLichessServer is a fake Lichess API backed by httptest.Server
+type MockLichessServer struct {
+	server  *httptest.Server
+	handler http.Handler
+	// delay is waited before each stream event, keepAlive is the interval of the
+	// empty keep-alive lines (0 for none)
+	delay     time.Duration
+	keepAlive time.Duration
+
+	mu         sync.Mutex
+	streams    map[string]chan []byte
+	moves      map[string][]string
+	drawOffers map[string][]string
+	accepted   []string
+	declined   map[string]string
+	aborted    []string
+	resigned   []string
+	chat       map[string][]string
+	challenges []map[string]interface{}
+	account    map[string]interface{}
+}
+
+// NewServer starts a mock server with a BOT account called "mockbot"
+func NewServer() *MockLichessServer {
+	m := New()
+	m.server = httptest.NewServer(m.handler)
+	return m
+}
+
+// New creates a mock with a BOT account called "mockbot" without starting a server,
+// for serving its Handler on an address of the caller's choice
+func New() *MockLichessServer {
+	m := &MockLichessServer{
+		streams:    make(map[string]chan []byte),
+		moves:      make(map[string][]string),
+		drawOffers: make(map[string][]string),
+		declined:   make(map[string]string),
+		chat:       make(map[string][]string),
+		account:    map[string]interface{}{"id": "mockbot", "username": "MockBot", "title": "BOT"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/account", m.handleAccount)
+	mux.HandleFunc("GET /api/stream/event", func(w http.ResponseWriter, r *http.Request) {
+		m.serveStream(w, r, "events")
+	})
+	mux.HandleFunc("GET /api/bot/game/stream/{gameID}", func(w http.ResponseWriter, r *http.Request) {
+		m.serveStream(w, r, "game/"+r.PathValue("gameID"))
+	})
+	mux.HandleFunc("POST /api/bot/game/{gameID}/move/{move}", m.handleMove)
+	mux.HandleFunc("POST /api/bot/game/{gameID}/abort", m.recordAction(&m.aborted))
+	mux.HandleFunc("POST /api/bot/game/{gameID}/resign", m.recordAction(&m.resigned))
+	mux.HandleFunc("POST /api/bot/game/{gameID}/chat", m.handleChat)
+	mux.HandleFunc("GET /api/challenge", m.handleListChallenges)
+	mux.HandleFunc("POST /api/challenge/{challengeID}/accept", m.handleAccept)
+	mux.HandleFunc("POST /api/challenge/{challengeID}/decline", m.handleDecline)
+
+	m.handler = mux
+	return m
+}
+
+// Handler returns the handler serving the fake Lichess API
+func (m *MockLichessServer) Handler() http.Handler {
+	return m.handler
+}
+
+// SetStreamDelay makes the streams wait d before sending each event
+func (m *MockLichessServer) SetStreamDelay(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.delay = d
+}
+
+// SetKeepAlive makes the streams send an empty line every interval, like Lichess
+func (m *MockLichessServer) SetKeepAlive(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keepAlive = interval
+}
+
+// URL is the base URL to use as LICHESS_BASE_URL (only set for NewServer)
+func (m *MockLichessServer) URL() string {
+	return m.server.URL
+}
+
+// Close shuts the server started by NewServer down, ending any open streams
+func (m *MockLichessServer) Close() {
+	if m.server == nil {
+		return
+	}
+	m.server.CloseClientConnections()
+	m.server.Close()
+}
+
+// SetAccount replaces the account returned by /api/account
+func (m *MockLichessServer) SetAccount(account map[string]interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.account = account
+}
+
+// AddChallenge adds a pending incoming challenge and announces it on the event stream
+func (m *MockLichessServer) AddChallenge(challenge map[string]interface{}) {
+	m.mu.Lock()
+	m.challenges = append(m.challenges, challenge)
+	m.mu.Unlock()
+	m.InjectEvent(map[string]interface{}{"type": "challenge", "challenge": challenge})
+}
+
+// InjectEvent queues an event on /api/stream/event
+func (m *MockLichessServer) InjectEvent(event interface{}) {
+	m.inject("events", event)
+}
+
+// InjectGameEvent queues an event on /api/bot/game/stream/{gameID}
+func (m *MockLichessServer) InjectGameEvent(gameID string, event interface{}) {
+	m.inject("game/"+gameID, event)
+}
+
+func (m *MockLichessServer) inject(key string, event interface{}) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		panic(fmt.Sprintf("lichessmock: cannot marshal event: %v", err))
+	}
+	m.stream(key) <- line
+}
+
+func (m *MockLichessServer) stream(key string) chan []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.streams[key]; ok {
+		return s
+	}
+	s := make(chan []byte, streamBufferSize)
+	m.streams[key] = s
+	return s
+}
+
+func (m *MockLichessServer) serveStream(w http.ResponseWriter, r *http.Request, key string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	m.mu.Lock()
+	delay, interval := m.delay, m.keepAlive
+	m.mu.Unlock()
+	var keepAlive <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
+	events := m.stream(key)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive:
+			w.Write([]byte("\n"))
+			flusher.Flush()
+		case line := <-events:
+			if delay > 0 {
+				time.Sleep(delay)
+			}
+			w.Write(append(line, '\n'))
+			flusher.Flush()
+		}
+	}
+}
+
+// Moves returns the moves the bot submitted in a game, in order
+func (m *MockLichessServer) Moves(gameID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.moves[gameID]...)
+}
+
+// AssertMoves fails the test unless exactly the expected moves were submitted in the game
+func (m *MockLichessServer) AssertMoves(t testing.TB, gameID string, expected ...string) {
+	t.Helper()
+	if got := m.Moves(gameID); !reflect.DeepEqual(got, expected) && (len(got) != 0 || len(expected) != 0) {
+		t.Errorf("lichessmock: expected moves %v in game %s, got %v", expected, gameID, got)
+	}
+}
+
+// DrawOffers returns the moves the bot submitted with a draw offer in a game
+func (m *MockLichessServer) DrawOffers(gameID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.drawOffers[gameID]...)
+}
+
+// AcceptedChallenges returns the IDs of accepted challenges
+func (m *MockLichessServer) AcceptedChallenges() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.accepted...)
+}
+
+// DeclinedChallenges returns declined challenge IDs mapped to the decline reason
+func (m *MockLichessServer) DeclinedChallenges() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	declined := make(map[string]string, len(m.declined))
+	for id, reason := range m.declined {
+		declined[id] = reason
+	}
+	return declined
+}
+
+// AbortedGames returns the IDs of games the bot aborted
+func (m *MockLichessServer) AbortedGames() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.aborted...)
+}
+
+// ResignedGames returns the IDs of games the bot resigned
+func (m *MockLichessServer) ResignedGames() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.resigned...)
+}
+
+// ChatMessages returns the chat messages the bot sent in a game as "room: text"
+func (m *MockLichessServer) ChatMessages(gameID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.chat[gameID]...)
+}
+
+func (m *MockLichessServer) handleAccount(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	writeJSON(w, m.account)
+}
+
+func (m *MockLichessServer) handleMove(w http.ResponseWriter, r *http.Request) {
+	gameID, move := r.PathValue("gameID"), r.PathValue("move")
+	m.mu.Lock()
+	m.moves[gameID] = append(m.moves[gameID], move)
+	if r.URL.Query().Get("offeringDraw") == "true" {
+		m.drawOffers[gameID] = append(m.drawOffers[gameID], move)
+	}
+	m.mu.Unlock()
+	writeJSON(w, map[string]bool{"ok": true})
+}
+
+func (m *MockLichessServer) recordAction(ids *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		*ids = append(*ids, r.PathValue("gameID"))
+		m.mu.Unlock()
+		writeJSON(w, map[string]bool{"ok": true})
+	}
+}
+
+func (m *MockLichessServer) handleChat(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	gameID := r.PathValue("gameID")
+	m.mu.Lock()
+	m.chat[gameID] = append(m.chat[gameID], r.Form.Get("room")+": "+r.Form.Get("text"))
+	m.mu.Unlock()
+	writeJSON(w, map[string]bool{"ok": true})
+}
+
+func (m *MockLichessServer) handleListChallenges(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	in := m.challenges
+	if in == nil {
+		in = []map[string]interface{}{}
+	}
+	writeJSON(w, map[string]interface{}{"in": in, "out": []interface{}{}})
+}
+
+func (m *MockLichessServer) handleAccept(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("challengeID")
+	if !m.removeChallenge(id) {
+		http.Error(w, `{"error":"Not found"}`, http.StatusNotFound)
+		return
+	}
+	m.mu.Lock()
+	m.accepted = append(m.accepted, id)
+	m.mu.Unlock()
+	writeJSON(w, map[string]bool{"ok": true})
+}
+
+func (m *MockLichessServer) handleDecline(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("challengeID")
+	if !m.removeChallenge(id) {
+		http.Error(w, `{"error":"Not found"}`, http.StatusNotFound)
+		return
+	}
+	r.ParseForm()
+	reason := strings.TrimSpace(r.Form.Get("reason"))
+	if reason == "" {
+		reason = "generic"
+	}
+	m.mu.Lock()
+	m.declined[id] = reason
+	m.mu.Unlock()
+	writeJSON(w, map[string]bool{"ok": true})
+}
+
+// removeChallenge drops a pending challenge, reporting whether it existed
+func (m *MockLichessServer) removeChallenge(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, c := range m.challenges {
+		if c["id"] == id {
+			m.challenges = append(m.challenges[:i], m.challenges[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
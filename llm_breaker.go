@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+)
+
+// Actions taken once a game's LLM call budget is used up
+const (
+	BreakerActionResign = "resign"
+	BreakerActionRandom = "random"
+)
+
+// ErrLLMCallLimit is returned for moves requested after a game's breaker has tripped
+var ErrLLMCallLimit = errors.New("LLM call limit for the game exceeded")
+
+// LLMCallBreaker counts LLM calls made for a single game and trips once the
+// limit is exceeded, to stop runaway API costs from invalid-move loops
+type LLMCallBreaker struct {
+	gameID   string
+	maxCalls int64
+	calls    atomic.Int64
+	tripped  atomic.Bool
+}
+
+// NewLLMCallBreaker creates a breaker for one game (maxCalls <= 0 means no limit)
+func NewLLMCallBreaker(gameID string, maxCalls int) *LLMCallBreaker {
+	return &LLMCallBreaker{gameID: gameID, maxCalls: int64(maxCalls)}
+}
+
+// Allow records an LLM call and reports whether it may be made
+func (b *LLMCallBreaker) Allow() bool {
+	count := b.calls.Add(1)
+	if b.maxCalls <= 0 || count <= b.maxCalls {
+		return true
+	}
+	if b.tripped.CompareAndSwap(false, true) {
+		log.Printf("Game %s exceeded %d LLM calls, circuit breaker tripped", b.gameID, b.maxCalls)
+	}
+	return false
+}
+
+// Calls returns the number of LLM calls requested so far
+func (b *LLMCallBreaker) Calls() int64 {
+	return b.calls.Load()
+}
+
+// Tripped reports whether the limit has been exceeded
+func (b *LLMCallBreaker) Tripped() bool {
+	return b.tripped.Load()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestLLMCallBreaker_TripsOnFailureLoop(t *testing.T) {
+	breaker := NewLLMCallBreaker("game1", 10)
+	errInvalidMove := errors.New("invalid move")
+
+	// Simulate an LLM that never returns a legal move
+	queryLLM := func() error { return errInvalidMove }
+
+	calls := 0
+	for breaker.Allow() {
+		calls++
+		if err := queryLLM(); err == nil {
+			t.Fatal("Expected simulated LLM to fail")
+		}
+		if calls > 100 {
+			t.Fatal("Circuit breaker never tripped")
+		}
+	}
+
+	if calls != 10 {
+		t.Errorf("Expected exactly 10 LLM calls before tripping, got %d", calls)
+	}
+	if breaker.Allow() {
+		t.Error("Expected breaker to stay tripped")
+	}
+}
+
+func TestLLMCallBreaker_Concurrent(t *testing.T) {
+	breaker := NewLLMCallBreaker("game1", 50)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if breaker.Allow() {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 50 {
+		t.Errorf("Expected 50 allowed calls, got %d", allowed)
+	}
+	if breaker.Calls() != 100 {
+		t.Errorf("Expected 100 recorded calls, got %d", breaker.Calls())
+	}
+}
+
+func TestLLMCallBreaker_Unlimited(t *testing.T) {
+	breaker := NewLLMCallBreaker("game1", 0)
+	for i := 0; i < 1000; i++ {
+		if !breaker.Allow() {
+			t.Fatalf("Expected unlimited breaker to allow call %d", i)
+		}
+	}
+}
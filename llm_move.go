@@ -0,0 +1,130 @@
+package main
+
+import "math/rand"
+
+// getBestMoveFromLLM asks model for the bot's next move in game. Illegal answers are
+// retried as configured by MAX_ILLEGAL_MOVE_RETRIES. Moves left in the game's
+// TEST_MOVE_SEQUENCE are played as they are, and with DISABLE_LLM set it plays a
+// random legal move, in both cases without calling OpenRouter. A move from the
+// PREWARM_PGN_FILE position cache or a reply pondered for the current position is
+// used without a new query. With VALIDATOR_MODEL set, the validator may replace a
+// move the model just proposed.
+func getBestMoveFromLLM(cfg *BotConfig, game *Game, model string) (string, error) {
+	if move, ok := game.scripted.Next(); ok {
+		return move, nil
+	}
+	moves := game.Moves()
+	if cfg.DisableLLM {
+		return randomLegalMove(moves, game.InitialFEN, rand.Intn)
+	}
+	if game.positions != nil {
+		if fen, err := movesToFEN(moves, game.InitialFEN); err == nil {
+			if move, ok := game.positions.Lookup(fen); ok && isLegalMove(moves, game.InitialFEN, move) {
+				game.logf("Playing known move %s from the position cache in game %s", move, game.ID)
+				return move, nil
+			}
+		}
+	}
+	if game.PonderCache != nil {
+		if move, ok := game.PonderCache.Lookup(moves); ok && isLegalMove(moves, game.InitialFEN, move) {
+			game.logf("Ponder hit in game %s, playing %s", game.ID, move)
+			return move, nil
+		}
+	}
+	move, err := requestLLMMove(cfg, game, moves, game.Color, model)
+	if err != nil || cfg.ValidatorModel == "" {
+		return move, err
+	}
+	return validatedMove(cfg, game, moves, move), nil
+}
+
+// validatedMove has VALIDATOR_MODEL double-check the move the primary model proposed
+// and returns the move to play. The proposed move is kept if the validator fails.
+func validatedMove(cfg *BotConfig, game *Game, moves []string, proposed string) string {
+	if game.llmCalls != nil && !game.llmCalls.Allow() {
+		return proposed
+	}
+	validation, err := validateMove(cfg, moves, game.InitialFEN, proposed)
+	if err != nil {
+		game.logf("Keeping move %s in game %s unvalidated: %v", proposed, game.ID, err)
+		return proposed
+	}
+	move, _ := chooseValidatedMove(moves, game.InitialFEN, proposed, validation)
+	return move
+}
+
+// requestLLMMove asks model for the move of color after moves in game, counting
+// the calls against the game's LLM call limit. With MULTI_STEP_PROMPT set the model
+// picks from its own candidate moves. Otherwise it answers in JSON with
+// RESPONSE_FORMAT_JSON set, or reasons first with THINK_BEFORE_MOVE set. How the
+// bot's moves were found is kept in the game.
+func requestLLMMove(cfg *BotConfig, game *Game, moves []string, color, model string) (string, error) {
+	prompt := withFeedback(buildMovePrompt(cfg, moves, game.InitialFEN, color), game.feedback)
+	// A JSON answer leaves no room for reasoning before the move
+	chainOfThought := cfg.LLMChainOfThought && !cfg.LLMResponseFormatJSON
+	switch {
+	case cfg.LLMResponseFormatJSON:
+		prompt = withJSONMoveFormat(prompt)
+	case chainOfThought:
+		prompt = withChainOfThought(prompt)
+	}
+
+	var reply llmReply
+	move, err := requestLegalMove(cfg, moves, game.InitialFEN, func(attempt int) (string, error) {
+		if game.llmCalls != nil && !game.llmCalls.Allow() {
+			return "", ErrLLMCallLimit
+		}
+		if cfg.LLMMultiStep {
+			// The selection step is a second call
+			if game.llmCalls != nil && !game.llmCalls.Allow() {
+				return "", ErrLLMCallLimit
+			}
+			reply.Attempts = attempt + 1
+			move, _, err := getMultiStepMove(cfg, model, moves, game.InitialFEN)
+			return move, err
+		}
+		messages := withPersonality([]openRouterMessage{{Role: "user", Content: prompt}}, game.personality)
+		content, err := game.callLLM(cfg, newOpenRouterRequest(cfg, model, messages, attempt))
+		if err != nil {
+			return "", err
+		}
+		reply.Attempts = attempt + 1
+		switch {
+		case cfg.LLMResponseFormatJSON:
+			return parseJSONMove(content)
+		case chainOfThought:
+			var move string
+			move, reply.Reasoning, err = parseChainOfThought(content)
+			return move, err
+		}
+		return parseMoveReply(content)
+	})
+	if err != nil && game.llmCalls != nil && game.llmCalls.Tripped() {
+		return "", ErrLLMCallLimit
+	}
+	if err == nil && color == game.Color {
+		game.setLLMReply(len(moves), reply)
+	}
+	return move, err
+}
+
+// ponderReply predicts the opponent's reply to the position after moves and stores
+// the bot's answer to it in the game's PonderCache, so that the answer is ready if
+// the opponent plays the predicted move
+func ponderReply(cfg *BotConfig, game *Game, moves []string, model string) error {
+	opponentColor := "white"
+	if game.Color == "white" {
+		opponentColor = "black"
+	}
+	predicted, err := requestLLMMove(cfg, game, moves, opponentColor, model)
+	if err != nil {
+		return err
+	}
+	expected := append(append([]string(nil), moves...), predicted)
+	reply, err := requestLLMMove(cfg, game, expected, game.Color, model)
+	if err != nil {
+		return err
+	}
+	game.PonderCache.Store(expected, reply)
+	return nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetBestMoveFromLLM_RetriesIllegalMove(t *testing.T) {
+	replies := []string{"e2e5", "Nf3 is best: g1f3"}
+	var prompts []string
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Messages[0].Content)
+
+		reply, _ := json.Marshal(replies[len(prompts)-1])
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":` + string(reply) + `}}]}`))
+	})
+
+	cfg := &BotConfig{MaxIllegalMoveRetries: 3}
+	game := &Game{ID: "g", Color: "white", InitialFEN: startposFEN, whiteStarts: true}
+	move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+	if err != nil {
+		t.Fatalf("getBestMoveFromLLM() failed: %v", err)
+	}
+	if move != "g1f3" {
+		t.Errorf("Expected g1f3 after the illegal first answer, got %s", move)
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[0], "as white") {
+		t.Errorf("Expected two move prompts for white, got %q", prompts)
+	}
+}
+
+func TestGetBestMoveFromLLM_CustomInitialFEN(t *testing.T) {
+	fen := "8/8/8/4k3/8/8/4P3/4K3 w - - 0 1"
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !strings.Contains(req.Messages[0].Content, fen) {
+			t.Errorf("Expected the prompt to contain the starting FEN, got %q", req.Messages[0].Content)
+		}
+		// Only legal from the custom position, where the white king stands on e1
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e1d2"}}]}`))
+	})
+
+	cfg := &BotConfig{MaxIllegalMoveRetries: 1}
+	game := &Game{ID: "g", Color: "white", InitialFEN: fen, whiteStarts: true}
+	move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+	if err != nil || move != "e1d2" {
+		t.Errorf("Expected e1d2 from the custom position, got %q (%v)", move, err)
+	}
+}
+
+func TestGetBestMoveFromLLM_DisableLLM(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no OpenRouter call with DISABLE_LLM set")
+	})
+
+	cfg := &BotConfig{DisableLLM: true, MaxIllegalMoveRetries: 1}
+	game := &Game{ID: "g", Color: "black", InitialFEN: startposFEN, whiteStarts: true, moves: []string{"d2d4"}}
+	for i := 0; i < 10; i++ {
+		move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+		if err != nil {
+			t.Fatalf("getBestMoveFromLLM() failed: %v", err)
+		}
+		if !isLegalMove([]string{"d2d4"}, "", move) {
+			t.Errorf("Expected a legal random move, got %s", move)
+		}
+	}
+}
+
+func TestGetBestMoveFromLLM_PositionCache(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"a7a6"}}]}`))
+	})
+	cache := NewPositionCache()
+	fen, _ := movesToFEN([]string{"e2e4"}, "")
+	cache.Add(fen, "c7c5")
+
+	cfg := &BotConfig{MaxIllegalMoveRetries: 1}
+	game := &Game{ID: "g", Color: "black", InitialFEN: startposFEN, whiteStarts: true, positions: cache, moves: []string{"e2e4"}}
+	if move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o"); err != nil || move != "c7c5" {
+		t.Errorf("Expected the cached c7c5, got %q (%v)", move, err)
+	}
+	// Unknown positions are still sent to the LLM
+	game.moves = []string{"d2d4"}
+	if move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o"); err != nil || move != "a7a6" {
+		t.Errorf("Expected the LLM's a7a6, got %q (%v)", move, err)
+	}
+}
+
+func TestGetBestMoveFromLLM_TestMoveSequence(t *testing.T) {
+	var calls int
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"d2d4"}}]}`))
+	})
+
+	cfg := &BotConfig{MaxIllegalMoveRetries: 1}
+	game := &Game{ID: "g", Color: "white", InitialFEN: startposFEN, whiteStarts: true,
+		scripted: NewScriptedMoves([]string{"e2e4", "g1f3"})}
+
+	var played []string
+	for _, opponent := range []string{"e7e5", "b8c6", ""} {
+		move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+		if err != nil {
+			t.Fatalf("getBestMoveFromLLM() failed: %v", err)
+		}
+		played = append(played, move)
+		game.moves = append(game.moves, move)
+		if opponent != "" {
+			game.moves = append(game.moves, opponent)
+		}
+	}
+	if strings.Join(played, " ") != "e2e4 g1f3 d2d4" {
+		t.Errorf("Expected the scripted moves followed by the LLM move, got %v", played)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the LLM to be asked only after the sequence ran out, got %d calls", calls)
+	}
+}
+
+func TestPonderReply(t *testing.T) {
+	var calls int
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		move := "g1f3"
+		if strings.Contains(req.Messages[0].Content, "as black") {
+			move = "e7e5"
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"` + move + `"}}]}`))
+	})
+
+	cfg := &BotConfig{MaxIllegalMoveRetries: 1}
+	game := &Game{ID: "g", Color: "white", InitialFEN: startposFEN, whiteStarts: true, PonderCache: NewPonderCache()}
+	if err := ponderReply(cfg, game, []string{"e2e4"}, "openai/gpt-4o"); err != nil {
+		t.Fatalf("ponderReply() failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected one call for the predicted reply and one for the answer, got %d", calls)
+	}
+
+	// The opponent plays the predicted move: the pondered answer is used as is
+	game.moves = []string{"e2e4", "e7e5"}
+	move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+	if err != nil || move != "g1f3" {
+		t.Errorf("Expected the pondered g1f3, got %q (%v)", move, err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected no LLM call on a ponder hit, got %d calls", calls)
+	}
+
+	// Any other reply misses the cache and queries the LLM
+	game.moves = []string{"e2e4", "c7c5"}
+	if _, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o"); err != nil {
+		t.Fatalf("getBestMoveFromLLM() failed: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected an LLM call on a ponder miss, got %d calls", calls)
+	}
+}
+
+func TestGetBestMoveFromLLM_Validator(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator string
+		expected  string
+	}{
+		{"override", "d2d4 85", "d2d4"},
+		{"low confidence", "d2d4 40", "a2a3"},
+		{"unparseable", "no idea", "a2a3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var models []string
+			withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+				var req openRouterRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				models = append(models, req.Model)
+				reply := "a2a3"
+				if req.Model == "openai/gpt-4o-mini" {
+					reply = tt.validator
+				}
+				w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"` + reply + `"}}]}`))
+			})
+
+			cfg := &BotConfig{MaxIllegalMoveRetries: 1, ValidatorModel: "openai/gpt-4o-mini"}
+			game := &Game{ID: "g", Color: "white", InitialFEN: startposFEN, whiteStarts: true}
+			move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+			if err != nil || move != tt.expected {
+				t.Errorf("Expected %s, got %q (%v)", tt.expected, move, err)
+			}
+			if expected := []string{"openai/gpt-4o", "openai/gpt-4o-mini"}; strings.Join(models, " ") != strings.Join(expected, " ") {
+				t.Errorf("Expected calls to %v, got %v", expected, models)
+			}
+		})
+	}
+}
+
+func TestGetBestMoveFromLLM_ChainOfThought(t *testing.T) {
+	var prompt string
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Messages[0].Content
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"The centre matters most.\nMove: **e2e4**"}}]}`))
+	})
+
+	cfg := &BotConfig{MaxIllegalMoveRetries: 1, LLMChainOfThought: true}
+	game := &Game{ID: "g", Color: "white", InitialFEN: startposFEN, whiteStarts: true}
+	move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+	if err != nil || move != "e2e4" {
+		t.Fatalf("Expected e2e4 from the final line, got %q (%v)", move, err)
+	}
+	if !strings.HasPrefix(prompt, chainOfThoughtInstruction) {
+		t.Errorf("Expected the prompt to start with the chain-of-thought instruction, got %q", prompt)
+	}
+	if got := game.LLMReply(0).Reasoning; got != "The centre matters most." {
+		t.Errorf("Expected the reasoning to be kept for the move, got %q", got)
+	}
+}
+
+func TestGetBestMoveFromLLM_MultiStep(t *testing.T) {
+	replies := []string{"1. e2e4 - takes the centre\n2. d2d4 - also central\n3. g1f3 - develops", "d2d4"}
+	calls := 0
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		reply, _ := json.Marshal(replies[calls])
+		calls++
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":` + string(reply) + `}}]}`))
+	})
+
+	cfg := &BotConfig{MaxIllegalMoveRetries: 1, LLMMultiStep: true}
+	game := &Game{ID: "g", Color: "white", InitialFEN: startposFEN, whiteStarts: true, llmCalls: NewLLMCallBreaker("g", 10)}
+	move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+	if err != nil || move != "d2d4" {
+		t.Fatalf("Expected the selected candidate d2d4, got %q (%v)", move, err)
+	}
+	if calls != 2 || game.llmCalls.Calls() != 2 {
+		t.Errorf("Expected two LLM calls counted for the move, got %d (%d counted)", calls, game.llmCalls.Calls())
+	}
+}
+
+func TestGetBestMoveFromLLM_JSONFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+	}{
+		{"json", `{\"move\": \"g1f3\"}`},
+		{"malformed json", `{\"move\": g1f3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+				var req openRouterRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				if req.ResponseFormat == nil || !strings.HasSuffix(req.Messages[0].Content, jsonMoveInstruction) {
+					t.Errorf("Expected a JSON move request, got %+v", req)
+				}
+				w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"` + tt.reply + `"}}]}`))
+			})
+
+			cfg := &BotConfig{MaxIllegalMoveRetries: 1, LLMResponseFormatJSON: true, LLMChainOfThought: true}
+			game := &Game{ID: "g", Color: "white", InitialFEN: startposFEN, whiteStarts: true}
+			move, err := getBestMoveFromLLM(cfg, game, "openai/gpt-4o")
+			if err != nil || move != "g1f3" {
+				t.Errorf("Expected g1f3, got %q (%v)", move, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	cfg, err := LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := tokenBudget.Load(cfg.LLMSpendFile); err != nil {
+		log.Fatalf("Failed to load the LLM spend: %v", err)
+	}
+
+	if cfg.SimulateOpponent {
+		log.Printf("SIMULATE_OPPONENT is set, playing %d moves against itself without Lichess", cfg.SimulateMoves)
+		pgn, err := runSimulation(cfg)
+		if err != nil {
+			log.Fatalf("Simulation failed: %v", err)
+		}
+		fmt.Print(pgn)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := runBot(ctx, cfg); err != nil {
+		log.Fatalf("Bot stopped: %v", err)
+	}
+}
+
+// runBot plays on Lichess until ctx is done, then waits for the running games to end
+func runBot(ctx context.Context, cfg *BotConfig) error {
+	account, err := NewAccountCache(cfg).Get()
+	if err != nil {
+		return err
+	}
+	log.Printf("Playing as %s", account.Username)
+
+	bot := NewBot(cfg, account)
+	pipeline := newChallengePipeline(cfg, rand.Float64)
+	challenges := NewChallengeProcessor(cfg.ChallengeProcessorPoolSize, func(challenge ChallengeData) {
+		if err := handleChallenge(cfg, pipeline, challenge); err != nil {
+			log.Printf("Failed to answer challenge %s: %v", challenge.ID, err)
+		}
+	})
+
+	err = streamLichessEvents(ctx, cfg, bot, challenges)
+	challenges.Close()
+	bot.Wait()
+	if closeErr := bot.Close(); closeErr != nil {
+		log.Printf("Failed to close the bot: %v", closeErr)
+	}
+	return err
+}
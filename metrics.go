@@ -0,0 +1,85 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const metricsWindow = time.Minute
+
+// botMetrics collects metrics for the whole bot process
+var botMetrics = NewMetricsCollector()
+
+// MetricsCollector counts moves over a sliding one-minute window,
+// both across all games and per game
+type MetricsCollector struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	moves     []time.Time
+	gameMoves map[string][]time.Time
+
+	validationOverrides atomic.Int64
+}
+
+// NewMetricsCollector creates an empty collector
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{
+		now:       time.Now,
+		gameMoves: make(map[string][]time.Time),
+	}
+}
+
+// RecordMove records a successfully submitted move and returns the game's current move rate
+func (m *MetricsCollector) RecordMove(gameID string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.moves = append(pruneOlderThan(m.moves, now), now)
+	m.gameMoves[gameID] = append(pruneOlderThan(m.gameMoves[gameID], now), now)
+	return float64(len(m.gameMoves[gameID]))
+}
+
+// MovesPerMinute returns the number of moves made in the last minute across all games
+func (m *MetricsCollector) MovesPerMinute() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.moves = pruneOlderThan(m.moves, m.now())
+	return float64(len(m.moves))
+}
+
+// GameMoveRate returns the number of moves made in the last minute in one game
+func (m *MetricsCollector) GameMoveRate(gameID string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	moves := pruneOlderThan(m.gameMoves[gameID], m.now())
+	if len(moves) == 0 {
+		delete(m.gameMoves, gameID)
+		return 0
+	}
+	m.gameMoves[gameID] = moves
+	return float64(len(moves))
+}
+
+// pruneOlderThan drops timestamps that fell out of the window (timestamps are in order)
+func pruneOlderThan(times []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-metricsWindow)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// RecordValidationOverride counts a move where the validator model's choice replaced the primary's
+func (m *MetricsCollector) RecordValidationOverride() {
+	m.validationOverrides.Add(1)
+}
+
+// ValidationOverrides returns how many moves were replaced by the validator model
+func (m *MetricsCollector) ValidationOverrides() int64 {
+	return m.validationOverrides.Load()
+}
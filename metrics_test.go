@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetricsCollector_SlidingWindow(t *testing.T) {
+	current := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMetricsCollector()
+	m.now = func() time.Time { return current }
+
+	m.RecordMove("game1")
+	current = current.Add(20 * time.Second)
+	m.RecordMove("game1")
+	m.RecordMove("game2")
+
+	if rate := m.MovesPerMinute(); rate != 3 {
+		t.Errorf("Expected 3 moves per minute, got %v", rate)
+	}
+	if rate := m.GameMoveRate("game1"); rate != 2 {
+		t.Errorf("Expected game1 rate 2, got %v", rate)
+	}
+
+	// The first move falls out of the window after 60 seconds
+	current = current.Add(45 * time.Second)
+	if rate := m.MovesPerMinute(); rate != 2 {
+		t.Errorf("Expected 2 moves per minute after window slides, got %v", rate)
+	}
+	if rate := m.GameMoveRate("game1"); rate != 1 {
+		t.Errorf("Expected game1 rate 1 after window slides, got %v", rate)
+	}
+
+	current = current.Add(time.Minute)
+	if rate := m.MovesPerMinute(); rate != 0 {
+		t.Errorf("Expected 0 moves per minute, got %v", rate)
+	}
+	if rate := m.GameMoveRate("game2"); rate != 0 {
+		t.Errorf("Expected game2 rate 0, got %v", rate)
+	}
+}
+
+func TestMetricsCollector_RecordMoveReturnsGameRate(t *testing.T) {
+	m := NewMetricsCollector()
+	for i := 1; i <= 5; i++ {
+		if rate := m.RecordMove("runaway"); rate != float64(i) {
+			t.Errorf("Expected rate %d after %d moves, got %v", i, i, rate)
+		}
+	}
+}
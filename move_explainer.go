@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// explainMove asks the LLM for a one-sentence explanation of a move the bot just played.
+// moves is the move list from initialFEN before the move was played.
+func explainMove(cfg *BotConfig, moves []string, initialFEN, move string) (string, error) {
+	history, notation := promptMoveHistory(cfg, moves, initialFEN)
+	prompt := fmt.Sprintf("Moves so far (%s): %s\nIn one sentence, explain why you played %s in the context of this position.",
+		notation, history, move)
+
+	// Stop sequences are meant for move extraction and would cut the sentence short
+	req := openRouterRequest{
+		Model:    cfg.OpenRouterModel,
+		Messages: []openRouterMessage{{Role: "user", Content: prompt}},
+	}
+	explanation, err := callOpenRouter(cfg, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get explanation for %s: %v", move, err)
+	}
+	return truncateExplanation(explanation, cfg.MaxExplanationLength), nil
+}
+
+// truncateExplanation trims whitespace and shortens the text to maxLen characters,
+// ending in "..." when it had to be cut (maxLen <= 0 means no limit)
+func truncateExplanation(text string, maxLen int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if maxLen <= 0 || len(runes) <= maxLen {
+		return text
+	}
+	if maxLen <= 3 {
+		return string(runes[:maxLen])
+	}
+	return strings.TrimSpace(string(runes[:maxLen-3])) + "..."
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTruncateExplanation(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxLen   int
+		expected string
+	}{
+		{"short text", "Controls the center.", 140, "Controls the center."},
+		{"newlines", "Develops\na   piece.", 140, "Develops a piece."},
+		{"truncated", "This move develops the knight toward the center", 20, "This move develop..."},
+		{"no limit", "Anything goes here", 0, "Anything goes here"},
+		{"tiny limit", "Checkmate", 2, "Ch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateExplanation(tt.text, tt.maxLen)
+			if got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+			if tt.maxLen > 0 && len([]rune(got)) > tt.maxLen {
+				t.Errorf("Result '%s' exceeds %d characters", got, tt.maxLen)
+			}
+		})
+	}
+}
+
+func TestExplainMove(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		prompt := req.Messages[0].Content
+		if !strings.Contains(prompt, "explain why you played g1f3") || !strings.Contains(prompt, "1. e2e4 e7e5") {
+			t.Errorf("Unexpected prompt '%s'", prompt)
+		}
+		if len(req.Stop) != 0 {
+			t.Errorf("Expected no stop sequences for explanations, got %q", req.Stop)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"The knight attacks e5 while developing toward the center."}}]}`))
+	})
+
+	cfg := &BotConfig{
+		OpenRouterAPIKey:         "test_key",
+		OpenRouterModel:          "openai/gpt-4o",
+		PromptIncludeMoveNumbers: true,
+		LLMStopSequences:         defaultLLMStopSequences,
+		MaxExplanationLength:     30,
+	}
+	explanation, err := explainMove(cfg, []string{"e2e4", "e7e5"}, "", "g1f3")
+	if err != nil {
+		t.Fatalf("explainMove() failed: %v", err)
+	}
+	if explanation != "The knight attacks e5 while..." {
+		t.Errorf("Unexpected explanation '%s'", explanation)
+	}
+}
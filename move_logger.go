@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const moveLogFlushInterval = 5 * time.Second
+
+var moveLogHeader = []string{
+	"timestamp", "game_id", "move_number", "color", "uci_move",
+	"fen_before", "llm_model", "latency_ms", "attempt",
+}
+
+// MoveRecord is a single row of the move CSV log
+type MoveRecord struct {
+	Timestamp  time.Time
+	GameID     string
+	MoveNumber int
+	Color      string
+	UCIMove    string
+	FENBefore  string
+	LLMModel   string
+	LatencyMS  int64
+	Attempt    int
+}
+
+// MoveLogger appends every move to a CSV file for offline analysis. Rows are
+// buffered and flushed periodically; the file is rotated when the day changes.
+type MoveLogger struct {
+	mu      sync.Mutex
+	file    *lumberjack.Logger
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	day     string
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewMoveLogger opens (or creates) the CSV file at path and starts the background flusher
+func NewMoveLogger(path string) (*MoveLogger, error) {
+	// Only a brand new file needs the header row
+	info, err := os.Stat(path)
+	needsHeader := err != nil || info.Size() == 0
+
+	file := &lumberjack.Logger{Filename: path, LocalTime: true}
+	buf := bufio.NewWriter(file)
+	l := &MoveLogger{
+		file:    file,
+		buf:     buf,
+		csv:     csv.NewWriter(buf),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		day:     time.Now().Format(time.DateOnly),
+	}
+	if needsHeader {
+		if err := l.csv.Write(moveLogHeader); err != nil {
+			return nil, err
+		}
+		if err := l.flushLocked(); err != nil {
+			return nil, err
+		}
+	}
+
+	go l.flushLoop()
+	return l, nil
+}
+
+// Log appends a move record, rotating the file first if a new day has started
+func (l *MoveLogger) Log(r MoveRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if day := r.Timestamp.Format(time.DateOnly); day != l.day {
+		if err := l.rotateLocked(); err != nil {
+			return err
+		}
+		l.day = day
+	}
+
+	return l.csv.Write([]string{
+		r.Timestamp.UTC().Format(time.RFC3339),
+		r.GameID,
+		strconv.Itoa(r.MoveNumber),
+		r.Color,
+		r.UCIMove,
+		r.FENBefore,
+		r.LLMModel,
+		strconv.FormatInt(r.LatencyMS, 10),
+		strconv.Itoa(r.Attempt),
+	})
+}
+
+// rotateLocked starts a new file (lumberjack keeps the old one as a backup) with a fresh header
+func (l *MoveLogger) rotateLocked() error {
+	if err := l.flushLocked(); err != nil {
+		return err
+	}
+	if err := l.file.Rotate(); err != nil {
+		return err
+	}
+	return l.csv.Write(moveLogHeader)
+}
+
+func (l *MoveLogger) flushLocked() error {
+	l.csv.Flush()
+	if err := l.csv.Error(); err != nil {
+		return err
+	}
+	return l.buf.Flush()
+}
+
+// Flush writes any buffered rows to disk
+func (l *MoveLogger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flushLocked()
+}
+
+func (l *MoveLogger) flushLoop() {
+	defer close(l.stopped)
+	ticker := time.NewTicker(moveLogFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.Flush()
+		}
+	}
+}
+
+// Close stops the background flusher, flushes remaining rows and closes the file
+func (l *MoveLogger) Close() error {
+	close(l.stop)
+	<-l.stopped
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return firstError(l.flushLocked(), l.file.Close())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMoveLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "moves.csv")
+	logger, err := NewMoveLogger(path)
+	if err != nil {
+		t.Fatalf("NewMoveLogger() failed: %v", err)
+	}
+
+	now := time.Now()
+	records := []MoveRecord{
+		{Timestamp: now, GameID: "g1", MoveNumber: 1, Color: "white", UCIMove: "e2e4", FENBefore: startFEN, LLMModel: "openai/gpt-4o", LatencyMS: 812, Attempt: 1},
+		{Timestamp: now, GameID: "g1", MoveNumber: 2, Color: "white", UCIMove: "g1f3", LLMModel: "openai/gpt-4o", LatencyMS: 1034, Attempt: 2},
+		{Timestamp: now, GameID: "g2", MoveNumber: 1, Color: "black", UCIMove: "c7c5", LLMModel: "anthropic/claude", LatencyMS: 95, Attempt: 1},
+	}
+	for _, r := range records {
+		if err := logger.Log(r); err != nil {
+			t.Fatalf("Log() failed: %v", err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open CSV: %v", err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	if len(rows) != len(records)+1 {
+		t.Fatalf("Expected %d rows including header, got %d", len(records)+1, len(rows))
+	}
+	for i, col := range moveLogHeader {
+		if rows[0][i] != col {
+			t.Errorf("Expected header column %d '%s', got '%s'", i, col, rows[0][i])
+		}
+	}
+	if rows[1][1] != "g1" || rows[1][4] != "e2e4" || rows[1][5] != startFEN || rows[1][7] != "812" {
+		t.Errorf("Unexpected first row %v", rows[1])
+	}
+	if rows[2][8] != "2" {
+		t.Errorf("Expected attempt '2', got '%s'", rows[2][8])
+	}
+
+	// Reopening an existing log must not write a second header
+	logger, err = NewMoveLogger(path)
+	if err != nil {
+		t.Fatalf("NewMoveLogger() reopen failed: %v", err)
+	}
+	logger.Log(records[0])
+	logger.Close()
+
+	content, _ := os.ReadFile(path)
+	f2, _ := os.Open(path)
+	defer f2.Close()
+	rows, _ = csv.NewReader(f2).ReadAll()
+	if len(rows) != len(records)+2 {
+		t.Errorf("Expected %d rows after reopening, got %d:\n%s", len(records)+2, len(rows), content)
+	}
+}
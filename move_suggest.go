@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Move suggestions are limited to suggestRateLimit per suggestRateWindow
+const (
+	suggestRateLimit  = 10
+	suggestRateWindow = time.Minute
+)
+
+// moveSuggestRequest is the JSON body of a move suggestion request
+type moveSuggestRequest struct {
+	Moves    []string `json:"moves"`
+	Model    string   `json:"model"`
+	Feedback string   `json:"feedback"`
+}
+
+// moveSuggestResponse is the answer to a move suggestion request
+type moveSuggestResponse struct {
+	Move      string `json:"move"`
+	Model     string `json:"model"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// MoveSuggester asks the LLM for a move in any position from the standard start,
+// without a running game
+type MoveSuggester struct {
+	cfg      *BotConfig
+	mu       sync.Mutex
+	requests []time.Time // start times of the requests within the rate window
+	now      func() time.Time
+}
+
+// NewMoveSuggester creates a suggester using the LLM settings of cfg
+func NewMoveSuggester(cfg *BotConfig) *MoveSuggester {
+	return &MoveSuggester{cfg: cfg, now: time.Now}
+}
+
+// allow records a request and reports whether it is within the rate limit
+func (s *MoveSuggester) allow() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	cutoff := now.Add(-suggestRateWindow)
+	recent := s.requests[:0]
+	for _, t := range s.requests {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	s.requests = recent
+	if len(s.requests) >= suggestRateLimit {
+		return false
+	}
+	s.requests = append(s.requests, now)
+	return true
+}
+
+// authorized reports whether r carries ADMIN_TOKEN as its bearer token
+func (s *MoveSuggester) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.cfg.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.AdminToken)) == 1
+}
+
+// Suggest asks model (OPENROUTER_MODEL when empty) for the next move after moves,
+// adding feedback to the prompt when it is set
+func (s *MoveSuggester) Suggest(moves []string, model, feedback string) (moveSuggestResponse, error) {
+	if model == "" {
+		model = s.cfg.OpenRouterModel
+	}
+	model = s.cfg.ResolveModel(model)
+	game := &Game{
+		ID:          "suggestion",
+		InitialFEN:  startposFEN,
+		StartedAt:   s.now(),
+		whiteStarts: true,
+		feedback:    feedback,
+		moves:       moves,
+		status:      "started",
+	}
+	game.Color = game.SideToMove()
+
+	start := time.Now()
+	move, err := getBestMoveFromLLM(s.cfg, game, model)
+	if err != nil {
+		return moveSuggestResponse{}, err
+	}
+	return moveSuggestResponse{Move: move, Model: model, LatencyMS: time.Since(start).Milliseconds()}, nil
+}
+
+// ServeHTTP implements GET /api/moves/suggest. The JSON body gives the moves played
+// so far, optionally the model and feedback on an earlier suggestion. Requests need
+// ADMIN_TOKEN as bearer token and are limited to 10 per minute.
+func (s *MoveSuggester) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !s.allow() {
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(suggestRateWindow.Seconds())))
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	var req moveSuggestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	for i, move := range req.Moves {
+		if !isLegalMove(req.Moves[:i], startposFEN, move) {
+			http.Error(w, fmt.Sprintf("illegal move '%s' at ply %d", move, i+1), http.StatusBadRequest)
+			return
+		}
+	}
+
+	suggestion, err := s.Suggest(req.Moves, req.Model, req.Feedback)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestion)
+}
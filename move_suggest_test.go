@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func suggestRequest(token, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/moves/suggest", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestMoveSuggester_ServeHTTP(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "openai/gpt-4o" {
+			t.Errorf("Expected the requested model, got '%s'", req.Model)
+		}
+		prompt := req.Messages[0].Content
+		if !strings.Contains(prompt, "as white") || !strings.Contains(prompt, "Feedback on your previous answer: avoid Bc4") {
+			t.Errorf("Expected a prompt for white with the feedback, got %q", prompt)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"g1f3"}}]}`))
+	})
+
+	suggester := NewMoveSuggester(&BotConfig{AdminToken: "secret", OpenRouterModel: "default/model", MaxIllegalMoveRetries: 1})
+	rec := httptest.NewRecorder()
+	suggester.ServeHTTP(rec, suggestRequest("secret", `{"moves":["e2e4","e7e5"],"model":"openai/gpt-4o","feedback":"avoid Bc4"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp moveSuggestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response %s: %v", rec.Body.String(), err)
+	}
+	if resp.Move != "g1f3" || resp.Model != "openai/gpt-4o" || resp.LatencyMS < 0 {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		code  int
+	}{
+		{"no token", "", `{"moves":[]}`, http.StatusUnauthorized},
+		{"wrong token", "guess", `{"moves":[]}`, http.StatusUnauthorized},
+		{"bad body", "secret", `moves`, http.StatusBadRequest},
+		{"illegal move", "secret", `{"moves":["e2e5"]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			suggester.ServeHTTP(rec, suggestRequest(tt.token, tt.body))
+			if rec.Code != tt.code {
+				t.Errorf("Expected status %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMoveSuggester_RateLimit(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	suggester := NewMoveSuggester(&BotConfig{AdminToken: "secret", DisableLLM: true, MaxIllegalMoveRetries: 1})
+	suggester.now = func() time.Time { return now }
+
+	for i := 0; i < suggestRateLimit; i++ {
+		rec := httptest.NewRecorder()
+		suggester.ServeHTTP(rec, suggestRequest("secret", `{"moves":["d2d4"]}`))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected request %d to succeed, got %d: %s", i+1, rec.Code, rec.Body.String())
+		}
+	}
+	rec := httptest.NewRecorder()
+	suggester.ServeHTTP(rec, suggestRequest("secret", `{"moves":["d2d4"]}`))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 over the limit, got %d", rec.Code)
+	}
+
+	now = now.Add(suggestRateWindow)
+	rec = httptest.NewRecorder()
+	suggester.ServeHTTP(rec, suggestRequest("secret", `{"moves":["d2d4"]}`))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected requests to be allowed again after a minute, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// validatorOverrideConfidence is the confidence (0-100) above which the validator's
+// move replaces the primary model's move
+const validatorOverrideConfidence = 50
+
+// MoveValidation is the validator model's verdict on a proposed move
+type MoveValidation struct {
+	Move       string
+	Confidence int // 0-100, how sure the validator is that its move is better
+}
+
+// validateMove asks the validator model to double-check the move proposed by the primary
+// model after moves from initialFEN
+func validateMove(cfg *BotConfig, moves []string, initialFEN, proposed string) (MoveValidation, error) {
+	history, notation := promptMoveHistory(cfg, moves, initialFEN)
+	prompt := fmt.Sprintf("Moves so far (%s): %s\n"+
+		"Another engine suggests playing %s. Check this move. Reply on a single line with the best "+
+		"move in UCI notation followed by your confidence from 0 to 100 that it is better than %s, "+
+		"for example \"e2e4 70\". If %s is best, reply with %s and confidence 0.",
+		notation, history, proposed, proposed, proposed, proposed)
+
+	req := openRouterRequest{
+		Model:    cfg.ValidatorModel,
+		Messages: []openRouterMessage{{Role: "user", Content: prompt}},
+	}
+	content, err := callOpenRouter(cfg, req)
+	if err != nil {
+		return MoveValidation{}, fmt.Errorf("validator model %s failed: %v", cfg.ValidatorModel, err)
+	}
+	return parseMoveValidation(content)
+}
+
+// parseMoveValidation parses a "<move> <confidence>" validator reply
+func parseMoveValidation(content string) (MoveValidation, error) {
+	fields := strings.Fields(strings.TrimSpace(content))
+	if len(fields) < 2 {
+		return MoveValidation{}, fmt.Errorf("unexpected validator reply '%s'", content)
+	}
+
+	confidence, err := strconv.Atoi(strings.TrimSuffix(fields[1], "%"))
+	if err != nil || confidence < 0 || confidence > 100 {
+		return MoveValidation{}, fmt.Errorf("invalid validator confidence in reply '%s'", content)
+	}
+	move := strings.ToLower(fields[0])
+	if !isUCIMove(move) {
+		return MoveValidation{}, fmt.Errorf("no UCI move in validator reply '%s'", content)
+	}
+	return MoveValidation{Move: move, Confidence: confidence}, nil
+}
+
+// chooseValidatedMove returns the move to play given the primary model's move and the
+// validator's verdict, and whether the validator overrode the primary model. The
+// validator's move only wins if it is legal after moves from initialFEN.
+func chooseValidatedMove(moves []string, initialFEN, proposed string, validation MoveValidation) (string, bool) {
+	if validation.Move == "" || validation.Move == proposed || validation.Confidence <= validatorOverrideConfidence {
+		return proposed, false
+	}
+	if !isLegalMove(moves, initialFEN, validation.Move) {
+		log.Printf("Ignoring illegal validator move %s (confidence %d), keeping %s", validation.Move, validation.Confidence, proposed)
+		return proposed, false
+	}
+	log.Printf("Validator overrode move %s with %s (confidence %d)", proposed, validation.Move, validation.Confidence)
+	botMetrics.RecordValidationOverride()
+	return validation.Move, true
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestParseMoveValidation(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected MoveValidation
+		wantErr  bool
+	}{
+		{"d2d4 80", MoveValidation{Move: "d2d4", Confidence: 80}, false},
+		{"  E2E4 0\n", MoveValidation{Move: "e2e4", Confidence: 0}, false},
+		{"g1f3 65%", MoveValidation{Move: "g1f3", Confidence: 65}, false},
+		{"e2e4", MoveValidation{}, true},
+		{"e2e4 high", MoveValidation{}, true},
+		{"e2e4 150", MoveValidation{}, true},
+		{"Nf3 90", MoveValidation{}, true},
+		{"resign 100", MoveValidation{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMoveValidation(tt.content)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Expected error for '%s', got %+v", tt.content, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("parseMoveValidation(%q) = %+v, %v; expected %+v", tt.content, got, err, tt.expected)
+		}
+	}
+}
+
+func TestMoveValidator_TwoModelInteraction(t *testing.T) {
+	// The primary model suggests a weak move, the validator is confident in a better one
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		reply := "a2a3"
+		if req.Model == "openai/gpt-4o-mini" {
+			reply = "d2d4 85"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	})
+
+	cfg := &BotConfig{
+		OpenRouterAPIKey: "test_key",
+		OpenRouterModel:  "openai/gpt-4o",
+		ValidatorModel:   "openai/gpt-4o-mini",
+	}
+
+	proposed, err := callOpenRouter(cfg, newOpenRouterRequest(cfg, cfg.OpenRouterModel, nil, 0))
+	if err != nil {
+		t.Fatalf("Primary model call failed: %v", err)
+	}
+
+	validation, err := validateMove(cfg, []string{"e2e4", "e7e5"}, "", proposed)
+	if err != nil {
+		t.Fatalf("validateMove() failed: %v", err)
+	}
+
+	overridesBefore := botMetrics.ValidationOverrides()
+	move, overridden := chooseValidatedMove([]string{"e2e4", "e7e5"}, "", proposed, validation)
+	if move != "d2d4" || !overridden {
+		t.Errorf("Expected validator move 'd2d4' to be chosen, got '%s' (overridden=%v)", move, overridden)
+	}
+	if botMetrics.ValidationOverrides() != overridesBefore+1 {
+		t.Error("Expected validation override to be counted in metrics")
+	}
+}
+
+func TestChooseValidatedMove_KeepsPrimary(t *testing.T) {
+	tests := []struct {
+		name       string
+		validation MoveValidation
+	}{
+		{"validator agrees", MoveValidation{Move: "e2e4", Confidence: 90}},
+		{"low confidence", MoveValidation{Move: "d2d4", Confidence: 40}},
+		{"no verdict", MoveValidation{}},
+		{"illegal validator move", MoveValidation{Move: "e2e5", Confidence: 95}},
+		{"validator move for the wrong side", MoveValidation{Move: "e7e5", Confidence: 95}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if move, overridden := chooseValidatedMove(nil, "", "e2e4", tt.validation); move != "e2e4" || overridden {
+				t.Errorf("Expected primary move to be kept, got '%s' (overridden=%v)", move, overridden)
+			}
+		})
+	}
+}
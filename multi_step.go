@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// multiStepCandidates is the number of candidate moves requested in the first step
+const multiStepCandidates = 3
+
+// CandidateMove is one of the moves proposed in the first multi-step call
+type CandidateMove struct {
+	Move          string
+	Justification string
+}
+
+// getMultiStepMove asks the model for candidate moves after moves from initialFEN and
+// then for the best of them. It returns the chosen move together with the candidates
+// it was picked from.
+func getMultiStepMove(cfg *BotConfig, model string, moves []string, initialFEN string) (string, []CandidateMove, error) {
+	history, notation := promptMoveHistory(cfg, moves, initialFEN)
+
+	candidatePrompt := fmt.Sprintf("Moves so far (%s): %s\n"+
+		"List the %d most promising moves for the side to move, one per line, each in UCI notation "+
+		"followed by \" - \" and a brief justification.", notation, history, multiStepCandidates)
+	messages := []openRouterMessage{{Role: "user", Content: candidatePrompt}}
+
+	// Both replies span several lines, so the usual stop sequences cannot be used
+	content, err := callOpenRouter(cfg, openRouterRequest{Model: model, Messages: messages})
+	if err != nil {
+		return "", nil, fmt.Errorf("candidate step failed: %v", err)
+	}
+	candidates, err := parseCandidateMoves(content)
+	if err != nil {
+		return "", nil, err
+	}
+
+	messages = append(messages,
+		openRouterMessage{Role: "assistant", Content: content},
+		openRouterMessage{Role: "user", Content: "Considering tactics (checks, captures and threats) for both sides, " +
+			"which of these candidates is best? Reply with only that move in UCI notation."})
+	content, err = callOpenRouter(cfg, openRouterRequest{Model: model, Messages: messages})
+	if err != nil {
+		return "", candidates, fmt.Errorf("selection step failed: %v", err)
+	}
+
+	move, err := parseSelectedMove(content, candidates)
+	return move, candidates, err
+}
+
+// parseCandidateMoves reads "<move> - <justification>" lines, tolerating list
+// numbering and other decoration. Lines without a UCI move are ignored.
+func parseCandidateMoves(content string) ([]CandidateMove, error) {
+	var candidates []CandidateMove
+	seen := map[string]bool{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			move := strings.ToLower(strings.Trim(field, "*`.:,;()"))
+			if !isUCIMove(move) {
+				continue
+			}
+			if !seen[move] {
+				seen[move] = true
+				justification := strings.TrimSpace(strings.Join(fields[i+1:], " "))
+				justification = strings.TrimSpace(strings.TrimLeft(justification, "-:–"))
+				candidates = append(candidates, CandidateMove{Move: move, Justification: justification})
+			}
+			break
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no candidate moves found in reply '%s'", content)
+	}
+	return candidates, nil
+}
+
+// parseSelectedMove returns the first candidate move mentioned in the selection reply
+func parseSelectedMove(content string, candidates []CandidateMove) (string, error) {
+	for _, field := range strings.Fields(content) {
+		move := strings.ToLower(strings.Trim(field, "*`.:,;()\"'"))
+		for _, c := range candidates {
+			if c.Move == move {
+				return move, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("selection reply '%s' does not name one of the candidates", content)
+}
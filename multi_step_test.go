@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestParseCandidateMoves(t *testing.T) {
+	content := "Here are my candidates:\n" +
+		"1. g1f3 - develops the knight and controls e5\n" +
+		"2. **d2d4** - grabs the centre\n" +
+		"3. f1c4: eyes f7\n" +
+		"4. g1f3 - repeated, should be ignored"
+
+	candidates, err := parseCandidateMoves(content)
+	if err != nil {
+		t.Fatalf("parseCandidateMoves() failed: %v", err)
+	}
+
+	expected := []CandidateMove{
+		{"g1f3", "develops the knight and controls e5"},
+		{"d2d4", "grabs the centre"},
+		{"f1c4", "eyes f7"},
+	}
+	if len(candidates) != len(expected) {
+		t.Fatalf("Expected %d candidates, got %+v", len(expected), candidates)
+	}
+	for i := range expected {
+		if candidates[i] != expected[i] {
+			t.Errorf("Candidate %d: expected %+v, got %+v", i, expected[i], candidates[i])
+		}
+	}
+
+	if _, err := parseCandidateMoves("I like the knight move."); err == nil {
+		t.Error("Expected error for reply without moves, but got nil")
+	}
+}
+
+func TestParseSelectedMove(t *testing.T) {
+	candidates := []CandidateMove{{Move: "g1f3"}, {Move: "d2d4"}}
+
+	tests := []struct {
+		content string
+		move    string
+		wantErr bool
+	}{
+		{"d2d4", "d2d4", false},
+		{"The best move is **G1F3**.", "g1f3", false},
+		{"e2e4", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		move, err := parseSelectedMove(tt.content, candidates)
+		if (err != nil) != tt.wantErr || move != tt.move {
+			t.Errorf("parseSelectedMove(%q) = '%s', %v; expected '%s' (wantErr %v)", tt.content, move, err, tt.move, tt.wantErr)
+		}
+	}
+}
+
+func TestGetMultiStepMove(t *testing.T) {
+	calls := 0
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		reply := "1. g1f3 - develops\n2. d2d4 - centre\n3. b1c3 - develops"
+		if calls == 2 {
+			if len(req.Messages) != 3 || req.Messages[1].Role != "assistant" {
+				t.Errorf("Expected selection step to include the candidate reply, got %+v", req.Messages)
+			}
+			reply = "d2d4"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	})
+
+	move, candidates, err := getMultiStepMove(&BotConfig{}, "openai/gpt-4o", []string{"e2e4", "e7e5"}, "")
+	if err != nil {
+		t.Fatalf("getMultiStepMove() failed: %v", err)
+	}
+	if move != "d2d4" || len(candidates) != 3 || calls != 2 {
+		t.Errorf("Unexpected result move=%s candidates=%v calls=%d", move, candidates, calls)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// ScanNDJSON is a bufio.SplitFunc that returns one JSON object per token. Unlike
+// bufio.ScanLines it does not depend on newlines: objects written back to back
+// are split apart, and an object split across reads is buffered until its closing
+// brace arrives. Whitespace between objects, including keep-alive newlines, is skipped.
+// A line that does not start with '{', or that ends before its object is closed,
+// is returned as is so the caller can report it.
+func ScanNDJSON(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := 0
+	for start < len(data) && isJSONSpace(data[start]) {
+		start++
+	}
+	if start == len(data) {
+		return start, nil, nil
+	}
+
+	if data[start] != '{' {
+		if i := bytes.IndexByte(data[start:], '\n'); i >= 0 {
+			return start + i + 1, bytes.TrimRight(data[start:start+i], "\r"), nil
+		}
+		if atEOF {
+			return len(data), data[start:], nil
+		}
+		return start, nil, nil
+	}
+
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\n':
+			// NDJSON objects never span lines, so this one was cut off (for example by
+			// a reconnect); hand it over as is rather than merging it with the next
+			return i + 1, bytes.TrimRight(data[start:i], "\r"), nil
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i + 1, data[start : i+1], nil
+			}
+		}
+	}
+
+	if atEOF {
+		return 0, nil, fmt.Errorf("stream ended inside a JSON object: %q", data[start:])
+	}
+	// Request more data, keeping the partial object
+	return start, nil, nil
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// maxNDJSONEventSize bounds a single event; gameFull events of long games exceed
+// bufio.Scanner's default 64 KiB token limit
+const maxNDJSONEventSize = 1 << 20
+
+// newNDJSONScanner returns a scanner over an NDJSON stream split with ScanNDJSON
+func newNDJSONScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONEventSize)
+	scanner.Split(ScanNDJSON)
+	return scanner
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// chunkedReader returns at most size bytes per Read, like a stream arriving in small TCP segments
+type chunkedReader struct {
+	data string
+	size int
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, io.EOF
+	}
+	n := r.size
+	if n > len(p) {
+		n = len(p)
+	}
+	if n > len(r.data) {
+		n = len(r.data)
+	}
+	copy(p, r.data[:n])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func scanAll(t *testing.T, r io.Reader) ([]string, error) {
+	t.Helper()
+	scanner := newNDJSONScanner(r)
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	return tokens, scanner.Err()
+}
+
+func TestScanNDJSON_ChunkSizes(t *testing.T) {
+	// Two objects back to back without a newline, keep-alives, and braces and
+	// quotes inside strings
+	stream := `{"type":"gameState","moves":"e2e4"}{"type":"chatLine","text":"nice {move} \"gg\""}` + "\n\n" +
+		`{"type":"gameFull","state":{"moves":"","clock":[1,2]}}` + "\r\n\n" +
+		`{"type":"gameState","moves":"e2e4 e7e5"}` + "\n"
+	expected := []string{
+		`{"type":"gameState","moves":"e2e4"}`,
+		`{"type":"chatLine","text":"nice {move} \"gg\""}`,
+		`{"type":"gameFull","state":{"moves":"","clock":[1,2]}}`,
+		`{"type":"gameState","moves":"e2e4 e7e5"}`,
+	}
+
+	for _, size := range []int{1, 2, 3, 7, 16, 64, 4096} {
+		tokens, err := scanAll(t, &chunkedReader{data: stream, size: size})
+		if err != nil {
+			t.Fatalf("chunk size %d: unexpected error %v", size, err)
+		}
+		if strings.Join(tokens, "|") != strings.Join(expected, "|") {
+			t.Errorf("chunk size %d: expected %q, got %q", size, expected, tokens)
+		}
+	}
+}
+
+func TestScanNDJSON_NonJSONLine(t *testing.T) {
+	tokens, err := scanAll(t, strings.NewReader("not json\n{\"a\":1}\ntrailing"))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expected := []string{"not json", `{"a":1}`, "trailing"}
+	if strings.Join(tokens, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, tokens)
+	}
+}
+
+func TestScanNDJSON_TruncatedObject(t *testing.T) {
+	tokens, err := scanAll(t, &chunkedReader{data: `{"a":1}` + "\n" + `{"type":"gameState","mov`, size: 5})
+	if len(tokens) != 1 || tokens[0] != `{"a":1}` {
+		t.Errorf("Expected the complete object before the error, got %q", tokens)
+	}
+	if err == nil || !strings.Contains(err.Error(), "inside a JSON object") {
+		t.Errorf("Expected an error for the truncated object, got %v", err)
+	}
+}
+
+func TestScanNDJSON_CutOffObjectEndsAtNewline(t *testing.T) {
+	// ReconnectingReader terminates an object cut off by a disconnect with a newline
+	tokens, err := scanAll(t, strings.NewReader(`{"n":3}`+"\n"+`{"n":`+"\n"+`{"n":4}`+"\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expected := []string{`{"n":3}`, `{"n":`, `{"n":4}`}
+	if strings.Join(tokens, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, tokens)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// openRouterAPIURL is the chat completions endpoint (a variable so tests can point it elsewhere)
+var openRouterAPIURL = "https://openrouter.ai/api/v1/chat/completions"
+
+// openRouterHTTPClient is used for all OpenRouter requests
+var openRouterHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+// openRouterMessage is a single chat message in an OpenRouter request
+type openRouterMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openRouterRequest is the body of an OpenRouter chat completion request
+type openRouterRequest struct {
+	Model          string                    `json:"model"`
+	Messages       []openRouterMessage       `json:"messages"`
+	Stop           []string                  `json:"stop,omitempty"`
+	Temperature    *float64                  `json:"temperature,omitempty"`
+	ResponseFormat *openRouterResponseFormat `json:"response_format,omitempty"`
+}
+
+// openRouterResponseFormat requests structured output from models that support it
+type openRouterResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// openRouterResponse is the part of the chat completion response the bot uses
+type openRouterResponse struct {
+	Choices []struct {
+		Message openRouterMessage `json:"message"`
+	} `json:"choices"`
+	Usage openRouterUsage `json:"usage"`
+}
+
+// newOpenRouterRequest builds a chat completion request for the given model and messages
+// using the request settings from the bot configuration. attempt is 0 for the first
+// query of a move and increases with every retry after an invalid move.
+func newOpenRouterRequest(cfg *BotConfig, model string, messages []openRouterMessage, attempt int) openRouterRequest {
+	req := openRouterRequest{
+		Model:    model,
+		Messages: messages,
+		Stop:     cfg.LLMStopSequences,
+	}
+	// Stop sequences would cut a step-by-step answer off after its first line
+	if cfg.LLMChainOfThought {
+		req.Stop = nil
+	}
+	// ...and a JSON object at its first space
+	if cfg.LLMResponseFormatJSON {
+		req.Stop = nil
+		req.ResponseFormat = &openRouterResponseFormat{Type: "json_object"}
+	}
+	// Retries use a higher temperature, the first attempt keeps the model default
+	if attempt > 0 {
+		temperature := cfg.LLMFallbackTemperature
+		req.Temperature = &temperature
+	}
+	return req
+}
+
+// callOpenRouter sends a chat completion request and returns the content of the first choice
+func callOpenRouter(cfg *BotConfig, request openRouterRequest) (string, error) {
+	model, err := tokenBudget.ModelFor(request.Model, cfg.MonthlyBudgetUSD, cfg.ConfiguredModels())
+	if err != nil {
+		return "", err
+	}
+	request.Model = model
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal OpenRouter request: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, openRouterAPIURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create OpenRouter request: %v", err)
+	}
+	for key, value := range cfg.LLMExtraHeaders {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.OpenRouterAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := openRouterHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("OpenRouter request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("OpenRouter returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var result openRouterResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode OpenRouter response: %v", err)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("OpenRouter response contained no choices")
+	}
+
+	content := result.Choices[0].Message.Content
+	tokenBudget.Record(request.Model, result.Usage)
+	slog.Info("LLM call", llmLogAttrs(cfg.VerboseLLMLogs, request, content, time.Since(start))...)
+	return content, nil
+}
+
+// llmLogAttrs returns the structured log fields for an LLM call. Prompts run to
+// several kilobytes per move, so the full prompt and response are only included
+// when VERBOSE_LLM_LOGGING is enabled.
+func llmLogAttrs(verbose bool, request openRouterRequest, content string, latency time.Duration) []any {
+	attrs := []any{
+		"model", request.Model,
+		"messages", len(request.Messages),
+		"latency_ms", latency.Milliseconds(),
+	}
+	if verbose {
+		prompt, _ := json.Marshal(request.Messages)
+		attrs = append(attrs, "prompt", string(prompt), "response", content)
+	}
+	return attrs
+}
+
+// preloadModels sends a minimal query to each model concurrently and logs the latency.
+// It returns once all queries have finished; failures are only logged.
+func preloadModels(cfg *BotConfig, models []string) {
+	var wg sync.WaitGroup
+	for _, model := range models {
+		wg.Add(1)
+		go func(model string) {
+			defer wg.Done()
+
+			start := time.Now()
+			req := openRouterRequest{
+				Model:    model,
+				Messages: []openRouterMessage{{Role: "user", Content: "What is 1+1?"}},
+			}
+			if _, err := callOpenRouter(cfg, req); err != nil {
+				log.Printf("Preload of model %s failed after %v: %v", model, time.Since(start), err)
+				return
+			}
+			log.Printf("Preloaded model %s in %v", model, time.Since(start))
+		}(model)
+	}
+	wg.Wait()
+}
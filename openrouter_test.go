@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewOpenRouterRequest_StopSequences(t *testing.T) {
+	cfg := &BotConfig{LLMStopSequences: defaultLLMStopSequences}
+	req := newOpenRouterRequest(cfg, "openai/gpt-4o", []openRouterMessage{
+		{Role: "user", Content: "Your move"},
+	}, 0)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal request body: %v", err)
+	}
+
+	stop, ok := decoded["stop"].([]interface{})
+	if !ok {
+		t.Fatalf("Expected 'stop' array in request body, got %s", body)
+	}
+	if len(stop) != 2 || stop[0] != "\n" || stop[1] != " " {
+		t.Errorf("Expected stop sequences [\"\\n\", \" \"], got %v", stop)
+	}
+}
+
+func TestNewOpenRouterRequest_NoStopSequences(t *testing.T) {
+	req := newOpenRouterRequest(&BotConfig{}, "openai/gpt-4o", nil, 0)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal request body: %v", err)
+	}
+	if _, exists := decoded["stop"]; exists {
+		t.Errorf("Expected no 'stop' field when no sequences are configured, got %s", body)
+	}
+}
+
+func TestNewOpenRouterRequest_ResponseFormatJSON(t *testing.T) {
+	cfg := &BotConfig{LLMStopSequences: []string{" "}, LLMResponseFormatJSON: true}
+	body, err := json.Marshal(newOpenRouterRequest(cfg, "openai/gpt-4o", nil, 0))
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	json.Unmarshal(body, &decoded)
+	format, _ := decoded["response_format"].(map[string]interface{})
+	if format["type"] != "json_object" {
+		t.Errorf("Expected response_format json_object, got %s", body)
+	}
+	if _, exists := decoded["stop"]; exists {
+		t.Errorf("Expected no stop sequences in JSON mode, got %s", body)
+	}
+}
+
+func TestNewOpenRouterRequest_ChainOfThoughtDropsStop(t *testing.T) {
+	cfg := &BotConfig{LLMStopSequences: []string{"\n"}, LLMChainOfThought: true}
+	req := newOpenRouterRequest(cfg, "openai/gpt-4o", nil, 0)
+	if req.Stop != nil {
+		t.Errorf("Expected no stop sequences with chain-of-thought, got %v", req.Stop)
+	}
+}
+
+func TestNewOpenRouterRequest_FallbackTemperature(t *testing.T) {
+	cfg := &BotConfig{LLMFallbackTemperature: defaultLLMFallbackTemperature}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		body, err := json.Marshal(newOpenRouterRequest(cfg, "openai/gpt-4o", nil, attempt))
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Fatalf("Failed to unmarshal request body: %v", err)
+		}
+
+		temperature, exists := decoded["temperature"]
+		if attempt == 0 {
+			if exists {
+				t.Errorf("Expected no temperature on first attempt, got %v", temperature)
+			}
+			continue
+		}
+		if temperature != defaultLLMFallbackTemperature {
+			t.Errorf("Expected temperature %v on attempt %d, got %v", defaultLLMFallbackTemperature, attempt, temperature)
+		}
+	}
+}
+
+// withOpenRouterServer points the OpenRouter client at a test server for the duration of a test
+func withOpenRouterServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	originalURL := openRouterAPIURL
+	openRouterAPIURL = server.URL
+	t.Cleanup(func() {
+		openRouterAPIURL = originalURL
+		server.Close()
+	})
+}
+
+func TestCallOpenRouter(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test_key" {
+			t.Errorf("Unexpected Authorization header '%s'", auth)
+		}
+		var req openRouterRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if req.Model != "openai/gpt-4o" {
+			t.Errorf("Expected model 'openai/gpt-4o', got '%s'", req.Model)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e2e4"}}]}`))
+	})
+
+	cfg := &BotConfig{OpenRouterAPIKey: "test_key"}
+	content, err := callOpenRouter(cfg, newOpenRouterRequest(cfg, "openai/gpt-4o", nil, 0))
+	if err != nil {
+		t.Fatalf("callOpenRouter() failed: %v", err)
+	}
+	if content != "e2e4" {
+		t.Errorf("Expected content 'e2e4', got '%s'", content)
+	}
+}
+
+func TestCallOpenRouter_ErrorStatus(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"No auth credentials found"}}`))
+	})
+
+	cfg := &BotConfig{OpenRouterAPIKey: "bad_key"}
+	if _, err := callOpenRouter(cfg, newOpenRouterRequest(cfg, "openai/gpt-4o", nil, 0)); err == nil {
+		t.Error("Expected error for unauthorized response, but got nil")
+	}
+}
+
+func TestPreloadModels(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		seen[req.Model] = true
+		mu.Unlock()
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"2"}}]}`))
+	})
+
+	cfg := &BotConfig{
+		OpenRouterAPIKey:   "test_key",
+		OpenRouterModel:    "openai/gpt-4o",
+		SimulateWhiteModel: "openai/gpt-4o",
+		SimulateBlackModel: "anthropic/claude-3-5-sonnet",
+	}
+	preloadModels(cfg, cfg.ConfiguredModels())
+
+	if len(seen) != 2 || !seen["openai/gpt-4o"] || !seen["anthropic/claude-3-5-sonnet"] {
+		t.Errorf("Expected each configured model to be queried once, got %v", seen)
+	}
+}
+
+func TestCallOpenRouter_ExtraHeaders(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Tenant-ID"); got != "chess-team" {
+			t.Errorf("Expected X-Tenant-ID 'chess-team', got '%s'", got)
+		}
+		if got := r.Header.Get("X-Gateway-Key"); got != "secret" {
+			t.Errorf("Expected X-Gateway-Key 'secret', got '%s'", got)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test_key" {
+			t.Errorf("Extra headers must not replace Authorization, got '%s'", auth)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e2e4"}}]}`))
+	})
+
+	cfg := &BotConfig{
+		OpenRouterAPIKey: "test_key",
+		LLMExtraHeaders: map[string]string{
+			"X-Tenant-ID":   "chess-team",
+			"X-Gateway-Key": "secret",
+			"Authorization": "Bearer should_be_ignored",
+		},
+	}
+	if _, err := callOpenRouter(cfg, newOpenRouterRequest(cfg, "openai/gpt-4o", nil, 0)); err != nil {
+		t.Fatalf("callOpenRouter() failed: %v", err)
+	}
+}
+
+func TestLLMLogAttrs(t *testing.T) {
+	req := openRouterRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []openRouterMessage{{Role: "user", Content: "Moves so far: e2e4. Your move?"}},
+	}
+
+	attrs := func(verbose bool) map[string]interface{} {
+		list := llmLogAttrs(verbose, req, "e7e5", 1500*time.Millisecond)
+		m := map[string]interface{}{}
+		for i := 0; i+1 < len(list); i += 2 {
+			m[list[i].(string)] = list[i+1]
+		}
+		return m
+	}
+
+	quiet := attrs(false)
+	if quiet["latency_ms"] != int64(1500) || quiet["messages"] != 1 || quiet["model"] != "openai/gpt-4o" {
+		t.Errorf("Unexpected summary fields %v", quiet)
+	}
+	if _, ok := quiet["prompt"]; ok {
+		t.Error("Expected no prompt field without verbose logging")
+	}
+
+	verbose := attrs(true)
+	prompt, _ := verbose["prompt"].(string)
+	if !strings.Contains(prompt, "Your move?") || verbose["response"] != "e7e5" {
+		t.Errorf("Expected full prompt and response with verbose logging, got %v", verbose)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+// Playing styles selectable with PERSONALITY
+const (
+	PersonalityAggressive        = "aggressive"
+	PersonalityPositional        = "positional"
+	PersonalityEndgameSpecialist = "endgame-specialist"
+	PersonalityRomantic          = "romantic"
+)
+
+// adventurousRatingGap is how much lower-rated an opponent must be for the bot
+// to switch to the romantic style for that game
+const adventurousRatingGap = 200
+
+// personalityPrompts are the system prompts sent alongside the move prompt for each style
+var personalityPrompts = map[string]string{
+	PersonalityAggressive: "You are an aggressive chess player. Prefer moves that attack the enemy king, " +
+		"seize the initiative and create threats, even at the cost of some material.",
+	PersonalityPositional: "You are a positional chess player. Prefer solid moves that improve your " +
+		"pieces, control key squares and avoid weaknesses in your pawn structure.",
+	PersonalityEndgameSpecialist: "You are an endgame specialist. Prefer trading pieces when it leads to " +
+		"favourable endgames, and play precise technical moves once material is reduced.",
+	PersonalityRomantic: "You play in the romantic style of the 19th century masters. Prefer bold " +
+		"gambits, sacrifices and open positions full of tactics.",
+}
+
+// isValidPersonality reports whether p names one of the configured playing styles
+func isValidPersonality(p string) bool {
+	_, ok := personalityPrompts[p]
+	return ok
+}
+
+// gamePersonality picks the style for a game from the ratings of its players (0 when
+// unknown): the configured personality, switched to romantic against clearly
+// lower-rated opponents. An empty result means no style prompt.
+func gamePersonality(configured string, bot, opponent int) string {
+	if configured == "" {
+		return ""
+	}
+	if bot > 0 && opponent > 0 && bot-opponent >= adventurousRatingGap {
+		return PersonalityRomantic
+	}
+	return configured
+}
+
+// withPersonality prepends the personality's system prompt to the conversation
+func withPersonality(messages []openRouterMessage, personality string) []openRouterMessage {
+	prompt, ok := personalityPrompts[personality]
+	if !ok {
+		return messages
+	}
+	return append([]openRouterMessage{{Role: "system", Content: prompt}}, messages...)
+}
@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestGamePersonality(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		bot        int
+		opponent   int
+		expected   string
+	}{
+		{"no personality configured", "", 2000, 1500, ""},
+		{"similar ratings", PersonalityPositional, 1800, 1750, PersonalityPositional},
+		{"stronger opponent", PersonalityPositional, 1500, 2000, PersonalityPositional},
+		{"much weaker opponent", PersonalityPositional, 2000, 1800, PersonalityRomantic},
+		{"unknown ratings", PersonalityAggressive, 0, 0, PersonalityAggressive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gamePersonality(tt.configured, tt.bot, tt.opponent); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithPersonality(t *testing.T) {
+	messages := []openRouterMessage{{Role: "user", Content: "Your move"}}
+
+	withStyle := withPersonality(messages, PersonalityAggressive)
+	if len(withStyle) != 2 || withStyle[0].Role != "system" || withStyle[0].Content != personalityPrompts[PersonalityAggressive] {
+		t.Errorf("Expected personality system prompt first, got %+v", withStyle)
+	}
+	if withStyle[1] != messages[0] {
+		t.Errorf("Expected original message to follow, got %+v", withStyle[1])
+	}
+
+	if plain := withPersonality(messages, ""); len(plain) != 1 {
+		t.Errorf("Expected messages unchanged without a personality, got %+v", plain)
+	}
+}
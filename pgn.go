@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// pgnTagLines renders tags as PGN header lines ([Name "value"]) in sorted order.
+// Tag names may only contain letters, digits and underscores; other characters are dropped.
+func pgnTagLines(tags map[string]string) string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		tagName := sanitizePGNTagName(name)
+		if tagName == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("[%s \"%s\"]\n", tagName, escapePGNValue(tags[name])))
+	}
+	return sb.String()
+}
+
+// sanitizePGNTagName keeps only the characters allowed in PGN tag names
+func sanitizePGNTagName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+// escapePGNValue escapes backslashes and quotes in a PGN tag value
+func escapePGNValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(value)
+}
+
+// pgnRosterTags are the Seven Tag Roster, written first and in this order
+var pgnRosterTags = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// formatPGN renders UCI moves played from initialFEN (the standard starting position
+// when empty or "startpos") as a PGN game. The roster tags come first, the other tags
+// follow in sorted order, and the movetext ends with the Result tag ("*" when unset).
+// Games from a custom position get SetUp and FEN tags.
+func formatPGN(tags map[string]string, moves []string, initialFEN string) (string, error) {
+	start, err := positionAfter(nil, initialFEN)
+	if err != nil {
+		return "", err
+	}
+	withSAN, err := movesWithSAN(moves, initialFEN)
+	if err != nil {
+		return "", err
+	}
+
+	others := make(map[string]string, len(tags))
+	for name, value := range tags {
+		others[name] = value
+	}
+	if initialFEN != "" && initialFEN != startposFEN {
+		others["SetUp"], others["FEN"] = "1", initialFEN
+	}
+	result := others["Result"]
+	if !pgnResults[result] {
+		result = "*"
+	}
+	others["Result"] = result
+
+	var sb strings.Builder
+	for _, name := range pgnRosterTags {
+		value, ok := others[name]
+		if !ok {
+			value = "?"
+		}
+		delete(others, name)
+		sb.WriteString(fmt.Sprintf("[%s \"%s\"]\n", name, escapePGNValue(value)))
+	}
+	sb.WriteString(pgnTagLines(others))
+	sb.WriteString("\n")
+
+	number, white := start.FullMoves, start.WhiteToMove
+	if number < 1 {
+		number = 1
+	}
+	for i, move := range withSAN {
+		switch {
+		case white:
+			sb.WriteString(fmt.Sprintf("%d. ", number))
+		case i == 0:
+			sb.WriteString(fmt.Sprintf("%d... ", number))
+		}
+		sb.WriteString(move.SAN + " ")
+		if !white {
+			number++
+		}
+		white = !white
+	}
+	sb.WriteString(result + "\n")
+	return sb.String(), nil
+}
+
+// pgnResult returns the PGN result for a Lichess game status and winner
+// ("white", "black" or empty), "*" while the game is running or after an abort
+func pgnResult(status, winner string) string {
+	switch gameOutcome(status, winner, "white") {
+	case OutcomeWin:
+		return "1-0"
+	case OutcomeLoss:
+		return "0-1"
+	case OutcomeDraw:
+		return "1/2-1/2"
+	}
+	return "*"
+}
+
+// PGNGame is a game read from a PGN file: its tags and its mainline moves in SAN
+type PGNGame struct {
+	Tags  map[string]string
+	Moves []string
+}
+
+// pgnResults are the game termination markers that end a PGN movetext
+var pgnResults = map[string]bool{"1-0": true, "0-1": true, "1/2-1/2": true, "*": true}
+
+// parsePGN reads all games from a PGN file. Comments, variations, NAGs and
+// move numbers are skipped, so only the mainline SAN moves remain.
+func parsePGN(r io.Reader) ([]PGNGame, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+
+	var games []PGNGame
+	current := PGNGame{Tags: map[string]string{}}
+	inMovetext := false
+	finish := func() {
+		if len(current.Tags) > 0 || len(current.Moves) > 0 {
+			games = append(games, current)
+		}
+		current = PGNGame{Tags: map[string]string{}}
+		inMovetext = false
+	}
+
+	for i := 0; i < len(text); {
+		switch c := text[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '[':
+			// A tag after movetext starts the next game, even without a result marker
+			if inMovetext {
+				finish()
+			}
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated PGN tag at offset %d", i)
+			}
+			name, value, err := parsePGNTag(text[i+1 : i+end])
+			if err != nil {
+				return nil, err
+			}
+			current.Tags[name] = value
+			i += end + 1
+		case c == '{':
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated PGN comment at offset %d", i)
+			}
+			i += end + 1
+		case c == ';':
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text) - i
+			}
+			i += end
+		case c == '(':
+			depth := 0
+			for ; i < len(text); i++ {
+				if text[i] == '(' {
+					depth++
+				} else if text[i] == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if depth != 0 {
+				return nil, fmt.Errorf("unterminated PGN variation")
+			}
+			i++
+		default:
+			end := i
+			for end < len(text) && !strings.ContainsRune(" \t\r\n[]{}();", rune(text[end])) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("unexpected '%c' in PGN at offset %d", c, i)
+			}
+			token := text[i:end]
+			i = end
+
+			inMovetext = true
+			if pgnResults[token] {
+				finish()
+				continue
+			}
+			// Move numbers may be attached to the move ("12.e4") or stand alone ("12...")
+			token = strings.TrimLeft(token, "0123456789")
+			token = strings.TrimLeft(token, ".")
+			if token != "" && token[0] != '$' {
+				current.Moves = append(current.Moves, token)
+			}
+		}
+	}
+	finish()
+	return games, nil
+}
+
+// parsePGNTag splits the inside of a tag pair such as `Event "Casual"`
+func parsePGNTag(tag string) (string, string, error) {
+	tag = strings.TrimSpace(tag)
+	space := strings.IndexAny(tag, " \t")
+	if space < 0 {
+		return "", "", fmt.Errorf("invalid PGN tag '[%s]'", tag)
+	}
+	value := strings.TrimSpace(tag[space:])
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return "", "", fmt.Errorf("invalid PGN tag '[%s]'", tag)
+	}
+	value = strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(value[1 : len(value)-1])
+	return tag[:space], value, nil
+}
+
+// pgnGameUCIMoves converts a game's SAN moves to UCI, starting from its FEN tag when present
+func pgnGameUCIMoves(game PGNGame) ([]string, string, error) {
+	initialFEN := game.Tags["FEN"]
+	pos, err := positionAfter(nil, initialFEN)
+	if err != nil {
+		return nil, "", err
+	}
+	moves := make([]string, 0, len(game.Moves))
+	for _, san := range game.Moves {
+		move, err := pos.SANToUCI(san)
+		if err != nil {
+			return nil, "", fmt.Errorf("move %d: %v", len(moves)+1, err)
+		}
+		if err := pos.Play(move); err != nil {
+			return nil, "", err
+		}
+		moves = append(moves, move)
+	}
+	return moves, initialFEN, nil
+}
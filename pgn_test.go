@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPGNTagLines(t *testing.T) {
+	tags := map[string]string{
+		"style":     "aggressive",
+		"tested":    "true",
+		"Note":      `say "hi" \ bye`,
+		"bad name!": "kept",
+		"!!!":       "dropped",
+	}
+
+	expected := "[Note \"say \\\"hi\\\" \\\\ bye\"]\n" +
+		"[badname \"kept\"]\n" +
+		"[style \"aggressive\"]\n" +
+		"[tested \"true\"]\n"
+
+	if got := pgnTagLines(tags); got != expected {
+		t.Errorf("Unexpected PGN tags:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestPGNTagLines_Empty(t *testing.T) {
+	if got := pgnTagLines(nil); got != "" {
+		t.Errorf("Expected no tag lines, got '%s'", got)
+	}
+}
+
+func TestFormatPGN(t *testing.T) {
+	tags := map[string]string{"White": "MockBot", "Black": "Opponent", "Result": "0-1", "style": "aggressive"}
+	pgn, err := formatPGN(tags, []string{"f2f3", "e7e5", "g2g4", "d8h4"}, startposFEN)
+	if err != nil {
+		t.Fatalf("formatPGN() failed: %v", err)
+	}
+	expected := `[Event "?"]
+[Site "?"]
+[Date "?"]
+[Round "?"]
+[White "MockBot"]
+[Black "Opponent"]
+[Result "0-1"]
+[style "aggressive"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+`
+	if pgn != expected {
+		t.Errorf("Unexpected PGN:\n%s\nexpected:\n%s", pgn, expected)
+	}
+}
+
+func TestFormatPGN_CustomPosition(t *testing.T) {
+	fen := "4k3/8/8/8/8/8/4P3/4K3 b - - 0 12"
+	pgn, err := formatPGN(nil, []string{"e8d7", "e2e4"}, fen)
+	if err != nil {
+		t.Fatalf("formatPGN() failed: %v", err)
+	}
+	games, err := parsePGN(strings.NewReader(pgn))
+	if err != nil || len(games) != 1 {
+		t.Fatalf("Expected the PGN to parse back into one game, got %v (%v)", games, err)
+	}
+	if games[0].Tags["FEN"] != fen || games[0].Tags["SetUp"] != "1" || games[0].Tags["Result"] != "*" {
+		t.Errorf("Unexpected tags %v", games[0].Tags)
+	}
+	if !strings.Contains(pgn, "12... Kd7 13. e4 *") {
+		t.Errorf("Expected movetext numbered from the FEN, got:\n%s", pgn)
+	}
+}
+
+func TestPGNResult(t *testing.T) {
+	tests := []struct {
+		status, winner, expected string
+	}{
+		{"mate", "white", "1-0"},
+		{"resign", "black", "0-1"},
+		{"stalemate", "", "1/2-1/2"},
+		{"started", "", "*"},
+		{"aborted", "", "*"},
+	}
+	for _, tt := range tests {
+		if got := pgnResult(tt.status, tt.winner); got != tt.expected {
+			t.Errorf("pgnResult(%s, %s) = %s, expected %s", tt.status, tt.winner, got, tt.expected)
+		}
+	}
+}
+
+func TestParsePGN(t *testing.T) {
+	games, err := parsePGN(strings.NewReader(prewarmTestPGN))
+	if err != nil {
+		t.Fatalf("parsePGN() failed: %v", err)
+	}
+	if len(games) != 4 {
+		t.Fatalf("Expected 4 games, got %d", len(games))
+	}
+
+	first := games[0]
+	if first.Tags["White"] != "Alice" || first.Tags["Result"] != "1-0" {
+		t.Errorf("Unexpected tags %v", first.Tags)
+	}
+	expected := "e4 e5 Nf3 Nc6 Bb5 a6"
+	if got := strings.Join(first.Moves, " "); got != expected {
+		t.Errorf("Expected moves '%s', got '%s'", expected, got)
+	}
+	if got := strings.Join(games[1].Moves, " "); got != "e4 c5 Nf3 d6" {
+		t.Errorf("Expected comment to be skipped, got '%s'", got)
+	}
+}
+
+func TestParsePGN_TagValueEscapes(t *testing.T) {
+	tags := map[string]string{"Event": `Say "hi" \ bye`}
+	games, err := parsePGN(strings.NewReader(pgnTagLines(tags) + "\n1. d4 *\n"))
+	if err != nil {
+		t.Fatalf("parsePGN() failed: %v", err)
+	}
+	if len(games) != 1 || games[0].Tags["Event"] != tags["Event"] {
+		t.Errorf("Expected tag to round-trip, got %v", games)
+	}
+}
+
+func TestParsePGN_Unterminated(t *testing.T) {
+	for _, pgn := range []string{`[Event "x"`, "1. e4 {comment", "1. e4 (1. d4"} {
+		if _, err := parsePGN(strings.NewReader(pgn)); err == nil {
+			t.Errorf("Expected error for '%s', but got nil", pgn)
+		}
+	}
+}
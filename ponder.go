@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// PonderCache stores speculative bot replies computed while the opponent is thinking.
+// Entries are keyed by the full move list of the position the reply is for.
+type PonderCache struct {
+	mu      sync.Mutex
+	replies map[string]string
+}
+
+// NewPonderCache creates an empty cache
+func NewPonderCache() *PonderCache {
+	return &PonderCache{replies: make(map[string]string)}
+}
+
+func ponderKey(moves []string) string {
+	return strings.Join(moves, " ")
+}
+
+// Store saves the reply for the position after the given moves
+func (c *PonderCache) Store(moves []string, reply string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replies[ponderKey(moves)] = reply
+}
+
+// Lookup returns the pre-computed reply for the position after the given moves.
+// On a hit the cache is cleared, since all other predictions are now stale.
+func (c *PonderCache) Lookup(moves []string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reply, ok := c.replies[ponderKey(moves)]
+	if ok {
+		c.replies = make(map[string]string)
+	}
+	return reply, ok
+}
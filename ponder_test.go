@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestPonderCache(t *testing.T) {
+	cache := NewPonderCache()
+	cache.Store([]string{"e2e4", "e7e5", "g1f3", "b8c6"}, "f1b5")
+	cache.Store([]string{"e2e4", "e7e5", "g1f3", "g8f6"}, "f3e5")
+
+	// Opponent played something that was not predicted
+	if reply, ok := cache.Lookup([]string{"e2e4", "e7e5", "g1f3", "d7d6"}); ok {
+		t.Errorf("Expected cache miss, got '%s'", reply)
+	}
+
+	reply, ok := cache.Lookup([]string{"e2e4", "e7e5", "g1f3", "b8c6"})
+	if !ok || reply != "f1b5" {
+		t.Errorf("Expected cache hit 'f1b5', got '%s' (ok=%v)", reply, ok)
+	}
+
+	// Other predictions are discarded after a hit
+	if reply, ok := cache.Lookup([]string{"e2e4", "e7e5", "g1f3", "g8f6"}); ok {
+		t.Errorf("Expected stale prediction to be cleared, got '%s'", reply)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// PositionCache maps positions to known-good moves so common positions can be
+// played without querying the LLM. When a position was seen with several moves,
+// the most frequent one is returned.
+type PositionCache struct {
+	mu        sync.RWMutex
+	positions map[string]map[string]int
+}
+
+// NewPositionCache creates an empty cache
+func NewPositionCache() *PositionCache {
+	return &PositionCache{positions: map[string]map[string]int{}}
+}
+
+// positionKey drops the move counters from a FEN, so transpositions reached
+// at different move numbers share an entry
+func positionKey(fen string) string {
+	fields := strings.Fields(fen)
+	if len(fields) > 4 {
+		fields = fields[:4]
+	}
+	return strings.Join(fields, " ")
+}
+
+// Add records move as played in the position given by fen
+func (c *PositionCache) Add(fen, move string) {
+	key := positionKey(fen)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.positions[key] == nil {
+		c.positions[key] = map[string]int{}
+	}
+	c.positions[key][move]++
+}
+
+// Lookup returns the most frequently recorded move for the position, if any.
+// Ties go to the alphabetically first move so results are deterministic.
+func (c *PositionCache) Lookup(fen string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	best, bestCount := "", 0
+	for move, count := range c.positions[positionKey(fen)] {
+		if count > bestCount || (count == bestCount && move < best) {
+			best, bestCount = move, count
+		}
+	}
+	return best, bestCount > 0
+}
+
+// Len returns the number of distinct positions in the cache
+func (c *PositionCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.positions)
+}
+
+// prewarmPositionCache adds every position and move from the games in a PGN file
+// to the cache and returns the number of games loaded. Games whose moves cannot be
+// read are skipped with a log message rather than failing the whole file.
+func prewarmPositionCache(cache *PositionCache, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open PGN file: %v", err)
+	}
+	defer f.Close()
+
+	games, err := parsePGN(f)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse PGN file %s: %v", path, err)
+	}
+
+	loaded := 0
+	for i, game := range games {
+		moves, initialFEN, err := pgnGameUCIMoves(game)
+		if err != nil {
+			log.Printf("Skipping game %d in %s: %v", i+1, path, err)
+			continue
+		}
+		pos, err := positionAfter(nil, initialFEN)
+		if err != nil {
+			log.Printf("Skipping game %d in %s: %v", i+1, path, err)
+			continue
+		}
+		for _, move := range moves {
+			cache.Add(pos.FEN(), move)
+			pos.Play(move)
+		}
+		loaded++
+	}
+	log.Printf("Prewarmed position cache with %d positions from %d games in %s", cache.Len(), loaded, path)
+	return loaded, nil
+}
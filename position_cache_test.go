@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const prewarmTestPGN = `[Event "Casual"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 {the main line} Nc6 (2... d6 3. d4) 3. Bb5 $1 a6 1-0
+
+[Event "Casual"]
+[Result "0-1"]
+
+1. e4 c5 2. Nf3 ; Sicilian
+d6 0-1
+
+[Event "Broken"]
+
+1. e4 e4 *
+
+[Event "From position"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+
+1. e3 Kd7 *
+`
+
+func TestPositionCache_Lookup(t *testing.T) {
+	cache := NewPositionCache()
+	cache.Add(startFEN, "d2d4")
+	cache.Add(startFEN, "e2e4")
+	// The move counters do not matter
+	cache.Add("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 7", "e2e4")
+
+	if move, ok := cache.Lookup(startFEN); !ok || move != "e2e4" {
+		t.Errorf("Expected most frequent move 'e2e4', got '%s' (%v)", move, ok)
+	}
+	if _, ok := cache.Lookup("4k3/8/8/8/8/8/8/4K3 w - - 0 1"); ok {
+		t.Error("Expected no move for an unknown position")
+	}
+}
+
+func TestPrewarmPositionCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.pgn")
+	if err := os.WriteFile(path, []byte(prewarmTestPGN), 0o644); err != nil {
+		t.Fatalf("Failed to write PGN: %v", err)
+	}
+
+	cache := NewPositionCache()
+	loaded, err := prewarmPositionCache(cache, path)
+	if err != nil {
+		t.Fatalf("prewarmPositionCache() failed: %v", err)
+	}
+	if loaded != 3 {
+		t.Errorf("Expected 3 games loaded (the broken one skipped), got %d", loaded)
+	}
+
+	// Both games open 1. e4 and continue 2. Nf3 after different replies
+	if move, ok := cache.Lookup(startFEN); !ok || move != "e2e4" {
+		t.Errorf("Expected 'e2e4' from the start position, got '%s' (%v)", move, ok)
+	}
+	afterE5, _ := movesToFEN([]string{"e2e4", "e7e5"}, "")
+	if move, ok := cache.Lookup(afterE5); !ok || move != "g1f3" {
+		t.Errorf("Expected 'g1f3' after 1. e4 e5, got '%s' (%v)", move, ok)
+	}
+	afterNc6, _ := movesToFEN([]string{"e2e4", "e7e5", "g1f3", "b8c6"}, "")
+	if move, ok := cache.Lookup(afterNc6); !ok || move != "f1b5" {
+		t.Errorf("Expected 'f1b5', got '%s' (%v)", move, ok)
+	}
+	// Variations are not part of the mainline
+	afterD6, _ := movesToFEN([]string{"e2e4", "e7e5", "g1f3", "d7d6"}, "")
+	if move, ok := cache.Lookup(afterD6); ok {
+		t.Errorf("Expected no move from the variation, got '%s'", move)
+	}
+	// Games with a FEN tag start from that position
+	if move, ok := cache.Lookup("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"); !ok || move != "e2e3" {
+		t.Errorf("Expected 'e2e3' from the FEN game, got '%s' (%v)", move, ok)
+	}
+}
+
+func TestPrewarmPositionCache_MissingFile(t *testing.T) {
+	if _, err := prewarmPositionCache(NewPositionCache(), filepath.Join(t.TempDir(), "missing.pgn")); err == nil {
+		t.Error("Expected error for a missing PGN file, but got nil")
+	}
+}
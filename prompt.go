@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// formatMovesForPrompt renders a list of UCI moves for the LLM prompt.
+// With includeNumbers set the moves are numbered like a game score:
+// "1. e2e4 e7e5 2. g1f3 b8c6".
+func formatMovesForPrompt(moves []string, includeNumbers bool) string {
+	if !includeNumbers {
+		return strings.Join(moves, " ")
+	}
+
+	var sb strings.Builder
+	for i, move := range moves {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		if i%2 == 0 {
+			sb.WriteString(fmt.Sprintf("%d. ", i/2+1))
+		}
+		sb.WriteString(move)
+	}
+	return sb.String()
+}
+
+// promptMoveHistory formats the game's moves, played from initialFEN (the standard
+// starting position when empty), for a prompt, in SAN when LLM_USE_SAN is enabled.
+// Moves that cannot be converted are shown in UCI as before. notation is "SAN" or
+// "UCI", whichever was used, for labelling the history in the prompt.
+func promptMoveHistory(cfg *BotConfig, moves []string, initialFEN string) (history, notation string) {
+	if cfg.LLMUseSAN {
+		if withSAN, err := movesWithSAN(moves, initialFEN); err == nil {
+			san := make([]string, len(withSAN))
+			for i, m := range withSAN {
+				san[i] = m.SAN
+			}
+			return formatMovesForPrompt(san, cfg.PromptIncludeMoveNumbers), "SAN"
+		}
+	}
+	return formatMovesForPrompt(moves, cfg.PromptIncludeMoveNumbers), "UCI"
+}
+
+// buildMovePrompt is the user prompt asking for the next move of color after moves
+// from initialFEN. Games from a custom position (endgame studies, From's Gambit
+// setups and the like) include the starting FEN, since the move list alone does not
+// describe the position.
+func buildMovePrompt(cfg *BotConfig, moves []string, initialFEN, color string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("You are playing chess as %s.\n", color))
+	if initialFEN != "" && initialFEN != startposFEN {
+		sb.WriteString(fmt.Sprintf("The game started from this position (FEN): %s\n", initialFEN))
+	}
+	history, notation := promptMoveHistory(cfg, moves, initialFEN)
+	if len(moves) == 0 {
+		history = "none"
+	}
+	sb.WriteString(fmt.Sprintf("Moves so far (%s): %s\n", notation, history))
+	sb.WriteString("Reply with your next move in UCI notation, for example e2e4.")
+	return sb.String()
+}
+
+// withFeedback appends a caller's feedback on an earlier answer to a move prompt
+func withFeedback(prompt, feedback string) string {
+	if feedback == "" {
+		return prompt
+	}
+	return prompt + "\nFeedback on your previous answer: " + feedback
+}
+
+// parseMoveReply returns the first UCI move in a plain-text reply
+func parseMoveReply(content string) (string, error) {
+	for _, field := range strings.Fields(content) {
+		if move := strings.ToLower(strings.Trim(field, "*`.:,;()\"'")); isUCIMove(move) {
+			return move, nil
+		}
+	}
+	return "", fmt.Errorf("no UCI move in reply '%s'", content)
+}
+
+// chainOfThoughtInstruction is prepended to the user prompt when THINK_BEFORE_MOVE is enabled
+const chainOfThoughtInstruction = "Think step by step about the position before providing the move. " +
+	"Then on the final line, output only the UCI move."
+
+// withChainOfThought prepends the step-by-step instruction to a user prompt
+func withChainOfThought(prompt string) string {
+	return chainOfThoughtInstruction + "\n\n" + prompt
+}
+
+// parseChainOfThought splits a step-by-step reply into the move on its final
+// non-empty line and the reasoning that precedes it
+func parseChainOfThought(content string) (move, reasoning string, err error) {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if last == "" {
+		return "", "", fmt.Errorf("empty chain-of-thought reply")
+	}
+
+	// Models sometimes decorate the final line ("Move: **e2e4**"); keep the last word
+	fields := strings.Fields(last)
+	move = strings.ToLower(strings.Trim(fields[len(fields)-1], "*`.\"'"))
+	if !isUCIMove(move) {
+		return "", "", fmt.Errorf("no UCI move on the final line of reply: '%s'", last)
+	}
+
+	reasoning = strings.TrimSpace(strings.Join(lines[:len(lines)-1], "\n"))
+	return move, reasoning, nil
+}
+
+// jsonMoveInstruction replaces the plain-text answer format when RESPONSE_FORMAT_JSON is enabled
+const jsonMoveInstruction = `Respond with a JSON object of the form {"move": "e2e4"} containing your move in UCI notation.`
+
+// withJSONMoveFormat appends the JSON answer instruction to a user prompt
+func withJSONMoveFormat(prompt string) string {
+	return prompt + "\n\n" + jsonMoveInstruction
+}
+
+// parseJSONMove extracts the move from a {"move": "..."} reply. If the reply is not
+// valid JSON it falls back to the first UCI move found in the text.
+func parseJSONMove(content string) (string, error) {
+	var reply struct {
+		Move string `json:"move"`
+	}
+	trimmed := strings.TrimSpace(content)
+	// Some models wrap the object in a markdown code fence despite response_format
+	trimmed = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(trimmed, "```json"), "```"), "```")
+
+	if err := json.Unmarshal([]byte(trimmed), &reply); err == nil {
+		move := strings.ToLower(strings.TrimSpace(reply.Move))
+		if isUCIMove(move) {
+			return move, nil
+		}
+	}
+
+	for _, field := range strings.Fields(content) {
+		if move := strings.ToLower(strings.Trim(field, "{}\"':,.*`")); isUCIMove(move) {
+			return move, nil
+		}
+	}
+	return "", fmt.Errorf("no move found in reply '%s'", content)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatMovesForPrompt(t *testing.T) {
+	tests := []struct {
+		name           string
+		moves          []string
+		includeNumbers bool
+		expected       string
+	}{
+		{"no moves", nil, true, ""},
+		{"single move", []string{"e2e4"}, true, "1. e2e4"},
+		{"full move pairs", []string{"e2e4", "e7e5", "g1f3", "b8c6"}, true, "1. e2e4 e7e5 2. g1f3 b8c6"},
+		{"white to move next", []string{"e2e4", "e7e5", "g1f3"}, true, "1. e2e4 e7e5 2. g1f3"},
+		{"numbers disabled", []string{"e2e4", "e7e5", "g1f3"}, false, "e2e4 e7e5 g1f3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMovesForPrompt(tt.moves, tt.includeNumbers); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPromptMoveHistory(t *testing.T) {
+	moves := []string{"e2e4", "e7e5", "g1f3"}
+	// Black to move in a king and pawn ending
+	const endgameFEN = "8/8/8/4k3/8/8/4P3/4K3 b - - 0 1"
+	tests := []struct {
+		name       string
+		cfg        BotConfig
+		moves      []string
+		initialFEN string
+		expected   string
+		notation   string
+	}{
+		{"UCI by default", BotConfig{PromptIncludeMoveNumbers: true}, moves, "", "1. e2e4 e7e5 2. g1f3", "UCI"},
+		{"SAN", BotConfig{LLMUseSAN: true, PromptIncludeMoveNumbers: true}, moves, "", "1. e4 e5 2. Nf3", "SAN"},
+		{"SAN without numbers", BotConfig{LLMUseSAN: true}, moves, "", "e4 e5 Nf3", "SAN"},
+		{"falls back to UCI", BotConfig{LLMUseSAN: true}, []string{"e2e5"}, "", "e2e5", "UCI"},
+		{"SAN from a custom position", BotConfig{LLMUseSAN: true}, []string{"e5d4", "e1d2"}, endgameFEN, "Kd4 Kd2", "SAN"},
+		{"custom position moves are illegal from the start", BotConfig{LLMUseSAN: true}, []string{"e5d4"}, "", "e5d4", "UCI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notation := promptMoveHistory(&tt.cfg, tt.moves, tt.initialFEN)
+			if got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+			if notation != tt.notation {
+				t.Errorf("Expected notation %s, got %s", tt.notation, notation)
+			}
+		})
+	}
+}
+
+func TestWithChainOfThought(t *testing.T) {
+	prompt := withChainOfThought("Moves so far: 1. e2e4")
+	if !strings.HasPrefix(prompt, chainOfThoughtInstruction) || !strings.HasSuffix(prompt, "Moves so far: 1. e2e4") {
+		t.Errorf("Unexpected prompt '%s'", prompt)
+	}
+}
+
+func TestParseChainOfThought(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		move      string
+		reasoning string
+		wantErr   bool
+	}{
+		{
+			name:      "reasoning then move",
+			content:   "White has played e4.\nThe most solid reply is to mirror it.\ne7e5",
+			move:      "e7e5",
+			reasoning: "White has played e4.\nThe most solid reply is to mirror it.",
+		},
+		{
+			name:      "trailing blank lines and decoration",
+			content:   "Develop the knight.\nMove: **G1F3**\n\n",
+			move:      "g1f3",
+			reasoning: "Develop the knight.",
+		},
+		{
+			name:    "promotion",
+			content: "Promote.\ne7e8q",
+			move:    "e7e8q", reasoning: "Promote.",
+		},
+		{name: "move only", content: "d2d4", move: "d2d4"},
+		{name: "no move on last line", content: "e2e4 looks good.\nI am not sure.", wantErr: true},
+		{name: "empty reply", content: "  \n ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			move, reasoning, err := parseChainOfThought(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got move '%s'", move)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseChainOfThought() failed: %v", err)
+			}
+			if move != tt.move {
+				t.Errorf("Expected move '%s', got '%s'", tt.move, move)
+			}
+			if reasoning != tt.reasoning {
+				t.Errorf("Expected reasoning %q, got %q", tt.reasoning, reasoning)
+			}
+		})
+	}
+}
+
+func TestParseJSONMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		move    string
+		wantErr bool
+	}{
+		{"json object", `{"move": "e2e4"}`, "e2e4", false},
+		{"json with extra fields", `{"move":"G1F3","comment":"develop"}`, "g1f3", false},
+		{"code fenced json", "```json\n{\"move\": \"e7e8q\"}\n```", "e7e8q", false},
+		{"malformed json falls back to text", `{"move": "d2d4"`, "d2d4", false},
+		{"plain text fallback", "I play c2c4.", "c2c4", false},
+		{"json with invalid move", `{"move": "Nf3"}`, "", true},
+		{"no move at all", `{"error": "thinking"}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			move, err := parseJSONMove(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJSONMove() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if move != tt.move {
+				t.Errorf("Expected move '%s', got '%s'", tt.move, move)
+			}
+		})
+	}
+}
+
+func TestBuildMovePrompt(t *testing.T) {
+	cfg := &BotConfig{}
+	prompt := buildMovePrompt(cfg, []string{"e2e4"}, "startpos", "black")
+	if !strings.Contains(prompt, "as black") || !strings.Contains(prompt, "Moves so far (UCI): e2e4") {
+		t.Errorf("Unexpected prompt %q", prompt)
+	}
+	if strings.Contains(prompt, "FEN") {
+		t.Errorf("Expected no FEN line for a standard game, got %q", prompt)
+	}
+
+	fen := "8/8/8/4k3/8/8/4P3/4K3 w - - 0 1"
+	prompt = buildMovePrompt(cfg, nil, fen, "white")
+	if !strings.Contains(prompt, "The game started from this position (FEN): "+fen) {
+		t.Errorf("Expected the starting FEN in the prompt, got %q", prompt)
+	}
+	if !strings.Contains(prompt, "Moves so far (UCI): none") {
+		t.Errorf("Expected 'none' for an empty move list, got %q", prompt)
+	}
+}
+
+func TestParseMoveReply(t *testing.T) {
+	tests := map[string]string{
+		"e2e4":              "e2e4",
+		"**E7E8Q**":         "e7e8q",
+		"I play g1f3.":      "g1f3",
+		"`b1c3` is natural": "b1c3",
+	}
+	for reply, expected := range tests {
+		if move, err := parseMoveReply(reply); err != nil || move != expected {
+			t.Errorf("parseMoveReply(%q): expected %s, got %q (%v)", reply, expected, move, err)
+		}
+	}
+	if _, err := parseMoveReply("I resign"); err == nil {
+		t.Error("Expected an error for a reply without a move")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DailyPuzzle is the subset of the /api/puzzle/daily response the bot uses
+type DailyPuzzle struct {
+	Game struct {
+		ID  string `json:"id"`
+		PGN string `json:"pgn"`
+	} `json:"game"`
+	Puzzle struct {
+		ID         string   `json:"id"`
+		Rating     int      `json:"rating"`
+		InitialPly int      `json:"initialPly"`
+		Solution   []string `json:"solution"`
+		Themes     []string `json:"themes"`
+	} `json:"puzzle"`
+}
+
+// getDailyPuzzle fetches today's Lichess puzzle
+func getDailyPuzzle(cfg *BotConfig) (*DailyPuzzle, error) {
+	req, err := newLichessRequest(cfg, http.MethodGet, "/api/puzzle/daily", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch daily puzzle: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("fetching daily puzzle failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var puzzle DailyPuzzle
+	if err := json.NewDecoder(resp.Body).Decode(&puzzle); err != nil {
+		return nil, fmt.Errorf("failed to decode daily puzzle: %v", err)
+	}
+	if puzzle.Puzzle.ID == "" || len(puzzle.Puzzle.Solution) == 0 {
+		return nil, fmt.Errorf("daily puzzle response has no solution")
+	}
+	return &puzzle, nil
+}
+
+// PuzzleAttempt tracks the bot's progress through a puzzle solution. The bot
+// plays the even-indexed solution moves; the odd ones are the opponent's replies.
+type PuzzleAttempt struct {
+	Puzzle *DailyPuzzle
+	ply    int
+	Moves  int
+	Failed bool
+}
+
+// NewPuzzleAttempt starts an attempt at the given puzzle
+func NewPuzzleAttempt(p *DailyPuzzle) *PuzzleAttempt {
+	return &PuzzleAttempt{Puzzle: p}
+}
+
+// Play checks the bot's move against the solution. It returns the opponent's reply
+// (empty when the puzzle is finished) and whether the move was correct.
+func (a *PuzzleAttempt) Play(move string) (string, bool) {
+	solution := a.Puzzle.Puzzle.Solution
+	if a.Failed || a.Solved() {
+		return "", false
+	}
+
+	a.Moves++
+	if move != solution[a.ply] {
+		a.Failed = true
+		return "", false
+	}
+
+	a.ply++
+	if a.ply >= len(solution) {
+		return "", true
+	}
+	reply := solution[a.ply]
+	a.ply++
+	return reply, true
+}
+
+// Solved reports whether every solution move has been played
+func (a *PuzzleAttempt) Solved() bool {
+	return !a.Failed && a.ply >= len(a.Puzzle.Puzzle.Solution)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const samplePuzzleJSON = `{
+	"game": {"id": "Gr1xPg0y", "pgn": "e4 e5 Nf3 Nc6 Bc4 Nd4 Nxe5"},
+	"puzzle": {"id": "K69di", "rating": 1516, "initialPly": 6,
+		"solution": ["d8g5", "e5f7", "g5g2"], "themes": ["fork", "short"]}
+}`
+
+func TestGetDailyPuzzle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/puzzle/daily" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+		w.Write([]byte(samplePuzzleJSON))
+	}))
+	defer server.Close()
+
+	puzzle, err := getDailyPuzzle(newTestLichessConfig(server.URL))
+	if err != nil {
+		t.Fatalf("getDailyPuzzle() failed: %v", err)
+	}
+	if puzzle.Puzzle.ID != "K69di" || puzzle.Game.ID != "Gr1xPg0y" || len(puzzle.Puzzle.Solution) != 3 {
+		t.Errorf("Unexpected puzzle %+v", puzzle)
+	}
+}
+
+func TestPuzzleAttempt(t *testing.T) {
+	puzzle := &DailyPuzzle{}
+	puzzle.Puzzle.Solution = []string{"d8g5", "e5f7", "g5g2"}
+
+	a := NewPuzzleAttempt(puzzle)
+	reply, ok := a.Play("d8g5")
+	if !ok || reply != "e5f7" {
+		t.Fatalf("Expected correct move with reply 'e5f7', got ok=%v reply='%s'", ok, reply)
+	}
+	if reply, ok = a.Play("g5g2"); !ok || reply != "" {
+		t.Fatalf("Expected final correct move, got ok=%v reply='%s'", ok, reply)
+	}
+	if !a.Solved() || a.Moves != 2 {
+		t.Errorf("Expected puzzle solved in 2 moves, got solved=%v moves=%d", a.Solved(), a.Moves)
+	}
+
+	a = NewPuzzleAttempt(puzzle)
+	if _, ok := a.Play("e7e5"); ok {
+		t.Error("Expected wrong move to fail")
+	}
+	if a.Solved() || !a.Failed {
+		t.Error("Expected attempt to be marked as failed")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// lichessRateLimit is shared by all Lichess API calls
+var lichessRateLimit = NewRateLimitState()
+
+// RateLimitState tracks the X-RateLimit-* headers returned by the API so calls
+// can pause until the limit resets instead of being rejected
+type RateLimitState struct {
+	mu        sync.Mutex
+	limit     int
+	remaining int
+	reset     time.Time
+	known     bool
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// rateLimitedBackoff is how long to wait after a 429 response that does not say
+// when to retry; Lichess asks clients to wait a full minute
+const rateLimitedBackoff = time.Minute
+
+// NewRateLimitState creates a state with no known limit
+func NewRateLimitState() *RateLimitState {
+	return &RateLimitState{now: time.Now, sleep: sleepContext}
+}
+
+// Update records the rate limit headers of a response. Responses without the
+// headers leave the state unchanged.
+func (s *RateLimitState) Update(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.known = true
+	s.remaining = remaining
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		s.limit = limit
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		s.reset = time.Unix(reset, 0)
+	}
+}
+
+// MarkLimited records a 429 response: no requests remain until the Retry-After
+// delay (or rateLimitedBackoff when the header is missing) has passed
+func (s *RateLimitState) MarkLimited(header http.Header) {
+	delay := rateLimitedBackoff
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.known = true
+	s.remaining = 0
+	if reset := s.now().Add(delay); reset.After(s.reset) {
+		s.reset = reset
+	}
+}
+
+// Wait blocks until the rate limit has reset if no requests are remaining, or until
+// ctx is done, in which case it returns ctx.Err(). The state is kept until the reset
+// time has passed, so every caller that arrives before then waits.
+func (s *RateLimitState) Wait(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.known || s.remaining > 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	delay := s.reset.Sub(s.now())
+	s.mu.Unlock()
+
+	if delay > 0 {
+		log.Printf("Lichess rate limit reached, waiting %v until reset", delay)
+		return s.sleep(ctx, delay)
+	}
+	return nil
+}
+
+// doLichessRequest sends a request to Lichess, respecting the shared rate limit state.
+// A 429 response is retried once after waiting for the limit to reset, provided the
+// request body can be sent again.
+func doLichessRequest(req *http.Request) (*http.Response, error) {
+	resp, err := sendLichessRequest(lichessHTTPClient, req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+	lichessRateLimit.MarkLimited(resp.Header)
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	resp, err = sendLichessRequest(lichessHTTPClient, retry)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		lichessRateLimit.MarkLimited(resp.Header)
+	}
+	return resp, err
+}
+
+// doLichessStreamRequest is doLichessRequest for streaming endpoints that may stay open
+// indefinitely. A 429 is returned to the caller, which reconnects with its own backoff.
+func doLichessStreamRequest(req *http.Request) (*http.Response, error) {
+	resp, err := sendLichessRequest(lichessStreamClient, req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		lichessRateLimit.MarkLimited(resp.Header)
+	}
+	return resp, err
+}
+
+// sendLichessRequest waits for the rate limit, sends req with client and records
+// the rate limit headers of the response
+func sendLichessRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	if err := lichessRateLimit.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	lichessRateLimit.Update(resp.Header)
+	return resp, nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRateLimitState returns a state on a fake clock that sleeping moves forward
+func newTestRateLimitState(now time.Time) (*RateLimitState, *time.Duration) {
+	var slept time.Duration
+	state := NewRateLimitState()
+	state.now = func() time.Time { return now }
+	state.sleep = func(ctx context.Context, d time.Duration) error {
+		slept += d
+		now = now.Add(d)
+		return nil
+	}
+	return state, &slept
+}
+
+func TestRateLimitState_WaitsUntilReset(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	state, slept := newTestRateLimitState(now)
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "60")
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(5*time.Second).Unix(), 10))
+	state.Update(header)
+
+	state.Wait(context.Background())
+	if *slept != 5*time.Second {
+		t.Errorf("Expected to sleep 5s until reset, slept %v", *slept)
+	}
+
+	// Once the reset time has passed there is nothing left to wait for
+	state.Wait(context.Background())
+	if *slept != 5*time.Second {
+		t.Errorf("Expected no additional sleep, slept %v in total", *slept)
+	}
+}
+
+func TestRateLimitState_AllWaitersWaitUntilReset(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	state := NewRateLimitState()
+	state.now = func() time.Time { return now }
+	var mu sync.Mutex
+	var sleeps []time.Duration
+	state.sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		sleeps = append(sleeps, d)
+		mu.Unlock()
+		return nil
+	}
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(5*time.Second).Unix(), 10))
+	state.Update(header)
+
+	// The clock does not move, as if every caller arrived before the first one woke up
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			state.Wait(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if len(sleeps) != 3 {
+		t.Fatalf("Expected all 3 callers to wait, got %d waits", len(sleeps))
+	}
+	for _, d := range sleeps {
+		if d != 5*time.Second {
+			t.Errorf("Expected each caller to wait 5s, got %v", d)
+		}
+	}
+}
+
+func TestRateLimitState_NoWaitWhenRemaining(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	state, slept := newTestRateLimitState(now)
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "10")
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(30*time.Second).Unix(), 10))
+	state.Update(header)
+	state.Wait(context.Background())
+
+	// Responses without rate limit headers don't change anything
+	state.Update(http.Header{})
+	state.Wait(context.Background())
+
+	if *slept != 0 {
+		t.Errorf("Expected no sleep, slept %v", *slept)
+	}
+}
+
+func TestMakeMove_RespectsRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	state, slept := newTestRateLimitState(now)
+	originalState := lichessRateLimit
+	lichessRateLimit = state
+	defer func() { lichessRateLimit = originalState }()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("X-RateLimit-Limit", "2")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(3*time.Second).Unix(), 10))
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestLichessConfig(server.URL)
+	if err := makeMove(cfg, "game1", "e2e4", false); err != nil {
+		t.Fatalf("makeMove() failed: %v", err)
+	}
+	if *slept != 0 {
+		t.Errorf("Expected no wait before the first call, slept %v", *slept)
+	}
+
+	if err := makeMove(cfg, "game1", "d2d4", false); err != nil {
+		t.Fatalf("makeMove() failed: %v", err)
+	}
+	if *slept != 3*time.Second {
+		t.Errorf("Expected to wait 3s for the rate limit reset, slept %v", *slept)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRateLimitState_WaitEndsWithContext(t *testing.T) {
+	state := NewRateLimitState()
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	state.Update(header)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := state.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Wait() returned after %v, expected it to end with the context", elapsed)
+	}
+}
+
+func TestDoLichessRequest_RetriesOnceAfter429(t *testing.T) {
+	tests := []struct {
+		name          string
+		responses     []int
+		expectedCalls int32
+		expectedCode  int
+	}{
+		{"succeeds on retry", []int{http.StatusTooManyRequests, http.StatusOK}, 2, http.StatusOK},
+		{"gives up after one retry", []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}, 2, http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+			state, slept := newTestRateLimitState(now)
+			originalState := lichessRateLimit
+			lichessRateLimit = state
+			defer func() { lichessRateLimit = originalState }()
+
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := atomic.AddInt32(&calls, 1)
+				if r.FormValue("text") != "hello" {
+					t.Errorf("Call %d: expected the form body to be resent, got '%s'", call, r.FormValue("text"))
+				}
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(tt.responses[call-1])
+			}))
+			defer server.Close()
+
+			cfg := newTestLichessConfig(server.URL)
+			req, err := newLichessRequest(cfg, http.MethodPost, "/api/bot/game/game1/chat", strings.NewReader("text=hello"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			resp, err := doLichessRequest(req)
+			if err != nil {
+				t.Fatalf("doLichessRequest() failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, resp.StatusCode)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, got)
+			}
+			if *slept != 2*time.Second {
+				t.Errorf("Expected to wait the 2s Retry-After before retrying, slept %v", *slept)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// streamReconnectAttempts bounds the connection attempts after a stream drops
+const streamReconnectAttempts = 5
+
+// maxEmptyStreamDelay caps the wait before reconnecting to a stream that keeps
+// ending without delivering any data
+const maxEmptyStreamDelay = time.Minute
+
+// ErrReaderClosed is returned by ReconnectingReader after Close
+var ErrReaderClosed = errors.New("reconnecting reader closed")
+
+// ReconnectingReader is an io.Reader over a long-lived stream that transparently
+// reopens the stream when it ends or fails. connect is called for the first read
+// and after every EOF or read error, retrying with backoff; Read only fails once
+// connect gives up, returns a Permanent error or ctx is done. Streams that end
+// without sending anything are reconnected with a growing delay. Wrap it in
+// bufio.NewScanner to read NDJSON events across reconnects.
+type ReconnectingReader struct {
+	connect func() (io.ReadCloser, error)
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu           sync.Mutex
+	current      io.ReadCloser
+	closed       bool
+	lastByte     byte
+	needNewline  bool // the last stream ended mid-line
+	delivered    bool // the current stream has returned data
+	emptyStreams int  // consecutive streams that ended without data
+}
+
+// NewReconnectingReader creates a reader that opens streams with connect until
+// ctx is done or the reader is closed
+func NewReconnectingReader(ctx context.Context, connect func() (io.ReadCloser, error)) *ReconnectingReader {
+	ctx, cancel := context.WithCancel(ctx)
+	return &ReconnectingReader{connect: connect, ctx: ctx, cancel: cancel, lastByte: '\n'}
+}
+
+// Read reads from the current stream, reconnecting as needed
+func (r *ReconnectingReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for {
+		// Terminate a line cut off by the disconnect so it is not glued to the
+		// first line of the new stream
+		r.mu.Lock()
+		if r.needNewline && !r.closed {
+			r.needNewline = false
+			r.lastByte = '\n'
+			r.mu.Unlock()
+			p[0] = '\n'
+			return 1, nil
+		}
+		r.mu.Unlock()
+
+		stream, err := r.stream()
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := stream.Read(p)
+		if n > 0 {
+			r.mu.Lock()
+			r.lastByte = p[n-1]
+			r.delivered = true
+			r.mu.Unlock()
+		}
+		if err == nil {
+			return n, nil
+		}
+
+		r.drop(stream)
+		if r.isClosed() {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, ErrReaderClosed
+		}
+		if err != io.EOF {
+			log.Printf("Stream read failed: %v; reconnecting", err)
+		}
+		r.mu.Lock()
+		r.needNewline = r.lastByte != '\n'
+		r.mu.Unlock()
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// stream returns the open stream, connecting first if there is none
+func (r *ReconnectingReader) stream() (io.ReadCloser, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, ErrReaderClosed
+	}
+	if r.current != nil {
+		stream := r.current
+		r.mu.Unlock()
+		return stream, nil
+	}
+	emptyStreams := r.emptyStreams
+	r.mu.Unlock()
+
+	// A stream that ends straight away, such as the stream of a finished game,
+	// must not be reopened in a tight loop
+	if emptyStreams > 0 {
+		delay := maxEmptyStreamDelay
+		if emptyStreams < 16 && retryBaseDelay<<(emptyStreams-1) < delay {
+			delay = retryBaseDelay << (emptyStreams - 1)
+		}
+		if err := sleepContext(r.ctx, delay); err != nil {
+			return nil, r.stopError(err)
+		}
+	}
+
+	var stream io.ReadCloser
+	err := RetryWithBackoffContext(r.ctx, streamReconnectAttempts, func() error {
+		if r.isClosed() {
+			return Permanent(ErrReaderClosed)
+		}
+		var err error
+		stream, err = r.connect()
+		return err
+	})
+	if err != nil {
+		return nil, r.stopError(fmt.Errorf("failed to open stream: %w", err))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		stream.Close()
+		return nil, ErrReaderClosed
+	}
+	r.current = stream
+	r.delivered = false
+	return stream, nil
+}
+
+// stopError reports ErrReaderClosed instead of err when the wait was cut short by Close
+func (r *ReconnectingReader) stopError(err error) error {
+	if r.isClosed() {
+		return ErrReaderClosed
+	}
+	return err
+}
+
+// drop closes stream and forgets it so the next read reconnects
+func (r *ReconnectingReader) drop(stream io.ReadCloser) {
+	stream.Close()
+	r.mu.Lock()
+	if r.current == stream {
+		r.current = nil
+		if r.delivered {
+			r.emptyStreams = 0
+		} else {
+			r.emptyStreams++
+		}
+	}
+	r.mu.Unlock()
+}
+
+func (r *ReconnectingReader) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+// Close closes the current stream and stops further reconnects, including any
+// wait between them
+func (r *ReconnectingReader) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	stream := r.current
+	r.current = nil
+	r.mu.Unlock()
+	r.cancel()
+
+	if stream != nil {
+		return stream.Close()
+	}
+	return nil
+}
+
+// lichessStreamConnector returns a connect function for NewReconnectingReader that
+// opens a Lichess NDJSON stream such as /api/stream/event. Client errors (4xx) are
+// permanent, so a revoked token does not reconnect forever.
+func lichessStreamConnector(ctx context.Context, cfg *BotConfig, path string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		req, err := newLichessRequest(cfg, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, Permanent(err)
+		}
+		req = req.WithContext(ctx)
+
+		resp, err := doLichessStreamRequest(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, Permanent(ctx.Err())
+			}
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			err := fmt.Errorf("stream %s returned status %d: %s", path, resp.StatusCode, body)
+			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return nil, Permanent(err)
+			}
+			return nil, err
+		}
+		return resp.Body, nil
+	}
+}
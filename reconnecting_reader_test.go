@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingStream returns its data and then a network-style error instead of EOF
+type failingStream struct {
+	r io.Reader
+}
+
+func (s *failingStream) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset by peer")
+	}
+	return n, err
+}
+
+func (s *failingStream) Close() error { return nil }
+
+// eofWithDataStream returns all of its data together with io.EOF in a single read
+type eofWithDataStream struct {
+	data string
+}
+
+func (s *eofWithDataStream) Read(p []byte) (int, error) {
+	n := copy(p, s.data)
+	s.data = s.data[n:]
+	if s.data == "" {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (s *eofWithDataStream) Close() error { return nil }
+
+func TestReconnectingReader_ReconnectsAfterEOFAndErrors(t *testing.T) {
+	withFastRetries(t)
+
+	streams := []io.ReadCloser{
+		io.NopCloser(strings.NewReader("{\"n\":1}\n{\"n\":2}\n")),
+		&failingStream{r: strings.NewReader("{\"n\":3}\n{\"n\":")},
+		io.NopCloser(strings.NewReader("{\"n\":4}\n")),
+	}
+	connects := 0
+	reader := NewReconnectingReader(context.Background(), func() (io.ReadCloser, error) {
+		if connects == len(streams) {
+			return nil, Permanent(errors.New("no more streams"))
+		}
+		connects++
+		return streams[connects-1], nil
+	})
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	expected := []string{`{"n":1}`, `{"n":2}`, `{"n":3}`, `{"n":`, `{"n":4}`}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected lines %q, got %q", expected, lines)
+	}
+	if err := scanner.Err(); err == nil || !strings.Contains(err.Error(), "no more streams") {
+		t.Errorf("Expected the permanent connect error, got %v", err)
+	}
+}
+
+func TestReconnectingReader_TerminatesLineCutOffWithEOF(t *testing.T) {
+	withFastRetries(t)
+
+	streams := []io.ReadCloser{
+		&eofWithDataStream{data: `{"type":"a"`},
+		&eofWithDataStream{data: `{"type":"b"}` + "\n"},
+	}
+	connects := 0
+	reader := NewReconnectingReader(context.Background(), func() (io.ReadCloser, error) {
+		if connects == len(streams) {
+			return nil, Permanent(errors.New("no more streams"))
+		}
+		connects++
+		return streams[connects-1], nil
+	})
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	expected := []string{`{"type":"a"`, `{"type":"b"}`}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected lines %q, got %q", expected, lines)
+	}
+}
+
+func TestReconnectingReader_BacksOffOnEmptyStreams(t *testing.T) {
+	// An hour-long base delay: the second connect only happens if there is no backoff
+	original := retryBaseDelay
+	retryBaseDelay = time.Hour
+	t.Cleanup(func() { retryBaseDelay = original })
+
+	var connects atomic.Int32
+	reader := NewReconnectingReader(context.Background(), func() (io.ReadCloser, error) {
+		connects.Add(1)
+		return io.NopCloser(strings.NewReader("")), nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := reader.Read(make([]byte, 16))
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	reader.Close()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrReaderClosed) {
+			t.Errorf("Expected ErrReaderClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not interrupt the reconnect delay")
+	}
+	if n := connects.Load(); n != 1 {
+		t.Errorf("Expected a single connect before the delay, got %d", n)
+	}
+}
+
+func TestReconnectingReader_RetriesFailedConnects(t *testing.T) {
+	withFastRetries(t)
+
+	attempts := 0
+	reader := NewReconnectingReader(context.Background(), func() (io.ReadCloser, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("temporarily unavailable")
+		}
+		return io.NopCloser(strings.NewReader("ok\n")), nil
+	})
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	if !scanner.Scan() || scanner.Text() != "ok" {
+		t.Fatalf("Expected line 'ok', got '%s' (%v)", scanner.Text(), scanner.Err())
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 connect attempts, got %d", attempts)
+	}
+}
+
+func TestReconnectingReader_Close(t *testing.T) {
+	pr, _ := io.Pipe()
+	var connects atomic.Int32
+	connected := make(chan struct{})
+	reader := NewReconnectingReader(context.Background(), func() (io.ReadCloser, error) {
+		connects.Add(1)
+		close(connected)
+		return pr, nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := reader.Read(make([]byte, 16))
+		done <- err
+	}()
+	<-connected
+	reader.Close()
+
+	if err := <-done; !errors.Is(err, ErrReaderClosed) {
+		t.Errorf("Expected ErrReaderClosed, got %v", err)
+	}
+	if _, err := reader.Read(make([]byte, 16)); !errors.Is(err, ErrReaderClosed) {
+		t.Errorf("Expected ErrReaderClosed after Close, got %v", err)
+	}
+	if n := connects.Load(); n != 1 {
+		t.Errorf("Expected a single connect, got %d", n)
+	}
+}
+
+func TestLichessStreamConnector(t *testing.T) {
+	withFastRetries(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/stream/event" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+		if requests.Add(1) > 2 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"type":"gameStart"}` + "\n"))
+	}))
+	defer server.Close()
+
+	reader := NewReconnectingReader(context.Background(), lichessStreamConnector(context.Background(), newTestLichessConfig(server.URL), "/api/stream/event"))
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("Expected one event from each of 2 connections, got %d", lines)
+	}
+	if err := scanner.Err(); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected a permanent 401 error, got %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("Expected no retries after 401, got %d requests", n)
+	}
+}
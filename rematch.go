@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// GameResult is what the bot keeps of a finished game to offer a rematch
+type GameResult struct {
+	GameID   string `json:"game_id"`
+	Opponent string `json:"opponent"` // the opponent's Lichess ID, empty for AI opponents
+	Color    string `json:"color"`    // the side the bot played
+	Rated    bool   `json:"rated"`
+	// ClockLimit and ClockIncrement are in seconds, both 0 for games without a clock
+	ClockLimit     int    `json:"clock_limit"`
+	ClockIncrement int    `json:"clock_increment"`
+	Outcome        string `json:"outcome"`
+}
+
+// GameResults keeps the results of the games finished since the bot started
+type GameResults struct {
+	cfg     *BotConfig
+	mu      sync.Mutex
+	results map[string]GameResult
+}
+
+// NewGameResults creates an empty store issuing rematches with cfg
+func NewGameResults(cfg *BotConfig) *GameResults {
+	return &GameResults{cfg: cfg, results: make(map[string]GameResult)}
+}
+
+// Add stores the result of a finished game
+func (r *GameResults) Add(result GameResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[result.GameID] = result
+}
+
+// Get returns the result of gameID
+func (r *GameResults) Get(gameID string) (GameResult, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.results[gameID]
+	return result, ok
+}
+
+// IssueRematch challenges the opponent of a finished game to another game with the
+// same clock and rating, the bot taking the other color this time. It returns the ID
+// of the new challenge.
+func IssueRematch(cfg *BotConfig, result *GameResult) (string, error) {
+	if err := checkRematch(result); err != nil {
+		return "", err
+	}
+	form := challengeForm(result.ClockLimit, result.ClockIncrement, result.Rated)
+	form.Set("color", "white")
+	if result.Color == "white" {
+		form.Set("color", "black")
+	}
+	challengeID, err := postChallenge(cfg, result.Opponent, form)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("Challenged %s to a rematch of game %s as %s (challenge %s)", result.Opponent, result.GameID, form.Get("color"), challengeID)
+	return challengeID, nil
+}
+
+// checkRematch returns an error when a game cannot be rematched: games against
+// the Lichess AI and games without a clock
+func checkRematch(result *GameResult) error {
+	if result.Opponent == "" {
+		return fmt.Errorf("game %s was not played against a Lichess user", result.GameID)
+	}
+	if result.ClockLimit == 0 && result.ClockIncrement == 0 {
+		return fmt.Errorf("game %s had no clock", result.GameID)
+	}
+	return nil
+}
+
+// ServeHTTP implements POST /api/games/{gameID}/rematch, challenging the opponent of
+// a finished game to a rematch. It answers with the ID of the new challenge.
+func (r *GameResults) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	gameID := req.PathValue("gameID")
+	result, ok := r.Get(gameID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no finished game %s", gameID), http.StatusNotFound)
+		return
+	}
+	if err := checkRematch(&result); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	challengeID, err := IssueRematch(r.cfg, &result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"challenge_id": challengeID})
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGameResults_Rematch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/challenge/alice" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		r.ParseForm()
+		if r.Form.Get("clock.limit") != "180" || r.Form.Get("clock.increment") != "2" || r.Form.Get("rated") != "true" {
+			t.Errorf("Expected the time control of the last game, got form %v", r.Form)
+		}
+		if color := r.Form.Get("color"); color != "black" {
+			t.Errorf("Expected the bot to take black after playing white, got '%s'", color)
+		}
+		w.Write([]byte(`{"id":"REMATCH1","status":"created"}`))
+	}))
+	defer server.Close()
+
+	results := NewGameResults(newTestLichessConfig(server.URL))
+	results.Add(GameResult{GameID: "game1", Opponent: "alice", Color: "white", Rated: true, ClockLimit: 180, ClockIncrement: 2, Outcome: OutcomeWin})
+	results.Add(GameResult{GameID: "ai", Color: "white", ClockLimit: 180, Outcome: OutcomeLoss})
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/games/{gameID}/rematch", results)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/games/game1/rematch", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["challenge_id"] != "REMATCH1" {
+		t.Errorf("Expected challenge_id 'REMATCH1', got %s (%v)", rec.Body.String(), err)
+	}
+
+	for path, code := range map[string]int{
+		"/api/games/unknown/rematch": http.StatusNotFound,
+		"/api/games/ai/rematch":      http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != code {
+			t.Errorf("Expected status %d for %s, got %d", code, path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// retryBaseDelay is the wait after the first failure; it doubles with every attempt
+var retryBaseDelay = time.Second
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that RetryWithBackoff returns it immediately
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// RetryWithBackoff calls fn up to attempts times, doubling the delay between
+// attempts, until it succeeds or returns a Permanent error. It returns the last error.
+func RetryWithBackoff(attempts int, fn func() error) error {
+	return RetryWithBackoffContext(context.Background(), attempts, fn)
+}
+
+// RetryWithBackoffContext is RetryWithBackoff with waits that end early when ctx is
+// done, in which case it returns ctx.Err()
+func RetryWithBackoffContext(ctx context.Context, attempts int, fn func() error) error {
+	var err error
+	delay := retryBaseDelay
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt < attempts {
+			log.Printf("Attempt %d/%d failed: %v; retrying in %v", attempt, attempts, err, delay)
+			if err := sleepContext(ctx, delay); err != nil {
+				return err
+			}
+			delay *= 2
+		}
+	}
+	return err
+}
+
+// sleepContext waits for d or until ctx is done, returning ctx.Err() in the latter case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
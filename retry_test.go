@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func withFastRetries(t *testing.T) {
+	t.Helper()
+	original := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = original })
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	withFastRetries(t)
+
+	calls := 0
+	err := RetryWithBackoff(5, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporarily unavailable")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third call, got err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = RetryWithBackoff(3, func() error {
+		calls++
+		return errors.New("still down")
+	})
+	if err == nil || err.Error() != "still down" || calls != 3 {
+		t.Errorf("Expected last error after 3 calls, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRetryWithBackoff_Permanent(t *testing.T) {
+	withFastRetries(t)
+
+	calls := 0
+	cause := errors.New("not a bot account")
+	err := RetryWithBackoff(5, func() error {
+		calls++
+		return Permanent(cause)
+	})
+	if calls != 1 {
+		t.Errorf("Expected a permanent error to stop retries, got %d calls", calls)
+	}
+	if err != cause {
+		t.Errorf("Expected the unwrapped cause, got %v", err)
+	}
+}
+
+func TestRetryWithBackoffContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- RetryWithBackoffContext(ctx, 5, func() error {
+			calls++
+			return errors.New("still down")
+		})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected the wait after the first call to be cut short, got %d calls", calls)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RetryWithBackoffContext did not return after cancel")
+	}
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// pieceMoves are the step directions of each piece kind; sliders repeat their
+// steps until blocked
+var pieceMoves = map[rune][][2]int{
+	'n': {{-2, -1}, {-2, 1}, {-1, -2}, {-1, 2}, {1, -2}, {1, 2}, {2, -1}, {2, 1}},
+	'b': {{-1, -1}, {-1, 1}, {1, -1}, {1, 1}},
+	'r': {{-1, 0}, {1, 0}, {0, -1}, {0, 1}},
+	'q': {{-1, -1}, {-1, 1}, {1, -1}, {1, 1}, {-1, 0}, {1, 0}, {0, -1}, {0, 1}},
+	'k': {{-1, -1}, {-1, 1}, {1, -1}, {1, 1}, {-1, 0}, {1, 0}, {0, -1}, {0, 1}},
+}
+
+// attacks reports whether the piece on (r, f) attacks (tr, tf). Pawns attack
+// diagonally forward only.
+func (b *Board) attacks(r, f, tr, tf int) bool {
+	piece := b[r][f]
+	kind := unicode.ToLower(piece)
+	if kind == 'p' {
+		forward := -1
+		if !unicode.IsUpper(piece) {
+			forward = 1
+		}
+		return tr-r == forward && (tf-f == 1 || f-tf == 1)
+	}
+
+	slider := kind == 'b' || kind == 'r' || kind == 'q'
+	for _, step := range pieceMoves[kind] {
+		for row, file := r+step[0], f+step[1]; row >= 0 && row < 8 && file >= 0 && file < 8; row, file = row+step[0], file+step[1] {
+			if row == tr && file == tf {
+				return true
+			}
+			if !slider || b[row][file] != emptySquare {
+				break
+			}
+		}
+	}
+	return false
+}
+
+// isAttacked reports whether any piece of the given colour attacks (r, f)
+func (b *Board) isAttacked(r, f int, byWhite bool) bool {
+	for row := 0; row < 8; row++ {
+		for file := 0; file < 8; file++ {
+			piece := b[row][file]
+			if piece != emptySquare && unicode.IsUpper(piece) == byWhite && b.attacks(row, file, r, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// leavesKingInCheck reports whether playing the UCI move exposes the mover's own king
+func (p *Position) leavesKingInCheck(move string) bool {
+	after := *p
+	if err := after.Play(move); err != nil {
+		return true
+	}
+	king := 'k'
+	if p.WhiteToMove {
+		king = 'K'
+	}
+	for row := 0; row < 8; row++ {
+		for file := 0; file < 8; file++ {
+			if after.Board[row][file] == king {
+				return after.Board.isAttacked(row, file, !p.WhiteToMove)
+			}
+		}
+	}
+	return false
+}
+
+// SANToUCI converts a move in standard algebraic notation, such as "Nbd7",
+// "exd6", "O-O" or "e8=Q+", into UCI notation for this position
+func (p *Position) SANToUCI(san string) (string, error) {
+	move := strings.TrimRight(san, "+#!?")
+	homeRank := "1"
+	if !p.WhiteToMove {
+		homeRank = "8"
+	}
+	castle := ""
+	switch strings.ReplaceAll(move, "0", "O") {
+	case "O-O":
+		castle = "e" + homeRank + "g" + homeRank
+	case "O-O-O":
+		castle = "e" + homeRank + "c" + homeRank
+	}
+	if castle != "" {
+		fromRow, fromFile, _ := parseSquare(castle[0:2])
+		toRow, toFile, _ := parseSquare(castle[2:4])
+		if !p.canCastle(fromRow, fromFile, toRow, toFile) {
+			return "", fmt.Errorf("illegal castling '%s'", san)
+		}
+		return castle, nil
+	}
+
+	promotion := ""
+	if i := strings.IndexByte(move, '='); i >= 0 {
+		promotion = strings.ToLower(move[i+1:])
+		move = move[:i]
+	} else if n := len(move); n > 2 && strings.ContainsRune("QRBN", rune(move[n-1])) && move[n-2] >= '1' && move[n-2] <= '8' {
+		promotion = strings.ToLower(move[n-1:])
+		move = move[:n-1]
+	}
+	if promotion != "" && (len(promotion) != 1 || !strings.ContainsRune("qrbn", rune(promotion[0]))) {
+		return "", fmt.Errorf("invalid promotion in SAN move '%s'", san)
+	}
+
+	kind := 'p'
+	if move != "" && strings.ContainsRune("NBRQK", rune(move[0])) {
+		kind = unicode.ToLower(rune(move[0]))
+		move = move[1:]
+	}
+	if len(move) < 2 {
+		return "", fmt.Errorf("invalid SAN move '%s'", san)
+	}
+	toRow, toFile, err := parseSquare(move[len(move)-2:])
+	if err != nil {
+		return "", fmt.Errorf("invalid SAN move '%s': %v", san, err)
+	}
+	if (promotion != "") != (kind == 'p' && (toRow == 0 || toRow == 7)) {
+		return "", fmt.Errorf("illegal promotion in SAN move '%s'", san)
+	}
+	// Whatever remains before the target square is a capture mark and/or the origin file or rank
+	hint := strings.ReplaceAll(move[:len(move)-2], "x", "")
+	capture := strings.Contains(move, "x")
+
+	piece := kind
+	if p.WhiteToMove {
+		piece = unicode.ToUpper(kind)
+	}
+
+	var candidates []string
+	for row := 0; row < 8; row++ {
+		for file := 0; file < 8; file++ {
+			if p.Board[row][file] != piece {
+				continue
+			}
+			from := squareName(row, file)
+			if !matchesSANHint(from, hint) || !p.canMoveTo(row, file, toRow, toFile, capture) {
+				continue
+			}
+			uci := from + move[len(move)-2:] + promotion
+			if !p.leavesKingInCheck(uci) {
+				candidates = append(candidates, uci)
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("no legal move matches '%s'", san)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("ambiguous SAN move '%s': %s", san, strings.Join(candidates, ", "))
+	}
+}
+
+// matchesSANHint checks a square against the origin file and/or rank given in a SAN move
+func matchesSANHint(square, hint string) bool {
+	for _, c := range hint {
+		if c >= 'a' && c <= 'h' && byte(c) != square[0] {
+			return false
+		}
+		if c >= '1' && c <= '8' && byte(c) != square[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// canMoveTo reports whether the piece on (r, f) can move to (tr, tf), ignoring checks
+func (p *Position) canMoveTo(r, f, tr, tf int, capture bool) bool {
+	piece := p.Board[r][f]
+	target := p.Board[tr][tf]
+	if target != emptySquare && unicode.IsUpper(target) == unicode.IsUpper(piece) {
+		return false
+	}
+	if unicode.ToLower(piece) != 'p' {
+		return p.Board.attacks(r, f, tr, tf)
+	}
+
+	if capture {
+		return p.Board.attacks(r, f, tr, tf) && (target != emptySquare || squareName(tr, tf) == p.EnPassant)
+	}
+	forward, startRow := -1, 6
+	if !unicode.IsUpper(piece) {
+		forward, startRow = 1, 1
+	}
+	if tf != f || target != emptySquare {
+		return false
+	}
+	if tr-r == forward {
+		return true
+	}
+	return r == startRow && tr-r == 2*forward && p.Board[r+forward][f] == emptySquare
+}
+
+// canCastle reports whether the king on (r, f) may castle to (tr, tf): the right must
+// still be held, the squares between king and rook must be empty, and the king may not
+// start on, pass through or land on an attacked square
+func (p *Position) canCastle(r, f, tr, tf int) bool {
+	white := p.WhiteToMove
+	homeRow, right := 0, 'k'
+	if white {
+		homeRow, right = 7, 'K'
+	}
+	rookFile, between := 7, []int{5, 6}
+	if tf < f {
+		rookFile, between, right = 0, []int{1, 2, 3}, right+('q'-'k')
+	}
+	king, rook := 'k', 'r'
+	if white {
+		king, rook = 'K', 'R'
+	}
+	if p.Board[r][f] != king || r != homeRow || tr != homeRow || f != 4 || !strings.ContainsRune(p.Castling, right) || p.Board[homeRow][rookFile] != rook {
+		return false
+	}
+	for _, file := range between {
+		if p.Board[homeRow][file] != emptySquare {
+			return false
+		}
+	}
+	step := 1
+	if tf < f {
+		step = -1
+	}
+	for file := f; file != tf+step; file += step {
+		if p.Board.isAttacked(homeRow, file, !white) {
+			return false
+		}
+	}
+	return true
+}
+
+// Move is a move in both UCI and standard algebraic notation
+type Move struct {
+	UCI string
+	SAN string
+}
+
+// hasLegalMove reports whether the side to move has any legal move. Castling is
+// not considered, which is safe for mate detection because it is never a way out of check.
+func (p *Position) hasLegalMove() bool {
+	for r := 0; r < 8; r++ {
+		for f := 0; f < 8; f++ {
+			piece := p.Board[r][f]
+			if piece == emptySquare || unicode.IsUpper(piece) != p.WhiteToMove {
+				continue
+			}
+			for tr := 0; tr < 8; tr++ {
+				for tf := 0; tf < 8; tf++ {
+					capture := p.Board[tr][tf] != emptySquare || squareName(tr, tf) == p.EnPassant
+					if !p.canMoveTo(r, f, tr, tf, capture) {
+						continue
+					}
+					move := squareName(r, f) + squareName(tr, tf)
+					if unicode.ToLower(piece) == 'p' && (tr == 0 || tr == 7) {
+						move += "q"
+					}
+					if !p.leavesKingInCheck(move) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// promotionPieces are the pieces a pawn can promote to, in UCI suffix form
+var promotionPieces = []string{"q", "r", "b", "n"}
+
+// legalMoves returns every legal move for the side to move in UCI notation,
+// including castling and all four promotions
+func (p *Position) legalMoves() []string {
+	var moves []string
+	for r := 0; r < 8; r++ {
+		for f := 0; f < 8; f++ {
+			piece := p.Board[r][f]
+			if piece == emptySquare || unicode.IsUpper(piece) != p.WhiteToMove {
+				continue
+			}
+			for tr := 0; tr < 8; tr++ {
+				for tf := 0; tf < 8; tf++ {
+					move := squareName(r, f) + squareName(tr, tf)
+					if unicode.ToLower(piece) != 'p' || (tr != 0 && tr != 7) {
+						if p.checkLegal(move) == nil {
+							moves = append(moves, move)
+						}
+						continue
+					}
+					for _, promotion := range promotionPieces {
+						if p.checkLegal(move+promotion) == nil {
+							moves = append(moves, move+promotion)
+						}
+					}
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// checkLegal returns an error unless move is a legal UCI move for the side to move,
+// including castling rights and promotion suffixes
+func (p *Position) checkLegal(move string) error {
+	if !isUCIMove(move) {
+		return fmt.Errorf("invalid move '%s'", move)
+	}
+	fromRow, fromFile, _ := parseSquare(move[0:2])
+	toRow, toFile, _ := parseSquare(move[2:4])
+	piece := p.Board[fromRow][fromFile]
+	if piece == emptySquare || unicode.IsUpper(piece) != p.WhiteToMove {
+		return fmt.Errorf("no piece of the side to move on %s for move '%s'", move[0:2], move)
+	}
+	kind := unicode.ToLower(piece)
+	promotes := kind == 'p' && (toRow == 0 || toRow == 7)
+	if promotes != (len(move) == 5) {
+		return fmt.Errorf("illegal promotion in move '%s'", move)
+	}
+	if kind == 'k' && (toFile-fromFile == 2 || fromFile-toFile == 2) {
+		if !p.canCastle(fromRow, fromFile, toRow, toFile) {
+			return fmt.Errorf("illegal move '%s'", move)
+		}
+		return nil
+	}
+	capture := p.Board[toRow][toFile] != emptySquare || (kind == 'p' && move[2:4] == p.EnPassant)
+	if !p.canMoveTo(fromRow, fromFile, toRow, toFile, capture) || p.leavesKingInCheck(move) {
+		return fmt.Errorf("illegal move '%s'", move)
+	}
+	return nil
+}
+
+// UCIToSAN converts a UCI move into standard algebraic notation for this position,
+// including disambiguation and the check (+) or mate (#) suffix
+func (p *Position) UCIToSAN(move string) (string, error) {
+	if err := p.checkLegal(move); err != nil {
+		return "", err
+	}
+	fromRow, fromFile, _ := parseSquare(move[0:2])
+	toRow, toFile, _ := parseSquare(move[2:4])
+	piece := p.Board[fromRow][fromFile]
+	kind := unicode.ToLower(piece)
+	capture := p.Board[toRow][toFile] != emptySquare || (kind == 'p' && move[2:4] == p.EnPassant)
+
+	var san string
+	switch {
+	case kind == 'k' && toFile-fromFile == 2:
+		san = "O-O"
+	case kind == 'k' && fromFile-toFile == 2:
+		san = "O-O-O"
+	case kind == 'p':
+		if capture {
+			san = move[0:1] + "x"
+		}
+		san += move[2:4]
+		if len(move) == 5 {
+			san += "=" + strings.ToUpper(move[4:5])
+		}
+	default:
+		// Disambiguate against other pieces of the same kind that could also reach the square
+		sameFile, sameRank, ambiguous := false, false, false
+		for r := 0; r < 8; r++ {
+			for f := 0; f < 8; f++ {
+				if (r == fromRow && f == fromFile) || p.Board[r][f] != piece {
+					continue
+				}
+				if !p.canMoveTo(r, f, toRow, toFile, capture) || p.leavesKingInCheck(squareName(r, f)+move[2:4]) {
+					continue
+				}
+				ambiguous = true
+				sameFile = sameFile || f == fromFile
+				sameRank = sameRank || r == fromRow
+			}
+		}
+		san = string(unicode.ToUpper(kind))
+		switch {
+		case ambiguous && !sameFile:
+			san += move[0:1]
+		case ambiguous && !sameRank:
+			san += move[1:2]
+		case ambiguous:
+			san += move[0:2]
+		}
+		if capture {
+			san += "x"
+		}
+		san += move[2:4]
+	}
+
+	after := *p
+	if err := after.Play(move); err != nil {
+		return "", err
+	}
+	for r := 0; r < 8; r++ {
+		for f := 0; f < 8; f++ {
+			king := 'k'
+			if after.WhiteToMove {
+				king = 'K'
+			}
+			if after.Board[r][f] != king || !after.Board.isAttacked(r, f, !after.WhiteToMove) {
+				continue
+			}
+			if after.hasLegalMove() {
+				return san + "+", nil
+			}
+			return san + "#", nil
+		}
+	}
+	return san, nil
+}
+
+// movesWithSAN plays UCI moves from initialFEN (the standard starting position when
+// empty) and returns each with its SAN, so the notation is only computed once per move
+func movesWithSAN(moves []string, initialFEN string) ([]Move, error) {
+	pos, err := positionAfter(nil, initialFEN)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Move, 0, len(moves))
+	for _, uci := range moves {
+		san, err := pos.UCIToSAN(uci)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Move{UCI: uci, SAN: san})
+		pos.Play(uci)
+	}
+	return result, nil
+}
+
+// describeMove formats a UCI move for logs as "O-O (e1g1)", playing moves from
+// initialFEN (the standard starting position when empty) to find its SAN. A move
+// that cannot be converted is returned as it is.
+func describeMove(moves []string, initialFEN, move string) string {
+	pos, err := positionAfter(moves, initialFEN)
+	if err != nil {
+		return move
+	}
+	san, err := pos.UCIToSAN(move)
+	if err != nil {
+		return move
+	}
+	return fmt.Sprintf("%s (%s)", san, move)
+}
@@ -0,0 +1,233 @@
+package main
+
+import "testing"
+
+func TestSANToUCI(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		san      string
+		expected string
+	}{
+		{"pawn push", startFEN, "e4", "e2e4"},
+		{"single pawn step", startFEN, "d3", "d2d3"},
+		{"knight", startFEN, "Nf3", "g1f3"},
+		{"check suffix", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "Ra8+", "a1a8"},
+		{"white short castle", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "O-O", "e1g1"},
+		{"black long castle", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "O-O-O", "e8c8"},
+		{"pawn capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "exd5", "e4d5"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "exd6", "e5d6"},
+		{"promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8=Q", "e7e8q"},
+		{"promotion without equals", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8N", "e7e8n"},
+		{"capture promotion", "3r4/4P3/8/8/8/8/k7/4K3 w - - 0 1", "exd8=R#", "e7d8r"},
+		{"file disambiguation", "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "Nbd2", "b1d2"},
+		{"rank disambiguation", "4k3/R7/8/8/8/8/8/R3K3 w - - 0 1", "R1a4", "a1a4"},
+		{"pinned piece excluded", "4k3/8/8/8/8/8/2N5/1b2KN2 w - - 0 1", "Nd2", "f1d2"},
+		{"black knight", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", "Nc6", "b8c6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, err := parseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("Invalid test FEN: %v", err)
+			}
+			move, err := pos.SANToUCI(tt.san)
+			if err != nil {
+				t.Fatalf("SANToUCI(%s) failed: %v", tt.san, err)
+			}
+			if move != tt.expected {
+				t.Errorf("Expected move '%s', got '%s'", tt.expected, move)
+			}
+		})
+	}
+}
+
+func TestSANToUCI_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		san  string
+	}{
+		{"no such move", startFEN, "e5"},
+		{"ambiguous", "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "Nd2"},
+		{"bad square", startFEN, "Nz3"},
+		{"blocked bishop", startFEN, "Bc4"},
+		{"bad promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8=K"},
+		{"missing promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8"},
+		{"castle through pieces", startFEN, "O-O"},
+		{"castle without right", "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", "O-O"},
+		{"empty", startFEN, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, err := parseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("Invalid test FEN: %v", err)
+			}
+			if move, err := pos.SANToUCI(tt.san); err == nil {
+				t.Errorf("Expected error for '%s', got move '%s'", tt.san, move)
+			}
+		})
+	}
+}
+
+func TestUCIToSAN(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		move     string
+		expected string
+	}{
+		{"pawn push", startFEN, "e2e4", "e4"},
+		{"knight", startFEN, "g1f3", "Nf3"},
+		{"castle short", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O"},
+		{"castle long", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8c8", "O-O-O"},
+		{"pawn capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", "exd5"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", "exd6"},
+		{"promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8q", "e8=Q"},
+		{"file disambiguation", "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "b1d2", "Nbd2"},
+		{"rank disambiguation", "4k3/R7/8/8/8/8/8/R3K3 w - - 0 1", "a1a4", "R1a4"},
+		{"pinned piece needs no disambiguation", "4k3/8/8/8/8/8/2N5/1b2KN2 w - - 0 1", "f1d2", "Nd2"},
+		{"check", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", "Ra8+"},
+		{"mate", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "a1a8", "Ra8#"},
+		{"capture with check", "3qk3/8/8/8/8/8/8/3RK3 w - - 0 1", "d1d8", "Rxd8+"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, err := parseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("Invalid test FEN: %v", err)
+			}
+			san, err := pos.UCIToSAN(tt.move)
+			if err != nil {
+				t.Fatalf("UCIToSAN(%s) failed: %v", tt.move, err)
+			}
+			if san != tt.expected {
+				t.Errorf("Expected SAN '%s', got '%s'", tt.expected, san)
+			}
+			// The SAN must lead back to the same move
+			if back, err := pos.SANToUCI(san); err != nil || back != tt.move {
+				t.Errorf("Expected '%s' to convert back to '%s', got '%s' (%v)", san, tt.move, back, err)
+			}
+		})
+	}
+}
+
+func TestUCIToSAN_Illegal(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string
+	}{
+		{"castle through pieces", startFEN, "e1g1"},
+		{"castle without right", "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", "e1g1"},
+		{"castle without rook", "r3k2r/8/8/8/8/8/8/R3K3 w KQkq - 0 1", "e1g1"},
+		{"castle out of check", "r3k2r/8/8/8/8/8/4q3/R3K2R w KQkq - 0 1", "e1g1"},
+		{"castle through attack", "r3k2r/8/8/8/8/5q2/8/R3K2R w KQkq - 0 1", "e1g1"},
+		{"castle into attack", "r3k2r/8/8/8/8/2q5/8/R3K2R w KQkq - 0 1", "e1c1"},
+		{"promotion suffix on a knight", startFEN, "g1f3q"},
+		{"promotion suffix short of the last rank", startFEN, "e2e4q"},
+		{"missing promotion suffix", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e7e8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, err := parseFEN(tt.fen)
+			if err != nil {
+				t.Fatalf("Invalid test FEN: %v", err)
+			}
+			if san, err := pos.UCIToSAN(tt.move); err == nil {
+				t.Errorf("Expected error for '%s', got '%s'", tt.move, san)
+			}
+		})
+	}
+}
+
+func TestMovesWithSAN(t *testing.T) {
+	moves, err := movesWithSAN([]string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5"}, "")
+	if err != nil {
+		t.Fatalf("movesWithSAN() failed: %v", err)
+	}
+	expected := []string{"e4", "e5", "Nf3", "Nc6", "Bb5"}
+	for i, m := range moves {
+		if m.SAN != expected[i] {
+			t.Errorf("Move %d: expected SAN '%s', got '%s' (%s)", i+1, expected[i], m.SAN, m.UCI)
+		}
+	}
+
+	for _, bad := range [][]string{{"e2e5"}, {"e3e4"}, {"e2e4", "e2e4"}} {
+		if _, err := movesWithSAN(bad, ""); err == nil {
+			t.Errorf("Expected error for illegal moves %v, but got nil", bad)
+		}
+	}
+}
+
+func TestDescribeMove(t *testing.T) {
+	tests := []struct {
+		initialFEN string
+		moves      []string
+		move       string
+		expected   string
+	}{
+		{"", []string{"e2e4", "e7e5"}, "g1f3", "Nf3 (g1f3)"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", nil, "e1g1", "O-O (e1g1)"},
+		{"4k3/8/8/8/8/8/8/R3K3 w - - 0 1", nil, "a1a8", "Ra8+ (a1a8)"},
+		{"", nil, "e2e5", "e2e5"},
+	}
+
+	for _, tt := range tests {
+		if got := describeMove(tt.moves, tt.initialFEN, tt.move); got != tt.expected {
+			t.Errorf("describeMove(%v, %s): expected '%s', got '%s'", tt.moves, tt.move, tt.expected, got)
+		}
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		moves    []string
+		count    int
+		includes []string
+		excludes []string
+	}{
+		{"start position", "", nil, 20, []string{"e2e4", "g1f3"}, []string{"e1g1"}},
+		{"after 1. e4", "", []string{"e2e4"}, 20, []string{"e7e5", "b8c6"}, nil},
+		{"castling both sides", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", nil, 26, []string{"e1g1", "e1c1"}, nil},
+		{"no castling through check", "r3k2r/8/8/8/8/8/5r2/R3K2R w KQkq - 0 1", nil, 0, []string{"e1d1"}, []string{"e1g1", "e1f1"}},
+		{"all promotions", "8/4P3/8/8/8/8/8/k6K w - - 0 1", nil, 0, []string{"e7e8q", "e7e8r", "e7e8b", "e7e8n"}, []string{"e7e8"}},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", nil, 0, []string{"e5d6"}, nil},
+		{"checkmate", "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", nil, 0, nil, nil},
+	}
+
+	for _, tt := range tests {
+		pos, err := positionAfter(tt.moves, tt.fen)
+		if err != nil {
+			t.Fatalf("%s: positionAfter() failed: %v", tt.name, err)
+		}
+		legal := pos.legalMoves()
+		if tt.count > 0 && len(legal) != tt.count {
+			t.Errorf("%s: expected %d legal moves, got %d: %v", tt.name, tt.count, len(legal), legal)
+		}
+		set := map[string]bool{}
+		for _, m := range legal {
+			set[m] = true
+		}
+		for _, m := range tt.includes {
+			if !set[m] {
+				t.Errorf("%s: expected %s to be legal, got %v", tt.name, m, legal)
+			}
+		}
+		for _, m := range tt.excludes {
+			if set[m] {
+				t.Errorf("%s: expected %s not to be legal", tt.name, m)
+			}
+		}
+		if tt.name == "checkmate" && len(legal) != 0 {
+			t.Errorf("%s: expected no legal moves, got %v", tt.name, legal)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import "sync"
+
+// ScriptedMoves hands out a predefined sequence of moves in order.
+// It is used for deterministic test games instead of querying the LLM.
+type ScriptedMoves struct {
+	mu    sync.Mutex
+	moves []string
+	next  int
+}
+
+// NewScriptedMoves creates a sequence from the given moves
+func NewScriptedMoves(moves []string) *ScriptedMoves {
+	return &ScriptedMoves{moves: append([]string(nil), moves...)}
+}
+
+// Next returns the next scripted move, or false once the sequence is exhausted
+// and the caller should fall back to the LLM
+func (s *ScriptedMoves) Next() (string, bool) {
+	if s == nil {
+		return "", false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next >= len(s.moves) {
+		return "", false
+	}
+	move := s.moves[s.next]
+	s.next++
+	return move, true
+}
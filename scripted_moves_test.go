@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestScriptedMoves_Next(t *testing.T) {
+	script := NewScriptedMoves([]string{"e2e4", "g1f3"})
+
+	for _, expected := range []string{"e2e4", "g1f3"} {
+		move, ok := script.Next()
+		if !ok || move != expected {
+			t.Fatalf("Expected scripted move '%s', got '%s' (ok=%v)", expected, move, ok)
+		}
+	}
+
+	if move, ok := script.Next(); ok {
+		t.Errorf("Expected sequence to be exhausted, got '%s'", move)
+	}
+}
+
+func TestScriptedMoves_Nil(t *testing.T) {
+	var script *ScriptedMoves
+	if move, ok := script.Next(); ok {
+		t.Errorf("Expected nil sequence to return nothing, got '%s'", move)
+	}
+}
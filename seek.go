@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// seekCooldown is the minimum time between two seeks, so a challenge that is
+// declined or ignored is not immediately followed by another one
+const seekCooldown = 5 * time.Minute
+
+// parseSeekTimeControl parses "minutes+increment" such as "3+2" or "0.5+0"
+func parseSeekTimeControl(tc string) (float64, int, error) {
+	minutesStr, incStr, ok := strings.Cut(tc, "+")
+	if !ok {
+		return 0, 0, fmt.Errorf("time control '%s' must look like '3+2'", tc)
+	}
+	minutes, err := strconv.ParseFloat(strings.TrimSpace(minutesStr), 64)
+	if err != nil || minutes <= 0 {
+		return 0, 0, fmt.Errorf("invalid minutes in time control '%s'", tc)
+	}
+	increment, err := strconv.Atoi(strings.TrimSpace(incStr))
+	if err != nil || increment < 0 {
+		return 0, 0, fmt.Errorf("invalid increment in time control '%s'", tc)
+	}
+	return minutes, increment, nil
+}
+
+// parseRatingRange parses a "min-max" rating range such as "1500-1800"
+func parseRatingRange(r string) (int, int, error) {
+	lowStr, highStr, ok := strings.Cut(r, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("rating range '%s' must look like '1500-1800'", r)
+	}
+	low, errLow := strconv.Atoi(lowStr)
+	high, errHigh := strconv.Atoi(highStr)
+	if errLow != nil || errHigh != nil || low < 0 || low > high {
+		return 0, 0, fmt.Errorf("invalid rating range '%s'", r)
+	}
+	return low, high, nil
+}
+
+// seekOnlineBotsLimit is how many online bots are fetched to pick an opponent from
+const seekOnlineBotsLimit = 50
+
+// onlineBot is a bot listed by GET /api/bot/online with its ratings by speed
+type onlineBot struct {
+	Username string             `json:"username"`
+	Perfs    map[string]botPerf `json:"perfs"`
+}
+
+// botPerf is a user's rating in one speed category
+type botPerf struct {
+	Rating int `json:"rating"`
+}
+
+// getOnlineBots returns up to limit bots that are currently online
+func getOnlineBots(cfg *BotConfig, limit int) ([]onlineBot, error) {
+	req, err := newLichessRequest(cfg, http.MethodGet, "/api/bot/online?nb="+strconv.Itoa(limit), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doLichessRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch online bots: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("fetching online bots failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var bots []onlineBot
+	scanner := newNDJSONScanner(resp.Body)
+	for scanner.Scan() {
+		var bot onlineBot
+		if err := json.Unmarshal(scanner.Bytes(), &bot); err != nil {
+			log.Printf("Skipping malformed online bot entry: %v", err)
+			continue
+		}
+		bots = append(bots, bot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read online bots: %v", err)
+	}
+	return bots, nil
+}
+
+// seekSpeed returns the Lichess rating category of a clock, using the same
+// estimated game duration as Lichess: limit + 40 * increment seconds
+func seekSpeed(limitSeconds, incrementSeconds int) string {
+	switch estimate := limitSeconds + 40*incrementSeconds; {
+	case estimate < 30:
+		return "ultraBullet"
+	case estimate < 180:
+		return "bullet"
+	case estimate < 480:
+		return "blitz"
+	case estimate < 1500:
+		return "rapid"
+	default:
+		return "classical"
+	}
+}
+
+// pickSeekOpponent chooses a random bot other than self whose rating for speed lies
+// in [low, high]; high <= 0 accepts any rating. random(n) returns a value in [0, n).
+func pickSeekOpponent(bots []onlineBot, self, speed string, low, high int, random func(n int) int) (string, bool) {
+	var candidates []string
+	for _, bot := range bots {
+		if bot.Username == "" || strings.EqualFold(bot.Username, self) {
+			continue
+		}
+		if high > 0 {
+			perf, ok := bot.Perfs[speed]
+			if !ok || perf.Rating < low || perf.Rating > high {
+				continue
+			}
+		}
+		candidates = append(candidates, bot.Username)
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	return candidates[random(len(candidates))], true
+}
+
+// seekGame finds a game the way a seek would. Lichess does not let BOT accounts create
+// seeks (POST /api/board/seek is Board API only), so it challenges a random online bot
+// that matches the seek preferences instead. The challenge is tracked in pending, and
+// self, the bot's own username, is never challenged.
+func seekGame(cfg *BotConfig, self string, pending *PendingChallenges) (string, error) {
+	minutes, increment, err := parseSeekTimeControl(cfg.SeekTimeControl)
+	if err != nil {
+		return "", err
+	}
+	limit := int(minutes * 60)
+
+	low, high := 0, 0
+	if cfg.SeekRatingRange != "" {
+		if low, high, err = parseRatingRange(cfg.SeekRatingRange); err != nil {
+			return "", err
+		}
+	}
+
+	bots, err := getOnlineBots(cfg, seekOnlineBotsLimit)
+	if err != nil {
+		return "", err
+	}
+	opponent, ok := pickSeekOpponent(bots, self, seekSpeed(limit, increment), low, high, rand.Intn)
+	if !ok {
+		return "", fmt.Errorf("no online bot matches the seek (%s, rating range '%s')", cfg.SeekTimeControl, cfg.SeekRatingRange)
+	}
+
+	id, err := createChallenge(cfg, opponent, limit, increment, cfg.SeekRated)
+	if err != nil {
+		return "", err
+	}
+	pending.Add(IssuedChallenge{ID: id, Opponent: opponent, Rated: cfg.SeekRated, ClockLimit: limit, ClockIncrement: increment})
+	log.Printf("Seeking a game: challenged %s (%s, rated=%v, challenge %s)", opponent, cfg.SeekTimeControl, cfg.SeekRated, id)
+	return id, nil
+}
+
+// SeekScheduler decides when a new seek should be created
+type SeekScheduler struct {
+	mu         sync.Mutex
+	minActive  int
+	lastSeekAt time.Time
+	now        func() time.Time
+}
+
+// NewSeekScheduler creates a scheduler that seeks while fewer than minActive games are running
+func NewSeekScheduler(minActive int) *SeekScheduler {
+	return &SeekScheduler{minActive: minActive, now: time.Now}
+}
+
+// ShouldSeek reports whether a seek should be created now and, if so, starts the cooldown
+func (s *SeekScheduler) ShouldSeek(activeGames int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if activeGames >= s.minActive {
+		return false
+	}
+	if !s.lastSeekAt.IsZero() && s.now().Sub(s.lastSeekAt) < seekCooldown {
+		return false
+	}
+	s.lastSeekAt = s.now()
+	return true
+}
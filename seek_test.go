@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseSeekTimeControl(t *testing.T) {
+	tests := []struct {
+		input     string
+		minutes   float64
+		increment int
+		wantErr   bool
+	}{
+		{"3+2", 3, 2, false},
+		{"10+0", 10, 0, false},
+		{"0.5+0", 0.5, 0, false},
+		{"3", 0, 0, true},
+		{"0+2", 0, 0, true},
+		{"3+-1", 0, 0, true},
+		{"a+b", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		minutes, increment, err := parseSeekTimeControl(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSeekTimeControl(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (minutes != tt.minutes || increment != tt.increment) {
+			t.Errorf("parseSeekTimeControl(%q) = %v+%d, expected %v+%d", tt.input, minutes, increment, tt.minutes, tt.increment)
+		}
+	}
+}
+
+func TestSeekSpeed(t *testing.T) {
+	tests := []struct {
+		limit, increment int
+		expected         string
+	}{
+		{15, 0, "ultraBullet"},
+		{60, 0, "bullet"},
+		{180, 2, "blitz"},
+		{600, 0, "rapid"},
+		{1800, 0, "classical"},
+	}
+	for _, tt := range tests {
+		if got := seekSpeed(tt.limit, tt.increment); got != tt.expected {
+			t.Errorf("seekSpeed(%d, %d): expected %s, got %s", tt.limit, tt.increment, tt.expected, got)
+		}
+	}
+}
+
+func TestPickSeekOpponent(t *testing.T) {
+	bot := func(name string, blitz int) onlineBot {
+		return onlineBot{Username: name, Perfs: map[string]botPerf{"blitz": {Rating: blitz}}}
+	}
+	bots := []onlineBot{bot("MyBot", 1600), bot("WeakBot", 1200), bot("GoodBot", 1650), bot("StrongBot", 2400)}
+	first := func(n int) int { return 0 }
+
+	if got, ok := pickSeekOpponent(bots, "mybot", "blitz", 1500, 1800, first); !ok || got != "GoodBot" {
+		t.Errorf("Expected GoodBot in range, got '%s' (%v)", got, ok)
+	}
+	if got, ok := pickSeekOpponent(bots, "MyBot", "blitz", 0, 0, first); !ok || got != "WeakBot" {
+		t.Errorf("Expected any bot but self without a range, got '%s' (%v)", got, ok)
+	}
+	if _, ok := pickSeekOpponent(bots, "MyBot", "rapid", 1500, 1800, first); ok {
+		t.Error("Expected no opponent without a rating for the speed")
+	}
+	if _, ok := pickSeekOpponent(bots, "MyBot", "blitz", 3000, 3200, first); ok {
+		t.Error("Expected no opponent outside the rating range")
+	}
+}
+
+func TestSeekGame(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/bot/online":
+			w.Write([]byte(`{"username":"MyBot","perfs":{"blitz":{"rating":1600}}}` + "\n" +
+				`{"username":"WeakBot","perfs":{"blitz":{"rating":1200}}}` + "\n" +
+				`{"username":"GoodBot","perfs":{"blitz":{"rating":1650}}}` + "\n"))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/challenge/GoodBot":
+			r.ParseForm()
+			if r.Form.Get("clock.limit") != "180" || r.Form.Get("clock.increment") != "2" || r.Form.Get("rated") != "true" {
+				t.Errorf("Unexpected challenge form %v", r.Form)
+			}
+			w.Write([]byte(`{"id":"seek1234"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := newTestLichessConfig(server.URL)
+	cfg.SeekTimeControl = "3+2"
+	cfg.SeekRated = true
+	cfg.SeekRatingRange = "1500-1800"
+	pending := NewPendingChallenges()
+
+	id, err := seekGame(cfg, "MyBot", pending)
+	if err != nil || id != "seek1234" {
+		t.Fatalf("Expected challenge seek1234, got '%s' (%v)", id, err)
+	}
+	if list := pending.List(); len(list) != 1 || list[0].Opponent != "GoodBot" || list[0].TimeControl != "3+2" {
+		t.Errorf("Expected the challenge to be tracked as pending, got %+v", list)
+	}
+
+	cfg.SeekRatingRange = "2500-2600"
+	if _, err := seekGame(cfg, "MyBot", pending); err == nil {
+		t.Error("Expected error when no online bot matches")
+	}
+}
+
+func TestSeekScheduler(t *testing.T) {
+	now := time.Now()
+	s := NewSeekScheduler(2)
+	s.now = func() time.Time { return now }
+
+	if s.ShouldSeek(2) {
+		t.Error("Expected no seek with enough active games")
+	}
+	if !s.ShouldSeek(1) {
+		t.Error("Expected seek with too few active games")
+	}
+	if s.ShouldSeek(0) {
+		t.Error("Expected no seek during cooldown")
+	}
+
+	now = now.Add(seekCooldown)
+	if !s.ShouldSeek(0) {
+		t.Error("Expected seek after cooldown expired")
+	}
+}
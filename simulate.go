@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// simulationGameID names the offline game played in SIMULATE_OPPONENT mode
+const simulationGameID = "simulation"
+
+// runSimulation plays the bot against itself without Lichess for up to SIMULATE_MOVES
+// moves (plies), stopping early when the game ends, and returns the game as PGN. White
+// asks SIMULATE_WHITE_MODEL and black SIMULATE_BLACK_MODEL, both defaulting to
+// OPENROUTER_MODEL.
+func runSimulation(cfg *BotConfig) (string, error) {
+	models := map[string]string{
+		"white": simulationModel(cfg, cfg.SimulateWhiteModel),
+		"black": simulationModel(cfg, cfg.SimulateBlackModel),
+	}
+	game := &Game{
+		ID:          simulationGameID,
+		InitialFEN:  startposFEN,
+		StartedAt:   time.Now(),
+		whiteStarts: true,
+		status:      "started",
+	}
+
+	for game.MoveCount() < cfg.SimulateMoves && !game.IsOver() {
+		// The bot plays whichever side is to move
+		game.Color = game.SideToMove()
+		move, err := getBestMoveFromLLM(cfg, game, models[game.Color])
+		if err != nil {
+			return "", fmt.Errorf("simulation stopped after %d moves: %v", game.MoveCount(), err)
+		}
+		game.playSimulatedMove(move)
+	}
+
+	status, winner := game.Status()
+	tags := map[string]string{
+		"Event":  "Simulated game",
+		"Site":   "local",
+		"Date":   game.StartedAt.Format("2006.01.02"),
+		"White":  models["white"],
+		"Black":  models["black"],
+		"Result": pgnResult(status, winner),
+	}
+	return formatPGN(tags, game.Moves(), game.InitialFEN)
+}
+
+// simulationModel returns the model configured for one side of a simulation
+func simulationModel(cfg *BotConfig, model string) string {
+	if model == "" {
+		return cfg.OpenRouterModel
+	}
+	return model
+}
+
+// playSimulatedMove appends move to a simulated game and ends the game on checkmate,
+// stalemate, insufficient material or the fifty-move rule, using the Lichess statuses
+func (g *Game) playSimulatedMove(move string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	mover := "black"
+	if (len(g.moves)%2 == 0) == g.whiteStarts {
+		mover = "white"
+	}
+	g.moves = append(g.moves, move)
+	g.lastMoveAt = time.Now()
+
+	position, err := positionAfter(g.moves, g.InitialFEN)
+	if err != nil {
+		return
+	}
+	switch {
+	case !position.hasLegalMove():
+		sans, err := movesWithSAN(g.moves, g.InitialFEN)
+		if err == nil && strings.HasSuffix(sans[len(sans)-1].SAN, "#") {
+			g.status, g.winner = "mate", mover
+		} else {
+			g.status = "stalemate"
+		}
+	case hasInsufficientMaterial(position.Board), position.HalfMoves >= fiftyMoveHalfMoves:
+		g.status = "draw"
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRunSimulation_ModelPerColor(t *testing.T) {
+	// Each side plays its model's next move from a scripted opening
+	scripts := map[string][]string{
+		"white/model": {"e2e4", "g1f3", "f1c4"},
+		"black/model": {"e7e5", "b8c6", "g8f6"},
+	}
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		script := scripts[req.Model]
+		if len(script) == 0 {
+			t.Errorf("Unexpected request for model '%s'", req.Model)
+			http.Error(w, "no more moves", http.StatusInternalServerError)
+			return
+		}
+		scripts[req.Model] = script[1:]
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"` + script[0] + `"}}]}`))
+	})
+
+	cfg := &BotConfig{
+		OpenRouterModel:       "default/model",
+		SimulateMoves:         6,
+		SimulateWhiteModel:    "white/model",
+		SimulateBlackModel:    "black/model",
+		MaxIllegalMoveRetries: 1,
+	}
+	pgn, err := runSimulation(cfg)
+	if err != nil {
+		t.Fatalf("runSimulation() failed: %v", err)
+	}
+	for _, want := range []string{`[White "white/model"]`, `[Black "black/model"]`, `[Result "*"]`, "1. e4 e5 2. Nf3 Nc6 3. Bc4 Nf6 *"} {
+		if !strings.Contains(pgn, want) {
+			t.Errorf("Expected PGN to contain %q, got:\n%s", want, pgn)
+		}
+	}
+}
+
+func TestPlaySimulatedMove_Checkmate(t *testing.T) {
+	game := &Game{ID: "g", InitialFEN: startposFEN, whiteStarts: true, status: "started"}
+	for _, move := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+		game.playSimulatedMove(move)
+	}
+	if status, winner := game.Status(); status != "mate" || winner != "black" {
+		t.Errorf("Expected black to win by mate, got status '%s' winner '%s'", status, winner)
+	}
+}
+
+func TestRunSimulation_RandomMoves(t *testing.T) {
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no OpenRouter call with DISABLE_LLM set")
+	})
+
+	cfg := &BotConfig{OpenRouterModel: "default/model", SimulateMoves: 10, DisableLLM: true, MaxIllegalMoveRetries: 1}
+	pgn, err := runSimulation(cfg)
+	if err != nil {
+		t.Fatalf("runSimulation() failed: %v", err)
+	}
+	if !strings.Contains(pgn, `[White "default/model"]`) || !strings.Contains(pgn, " 5. ") {
+		t.Errorf("Expected ten moves played by the default model, got:\n%s", pgn)
+	}
+}
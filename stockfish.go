@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EngineLLM       = "llm"
+	EngineStockfish = "stockfish"
+)
+
+// stockfishTimeout bounds a whole UCI session, including process startup
+var stockfishTimeout = 30 * time.Second
+
+// mateScoreCP stands in for a forced mate when a score is reported in centipawns
+const mateScoreCP = 100000
+
+// getBestMoveFromStockfish starts cfg.StockfishPath, sends the game so far (moves
+// from initialFEN, the standard starting position when empty) over UCI and returns
+// the engine's best move in UCI notation.
+func getBestMoveFromStockfish(cfg *BotConfig, moves []string, initialFEN string, depth int) (string, error) {
+	move, _, err := runStockfish(cfg, moves, initialFEN, depth)
+	return move, err
+}
+
+// evaluateWithStockfish returns the engine's evaluation in centipawns from the
+// point of view of the side to move. Forced mates are reported as ±mateScoreCP.
+func evaluateWithStockfish(cfg *BotConfig, moves []string, initialFEN string, depth int) (int, error) {
+	_, score, err := runStockfish(cfg, moves, initialFEN, depth)
+	return score, err
+}
+
+// runStockfish runs a single UCI search and returns the best move and the last reported score
+func runStockfish(cfg *BotConfig, moves []string, initialFEN string, depth int) (string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), stockfishTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cfg.StockfishPath)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return "", 0, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", 0, fmt.Errorf("failed to start stockfish: %v", err)
+	}
+	defer cmd.Wait()
+	defer stdin.Close()
+
+	scanner := bufio.NewScanner(stdout)
+	send := func(command string) error {
+		_, err := io.WriteString(stdin, command+"\n")
+		return err
+	}
+	// waitFor reads engine output until a line starting with prefix arrives,
+	// remembering the score from any "info ... score cp X" lines on the way
+	score := 0
+	waitFor := func(prefix string) (string, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, prefix) {
+				return line, nil
+			}
+			if s, ok := parseUCIScore(line); ok {
+				score = s
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("stockfish exited before sending '%s'", prefix)
+	}
+
+	if err := send("uci"); err != nil {
+		return "", 0, err
+	}
+	if _, err := waitFor("uciok"); err != nil {
+		return "", 0, err
+	}
+	if err := send("isready"); err != nil {
+		return "", 0, err
+	}
+	if _, err := waitFor("readyok"); err != nil {
+		return "", 0, err
+	}
+
+	if err := send(uciPositionCommand(moves, initialFEN)); err != nil {
+		return "", 0, err
+	}
+	if err := send(fmt.Sprintf("go depth %d", depth)); err != nil {
+		return "", 0, err
+	}
+
+	line, err := waitFor("bestmove")
+	if err != nil {
+		return "", 0, err
+	}
+	send("quit")
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[1] == "(none)" {
+		return "", score, fmt.Errorf("stockfish returned no move: %q", line)
+	}
+	return fields[1], score, nil
+}
+
+// uciPositionCommand returns the UCI "position" command for moves played from
+// initialFEN, using startpos for the standard starting position
+func uciPositionCommand(moves []string, initialFEN string) string {
+	position := "position startpos"
+	if initialFEN != "" && initialFEN != "startpos" {
+		position = "position fen " + initialFEN
+	}
+	if len(moves) > 0 {
+		position += " moves " + strings.Join(moves, " ")
+	}
+	return position
+}
+
+// parseUCIScore extracts the score in centipawns from a UCI info line
+func parseUCIScore(line string) (int, bool) {
+	fields := strings.Fields(line)
+	for i := 0; i+2 < len(fields); i++ {
+		if fields[i] != "score" {
+			continue
+		}
+		value, err := strconv.Atoi(fields[i+2])
+		if err != nil {
+			return 0, false
+		}
+		switch fields[i+1] {
+		case "cp":
+			return value, true
+		case "mate":
+			// "mate 0" means the side to move is already mated
+			if value <= 0 {
+				return -mateScoreCP, true
+			}
+			return mateScoreCP, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeStubStockfish creates a shell script that speaks just enough UCI for the tests
+// and records every command it receives to a log file.
+func writeStubStockfish(t *testing.T, bestMoveLine string) (path, logPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	path = filepath.Join(dir, "stockfish")
+	logPath = filepath.Join(dir, "commands.log")
+
+	script := `#!/bin/sh
+while read line; do
+  echo "$line" >> "` + logPath + `"
+  case "$line" in
+    uci) echo "id name StubFish"; echo "uciok" ;;
+    isready) echo "readyok" ;;
+    go*) echo "info depth 1 score cp 20"; echo "` + bestMoveLine + `" ;;
+    quit) exit 0 ;;
+  esac
+done
+`
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write stub stockfish: %v", err)
+	}
+	return path, logPath
+}
+
+func TestGetBestMoveFromStockfish(t *testing.T) {
+	path, logPath := writeStubStockfish(t, "bestmove e7e5 ponder g1f3")
+	cfg := &BotConfig{StockfishPath: path}
+
+	move, err := getBestMoveFromStockfish(cfg, []string{"e2e4"}, "", 12)
+	if err != nil {
+		t.Fatalf("getBestMoveFromStockfish() failed: %v", err)
+	}
+	if move != "e7e5" {
+		t.Errorf("Expected move 'e7e5', got '%s'", move)
+	}
+
+	logged, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read command log: %v", err)
+	}
+	for _, expected := range []string{"uci", "isready", "position startpos moves e2e4", "go depth 12"} {
+		if !strings.Contains(string(logged), expected+"\n") {
+			t.Errorf("Expected stockfish to receive '%s', got:\n%s", expected, logged)
+		}
+	}
+}
+
+func TestGetBestMoveFromStockfish_PositionCommand(t *testing.T) {
+	const endgameFEN = "8/8/8/4k3/8/8/4P3/4K3 w - - 0 1"
+	tests := []struct {
+		name       string
+		moves      []string
+		initialFEN string
+		expected   string
+	}{
+		{"start position without moves", nil, "", "position startpos"},
+		{"start position with moves", []string{"e2e4", "e7e5"}, "", "position startpos moves e2e4 e7e5"},
+		{"startpos marker", []string{"d2d4"}, "startpos", "position startpos moves d2d4"},
+		{"custom position without moves", nil, endgameFEN, "position fen " + endgameFEN},
+		{"custom position with moves", []string{"e2e4", "e5d4"}, endgameFEN, "position fen " + endgameFEN + " moves e2e4 e5d4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, logPath := writeStubStockfish(t, "bestmove e1d2")
+			if _, err := getBestMoveFromStockfish(&BotConfig{StockfishPath: path}, tt.moves, tt.initialFEN, 8); err != nil {
+				t.Fatalf("getBestMoveFromStockfish() failed: %v", err)
+			}
+
+			logged, err := os.ReadFile(logPath)
+			if err != nil {
+				t.Fatalf("Failed to read command log: %v", err)
+			}
+			var positions []string
+			for _, line := range strings.Split(string(logged), "\n") {
+				if strings.HasPrefix(line, "position") {
+					positions = append(positions, line)
+				}
+			}
+			if len(positions) != 1 || positions[0] != tt.expected {
+				t.Errorf("Expected a single '%s' command, got %q", tt.expected, positions)
+			}
+		})
+	}
+}
+
+func TestGetBestMoveFromStockfish_NoMove(t *testing.T) {
+	path, _ := writeStubStockfish(t, "bestmove (none)")
+	cfg := &BotConfig{StockfishPath: path}
+
+	if _, err := getBestMoveFromStockfish(cfg, nil, "", 5); err == nil {
+		t.Error("Expected error when stockfish has no move, but got nil")
+	}
+}
+
+func TestGetBestMoveFromStockfish_MissingBinary(t *testing.T) {
+	cfg := &BotConfig{StockfishPath: filepath.Join(t.TempDir(), "missing")}
+
+	if _, err := getBestMoveFromStockfish(cfg, nil, "", 5); err == nil {
+		t.Error("Expected error for missing stockfish binary, but got nil")
+	}
+}
+
+func TestParseUCIScore(t *testing.T) {
+	tests := []struct {
+		line  string
+		score int
+		ok    bool
+	}{
+		{"info depth 12 seldepth 18 score cp 24 nodes 12345 pv e2e4", 24, true},
+		{"info depth 20 score cp -310 upperbound", -310, true},
+		{"info depth 8 score mate 3 pv d1h5", mateScoreCP, true},
+		{"info depth 8 score mate -2", -mateScoreCP, true},
+		{"info depth 0 score mate 0", -mateScoreCP, true},
+		{"info string NNUE evaluation enabled", 0, false},
+	}
+	for _, tt := range tests {
+		score, ok := parseUCIScore(tt.line)
+		if score != tt.score || ok != tt.ok {
+			t.Errorf("parseUCIScore(%q) = %d, %v; expected %d, %v", tt.line, score, ok, tt.score, tt.ok)
+		}
+	}
+}
+
+func TestEvaluateWithStockfish(t *testing.T) {
+	path, _ := writeStubStockfish(t, "bestmove e7e5")
+	score, err := evaluateWithStockfish(&BotConfig{StockfishPath: path}, []string{"e2e4"}, "", 10)
+	if err != nil {
+		t.Fatalf("evaluateWithStockfish() failed: %v", err)
+	}
+	if score != 20 {
+		t.Errorf("Expected score 20 from the stub's info line, got %d", score)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Event types sent on the Lichess /api/stream/event stream
+const (
+	EventChallenge         = "challenge"
+	EventChallengeCanceled = "challengeCanceled"
+	EventChallengeDeclined = "challengeDeclined"
+	EventGameStart         = "gameStart"
+	EventGameFinish        = "gameFinish"
+)
+
+// StreamEvent is one line of the Lichess event stream. Type says which event it
+// is and Data holds the event's payload (the "challenge" or "game" object) to be
+// decoded with the typed accessor for that type.
+type StreamEvent struct {
+	Type string
+	Data json.RawMessage
+}
+
+// ChallengeEvent is the payload of challenge, challengeCanceled and challengeDeclined events
+type ChallengeEvent struct {
+	Challenge     ChallengeData
+	DeclineReason string
+}
+
+// EventOpponent is the opponent of a game in gameStart and gameFinish events
+type EventOpponent struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Rating   int    `json:"rating"`
+}
+
+// GameStartEvent is the payload of a gameStart event
+type GameStartEvent struct {
+	GameID   string        `json:"gameId"`
+	FullID   string        `json:"fullId"`
+	Color    string        `json:"color"`
+	FEN      string        `json:"fen"`
+	IsMyTurn bool          `json:"isMyTurn"`
+	LastMove string        `json:"lastMove"`
+	Source   string        `json:"source"`
+	Speed    string        `json:"speed"`
+	Rated    bool          `json:"rated"`
+	Opponent EventOpponent `json:"opponent"`
+}
+
+// GameFinishEvent is the payload of a gameFinish event
+type GameFinishEvent struct {
+	GameID   string        `json:"gameId"`
+	Color    string        `json:"color"`
+	Opponent EventOpponent `json:"opponent"`
+	Status   struct {
+		Name string `json:"name"`
+	} `json:"status"`
+	Winner string `json:"winner"` // "white" or "black", empty for draws
+}
+
+// ParseStreamEvent decodes an event stream line. Keep-alive (empty) lines
+// are not events and return an error.
+func ParseStreamEvent(line string) (*StreamEvent, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, fmt.Errorf("empty event line")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil, fmt.Errorf("invalid event JSON: %v", err)
+	}
+	event := &StreamEvent{}
+	if err := json.Unmarshal(fields["type"], &event.Type); err != nil || event.Type == "" {
+		return nil, fmt.Errorf("event has no type: %s", line)
+	}
+
+	switch event.Type {
+	case EventChallenge, EventChallengeCanceled, EventChallengeDeclined:
+		event.Data = fields["challenge"]
+	case EventGameStart, EventGameFinish:
+		event.Data = fields["game"]
+	default:
+		// Unknown event types keep the whole line so they can still be logged or inspected
+		event.Data = json.RawMessage(line)
+		return event, nil
+	}
+	if len(event.Data) == 0 {
+		return nil, fmt.Errorf("%s event has no payload", event.Type)
+	}
+	return event, nil
+}
+
+// Challenge decodes the payload of a challenge event
+func (e *StreamEvent) Challenge() (ChallengeEvent, error) {
+	var ce ChallengeEvent
+	switch e.Type {
+	case EventChallenge, EventChallengeCanceled, EventChallengeDeclined:
+	default:
+		return ce, fmt.Errorf("%s event is not a challenge event", e.Type)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(e.Data, &raw); err != nil {
+		return ce, fmt.Errorf("invalid challenge payload: %v", err)
+	}
+	var err error
+	if ce.Challenge, err = parseChallengeData(raw); err != nil {
+		return ce, err
+	}
+	if ce.DeclineReason, err = stringField(raw, "declineReason"); err != nil {
+		return ce, err
+	}
+	return ce, nil
+}
+
+// GameStart decodes the payload of a gameStart event
+func (e *StreamEvent) GameStart() (GameStartEvent, error) {
+	var gs GameStartEvent
+	if e.Type != EventGameStart {
+		return gs, fmt.Errorf("%s event is not a gameStart event", e.Type)
+	}
+	if err := json.Unmarshal(e.Data, &gs); err != nil {
+		return gs, fmt.Errorf("invalid gameStart payload: %v", err)
+	}
+	return gs, nil
+}
+
+// GameFinish decodes the payload of a gameFinish event
+func (e *StreamEvent) GameFinish() (GameFinishEvent, error) {
+	var gf GameFinishEvent
+	if e.Type != EventGameFinish {
+		return gf, fmt.Errorf("%s event is not a gameFinish event", e.Type)
+	}
+	if err := json.Unmarshal(e.Data, &gf); err != nil {
+		return gf, fmt.Errorf("invalid gameFinish payload: %v", err)
+	}
+	return gf, nil
+}
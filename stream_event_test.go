@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStreamEvent_Challenge(t *testing.T) {
+	event, err := ParseStreamEvent(`{"type":"challenge","challenge":{"id":"c1","status":"created","rated":true,` +
+		`"variant":{"key":"standard"},"timeControl":{"type":"clock","limit":180,"increment":2},` +
+		`"challenger":{"id":"alice","name":"Alice","title":"FM","rating":2200}}}`)
+	if err != nil {
+		t.Fatalf("ParseStreamEvent() failed: %v", err)
+	}
+	if event.Type != EventChallenge {
+		t.Errorf("Expected type '%s', got '%s'", EventChallenge, event.Type)
+	}
+
+	ce, err := event.Challenge()
+	if err != nil {
+		t.Fatalf("Challenge() failed: %v", err)
+	}
+	c := ce.Challenge
+	if c.ID != "c1" || !c.Rated || c.Variant != "standard" || c.Challenger.Title != "FM" || c.TimeControlString() != "3+2" {
+		t.Errorf("Unexpected challenge %+v", c)
+	}
+	if _, err := event.GameStart(); err == nil {
+		t.Error("Expected error decoding a challenge event as gameStart")
+	}
+}
+
+func TestParseStreamEvent_ChallengeDeclined(t *testing.T) {
+	event, err := ParseStreamEvent(`{"type":"challengeDeclined","challenge":{"id":"c1","declineReason":"Too fast"}}`)
+	if err != nil {
+		t.Fatalf("ParseStreamEvent() failed: %v", err)
+	}
+	ce, err := event.Challenge()
+	if err != nil {
+		t.Fatalf("Challenge() failed: %v", err)
+	}
+	if ce.Challenge.ID != "c1" || ce.DeclineReason != "Too fast" {
+		t.Errorf("Unexpected challenge event %+v", ce)
+	}
+}
+
+func TestParseStreamEvent_GameStart(t *testing.T) {
+	event, err := ParseStreamEvent(`{"type":"gameStart","game":{"gameId":"abcd1234","fullId":"abcd1234wxyz",` +
+		`"color":"black","fen":"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1","isMyTurn":true,` +
+		`"lastMove":"e2e4","speed":"blitz","rated":true,"opponent":{"id":"bob","username":"Bob","rating":1800}}}`)
+	if err != nil {
+		t.Fatalf("ParseStreamEvent() failed: %v", err)
+	}
+	gs, err := event.GameStart()
+	if err != nil {
+		t.Fatalf("GameStart() failed: %v", err)
+	}
+	if gs.GameID != "abcd1234" || gs.Color != "black" || !gs.IsMyTurn || gs.LastMove != "e2e4" || gs.Opponent.Username != "Bob" || gs.Opponent.Rating != 1800 {
+		t.Errorf("Unexpected gameStart %+v", gs)
+	}
+}
+
+func TestParseStreamEvent_GameFinish(t *testing.T) {
+	event, err := ParseStreamEvent(`{"type":"gameFinish","game":{"gameId":"abcd1234","color":"white",` +
+		`"status":{"id":31,"name":"resign"},"winner":"white","opponent":{"username":"Bob"}}}`)
+	if err != nil {
+		t.Fatalf("ParseStreamEvent() failed: %v", err)
+	}
+	gf, err := event.GameFinish()
+	if err != nil {
+		t.Fatalf("GameFinish() failed: %v", err)
+	}
+	if gf.GameID != "abcd1234" || gf.Status.Name != "resign" || gf.Winner != "white" {
+		t.Errorf("Unexpected gameFinish %+v", gf)
+	}
+	if outcome := gameOutcome(gf.Status.Name, gf.Winner, gf.Color); outcome != OutcomeWin {
+		t.Errorf("Expected outcome '%s', got '%s'", OutcomeWin, outcome)
+	}
+}
+
+func TestParseStreamEvent_UnknownType(t *testing.T) {
+	line := `{"type":"somethingNew","value":1}`
+	event, err := ParseStreamEvent(line)
+	if err != nil {
+		t.Fatalf("ParseStreamEvent() failed: %v", err)
+	}
+	if event.Type != "somethingNew" || string(event.Data) != line {
+		t.Errorf("Expected the whole line as data, got %+v", event)
+	}
+}
+
+func TestParseStreamEvent_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantErr string
+	}{
+		{"keep-alive", "", "empty"},
+		{"invalid JSON", "{not json", "invalid"},
+		{"missing type", `{"game":{"gameId":"x"}}`, "no type"},
+		{"missing payload", `{"type":"gameStart"}`, "no payload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStreamEvent(tt.line)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error mentioning '%s', got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultStudyRecordFile = "study_records.json"
+	studyChallengeDelay    = 30 * time.Second
+	studyClockLimit        = 300
+	studyClockIncrement    = 3
+)
+
+// StudyOpening is an opening line the bot practises against study opponents
+type StudyOpening struct {
+	Name  string
+	Moves []string
+}
+
+// studyOpenings are played in order, so each opponent sees every line once before any repeats
+var studyOpenings = []StudyOpening{
+	{"Ruy Lopez", []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5"}},
+	{"Sicilian Defence", []string{"e2e4", "c7c5"}},
+	{"French Defence", []string{"e2e4", "e7e6", "d2d4", "d7d5"}},
+	{"Caro-Kann Defence", []string{"e2e4", "c7c6", "d2d4", "d7d5"}},
+	{"Queen's Gambit", []string{"d2d4", "d7d5", "c2c4"}},
+	{"King's Indian Defence", []string{"d2d4", "g8f6", "c2c4", "g7g6", "b1c3", "f8g7"}},
+	{"English Opening", []string{"c2c4", "e7e5"}},
+}
+
+// StudyRecord lists the openings already studied against one opponent
+type StudyRecord struct {
+	Opponent string    `json:"opponent"`
+	Openings []string  `json:"openings"`
+	LastGame time.Time `json:"last_game"`
+}
+
+// StudyRecords persists StudyRecord entries as a JSON file
+type StudyRecords struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]*StudyRecord
+}
+
+// loadStudyRecords reads the records at path; a missing file starts empty
+func loadStudyRecords(path string) (*StudyRecords, error) {
+	s := &StudyRecords{path: path, records: make(map[string]*StudyRecord)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read study records: %v", err)
+	}
+
+	var records []*StudyRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse study records %s: %v", path, err)
+	}
+	for _, r := range records {
+		s.records[r.Opponent] = r
+	}
+	return s, nil
+}
+
+// NextOpening returns the first opening not yet studied against opponent,
+// starting over once all of them have been played
+func (s *StudyRecords) NextOpening(opponent string) StudyOpening {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	studied := 0
+	if r, ok := s.records[opponent]; ok {
+		studied = len(r.Openings)
+	}
+	return studyOpenings[studied%len(studyOpenings)]
+}
+
+// Record marks an opening as studied against opponent and saves the records
+func (s *StudyRecords) Record(opponent, opening string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[opponent]
+	if !ok {
+		r = &StudyRecord{Opponent: opponent}
+		s.records[opponent] = r
+	}
+	r.Openings = append(r.Openings, opening)
+	r.LastGame = time.Now().UTC()
+	return s.saveLocked()
+}
+
+func (s *StudyRecords) saveLocked() error {
+	records := make([]*StudyRecord, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so a crash never leaves a partial file
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write study records: %v", err)
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+// createStudyChallenge challenges username to an unrated game starting after the opening moves
+func createStudyChallenge(cfg *BotConfig, username string, opening StudyOpening) (string, error) {
+	fen, err := movesToFEN(opening.Moves, "")
+	if err != nil {
+		return "", fmt.Errorf("invalid study opening %s: %v", opening.Name, err)
+	}
+	// Lichess only allows custom starting positions in unrated games
+	form := challengeForm(studyClockLimit, studyClockIncrement, false)
+	form.Set("fen", fen)
+	return postChallenge(cfg, username, form)
+}
+
+// ChallengeCreator challenges the STUDY_OPPONENTS one after another to practise openings.
+// An opening only counts as studied once the opponent accepts and the game starts.
+type ChallengeCreator struct {
+	opponents []string
+	records   *StudyRecords
+	pending   *PendingChallenges
+	delay     time.Duration
+	challenge func(username string, opening StudyOpening) (string, error)
+
+	mu   sync.Mutex
+	sent map[string]studyChallenge // by challenge ID, until answered
+}
+
+// studyChallenge is a study challenge waiting for an answer
+type studyChallenge struct {
+	opponent string
+	opening  string
+}
+
+// NewChallengeCreator creates a creator that sends study challenges through the Lichess
+// API and tracks them in pending alongside the bot's other outgoing challenges
+func NewChallengeCreator(cfg *BotConfig, records *StudyRecords, pending *PendingChallenges) *ChallengeCreator {
+	return &ChallengeCreator{
+		opponents: cfg.StudyOpponents,
+		records:   records,
+		pending:   pending,
+		delay:     studyChallengeDelay,
+		challenge: func(username string, opening StudyOpening) (string, error) {
+			return createStudyChallenge(cfg, username, opening)
+		},
+		sent: make(map[string]studyChallenge),
+	}
+}
+
+// Run challenges each study opponent in turn, waiting between challenges. It does
+// nothing while the bot has active games and stops early when ctx is cancelled.
+func (c *ChallengeCreator) Run(ctx context.Context, activeGames int) {
+	if activeGames > 0 {
+		return
+	}
+	for i, opponent := range c.opponents {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.delay):
+			}
+		}
+
+		opening := c.records.NextOpening(opponent)
+		id, err := c.challenge(opponent, opening)
+		if err != nil {
+			log.Printf("Study challenge to %s failed: %v", opponent, err)
+			continue
+		}
+		log.Printf("Challenged %s to study the %s at %s (challenge %s)",
+			opponent, opening.Name, formatTimeControl(studyClockLimit, studyClockIncrement), id)
+
+		c.pending.Add(IssuedChallenge{ID: id, Opponent: opponent, ClockLimit: studyClockLimit, ClockIncrement: studyClockIncrement})
+		c.mu.Lock()
+		c.sent[id] = studyChallenge{opponent: opponent, opening: opening.Name}
+		c.mu.Unlock()
+	}
+}
+
+// HandleEvent records the opening of a study challenge once its game starts (Lichess
+// gives the game the challenge's ID) and forgets challenges that were declined or canceled
+func (c *ChallengeCreator) HandleEvent(event *StreamEvent) {
+	var id string
+	switch event.Type {
+	case EventGameStart:
+		gs, err := event.GameStart()
+		if err != nil {
+			return
+		}
+		id = gs.GameID
+	case EventChallengeDeclined, EventChallengeCanceled:
+		ce, err := event.Challenge()
+		if err != nil {
+			return
+		}
+		id = ce.Challenge.ID
+	default:
+		return
+	}
+
+	c.mu.Lock()
+	study, ok := c.sent[id]
+	delete(c.sent, id)
+	c.mu.Unlock()
+	if !ok || event.Type != EventGameStart {
+		return
+	}
+
+	c.pending.Remove(id)
+	if err := c.records.Record(study.opponent, study.opening); err != nil {
+		log.Printf("Failed to save study record: %v", err)
+	}
+}
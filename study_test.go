@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestStudyRecords_PersistAndRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "study.json")
+	records, err := loadStudyRecords(path)
+	if err != nil {
+		t.Fatalf("loadStudyRecords() failed: %v", err)
+	}
+
+	if first := records.NextOpening("alice"); first.Name != studyOpenings[0].Name {
+		t.Errorf("Expected first opening '%s', got '%s'", studyOpenings[0].Name, first.Name)
+	}
+	if err := records.Record("alice", studyOpenings[0].Name); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+
+	reloaded, err := loadStudyRecords(path)
+	if err != nil {
+		t.Fatalf("loadStudyRecords() reload failed: %v", err)
+	}
+	if next := reloaded.NextOpening("alice"); next.Name != studyOpenings[1].Name {
+		t.Errorf("Expected second opening after reload, got '%s'", next.Name)
+	}
+	if next := reloaded.NextOpening("bob"); next.Name != studyOpenings[0].Name {
+		t.Errorf("Expected new opponent to start with the first opening, got '%s'", next.Name)
+	}
+}
+
+func TestStudyOpenings_AreValid(t *testing.T) {
+	for _, opening := range studyOpenings {
+		if _, err := movesToFEN(opening.Moves, ""); err != nil {
+			t.Errorf("Opening %s has invalid moves: %v", opening.Name, err)
+		}
+	}
+}
+
+func TestChallengeCreator_Run(t *testing.T) {
+	records, _ := loadStudyRecords(filepath.Join(t.TempDir(), "study.json"))
+	pending := NewPendingChallenges()
+	var challenged []string
+	c := &ChallengeCreator{
+		opponents: []string{"alice", "bob"},
+		records:   records,
+		pending:   pending,
+		challenge: func(username string, opening StudyOpening) (string, error) {
+			challenged = append(challenged, username+":"+opening.Name)
+			return "id-" + username, nil
+		},
+		sent: make(map[string]studyChallenge),
+	}
+
+	c.Run(context.Background(), 1)
+	if len(challenged) != 0 {
+		t.Fatalf("Expected no challenges while games are active, got %v", challenged)
+	}
+
+	c.Run(context.Background(), 0)
+	expected := []string{"alice:" + studyOpenings[0].Name, "bob:" + studyOpenings[0].Name}
+	if len(challenged) != 2 || challenged[0] != expected[0] || challenged[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, challenged)
+	}
+	if list := pending.List(); len(list) != 2 {
+		t.Errorf("Expected both study challenges to be pending, got %+v", list)
+	}
+	if next := records.NextOpening("alice"); next.Name != studyOpenings[0].Name {
+		t.Errorf("Expected alice's opening not to advance before the game starts, got '%s'", next.Name)
+	}
+
+	// alice accepts, bob declines
+	start, _ := ParseStreamEvent(`{"type":"gameStart","game":{"gameId":"id-alice"}}`)
+	declined, _ := ParseStreamEvent(`{"type":"challengeDeclined","challenge":{"id":"id-bob"}}`)
+	c.HandleEvent(start)
+	c.HandleEvent(declined)
+
+	if next := records.NextOpening("alice"); next.Name != studyOpenings[1].Name {
+		t.Errorf("Expected alice's next opening to advance, got '%s'", next.Name)
+	}
+	if next := records.NextOpening("bob"); next.Name != studyOpenings[0].Name {
+		t.Errorf("Expected bob's opening to stay after a decline, got '%s'", next.Name)
+	}
+	if list := pending.List(); len(list) != 1 || list[0].ID != "id-bob" {
+		t.Errorf("Expected only bob's challenge left, its decline being PendingChallenges' to handle, got %+v", list)
+	}
+}
+
+func TestCreateStudyChallenge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.URL.Path != "/api/challenge/alice" || r.Form.Get("rated") != "false" {
+			t.Errorf("Unexpected challenge %s %v", r.URL.Path, r.Form)
+		}
+		if fen := r.Form.Get("fen"); fen != "rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2" {
+			t.Errorf("Unexpected fen '%s'", fen)
+		}
+		w.Write([]byte(`{"id":"STUDY1"}`))
+	}))
+	defer server.Close()
+
+	id, err := createStudyChallenge(newTestLichessConfig(server.URL), "alice", studyOpenings[1])
+	if err != nil || id != "STUDY1" {
+		t.Errorf("Expected challenge 'STUDY1', got '%s' (err=%v)", id, err)
+	}
+}
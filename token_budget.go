@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLLMSpendFile is where the monthly LLM spend is kept unless OPENROUTER_SPEND_FILE says otherwise
+const defaultLLMSpendFile = "llm_spend.json"
+
+// modelPrice is the OpenRouter price of a model in USD per million tokens
+type modelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// modelPricing holds list prices for common models, keyed by normalizeModelName.
+// The budget built on it is an estimate, not a bill.
+var modelPricing = map[string]modelPrice{
+	"gpt-4o":                 {2.50, 10.00},
+	"gpt-4o-mini":            {0.15, 0.60},
+	"gpt-4-1":                {2.00, 8.00},
+	"gpt-4-1-mini":           {0.40, 1.60},
+	"claude-3-5-sonnet":      {3.00, 15.00},
+	"claude-3-5-haiku":       {0.80, 4.00},
+	"gemini-flash-1-5":       {0.075, 0.30},
+	"llama-3-1-70b-instruct": {0.40, 0.40},
+}
+
+// modelDateSuffix matches the snapshot date some model names end in, e.g. "-20241022"
+var modelDateSuffix = regexp.MustCompile(`-(\d{8}|\d{4}-\d{2}-\d{2}|latest)$`)
+
+// normalizeModelName reduces a model name to its pricing key, so that
+// "anthropic/claude-3.5-sonnet", "anthropic/claude-3-5-sonnet" and
+// "claude-3-5-sonnet-20241022" are all priced as "claude-3-5-sonnet"
+func normalizeModelName(model string) string {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name, _, _ = strings.Cut(name, ":")
+	name = strings.ReplaceAll(name, ".", "-")
+	return modelDateSuffix.ReplaceAllString(name, "")
+}
+
+// unknownModelPrice is charged for models missing from modelPricing: the most
+// expensive known price, so the budget errs towards switching too early
+var unknownModelPrice = func() modelPrice {
+	var highest modelPrice
+	for _, price := range modelPricing {
+		if price.Prompt+price.Completion > highest.Prompt+highest.Completion {
+			highest = price
+		}
+	}
+	return highest
+}()
+
+var (
+	warnedUnknownMu     sync.Mutex
+	warnedUnknownModels = map[string]bool{}
+)
+
+// lookupModelPrice returns the price of model and whether it is in the table.
+// OpenRouter's ":free" variants cost nothing; other unknown models get unknownModelPrice.
+func lookupModelPrice(model string) (modelPrice, bool) {
+	if strings.HasSuffix(strings.ToLower(model), ":free") {
+		return modelPrice{}, true
+	}
+	if price, ok := modelPricing[normalizeModelName(model)]; ok {
+		return price, true
+	}
+	warnedUnknownMu.Lock()
+	if !warnedUnknownModels[model] {
+		warnedUnknownModels[model] = true
+		log.Printf("WARNING: no price known for model %s, estimating its spend at $%.2f/$%.2f per million tokens",
+			model, unknownModelPrice.Prompt, unknownModelPrice.Completion)
+	}
+	warnedUnknownMu.Unlock()
+	return unknownModelPrice, false
+}
+
+// openRouterUsage is the token usage reported with a chat completion
+type openRouterUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// llmCost estimates the cost in USD of a call; unknown models are priced conservatively
+func llmCost(model string, usage openRouterUsage) float64 {
+	price, _ := lookupModelPrice(model)
+	return (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6
+}
+
+// tokenBudget tracks the estimated OpenRouter spend of the whole bot process
+var tokenBudget = NewTokenBudgetTracker()
+
+// TokenBudgetTracker accumulates the estimated LLM spend for the current calendar
+// month and switches to the cheapest configured model once the budget is used up.
+// After Load the spend of each month is saved to a JSON file, so restarts don't reset it.
+type TokenBudgetTracker struct {
+	mu          sync.Mutex
+	path        string
+	spend       map[string]float64 // estimated USD by month ("2006-01")
+	warnedMonth string             // month the over-budget warning was last logged for
+	now         func() time.Time
+}
+
+// NewTokenBudgetTracker creates an in-memory tracker with nothing spent
+func NewTokenBudgetTracker() *TokenBudgetTracker {
+	return &TokenBudgetTracker{spend: make(map[string]float64), now: time.Now}
+}
+
+// Load reads the spend saved at path and saves there from now on; a missing file starts empty
+func (t *TokenBudgetTracker) Load(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.path = path
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read LLM spend: %v", err)
+	}
+	spend := make(map[string]float64)
+	if err := json.Unmarshal(data, &spend); err != nil {
+		return fmt.Errorf("failed to parse LLM spend %s: %v", path, err)
+	}
+	for month, usd := range spend {
+		t.spend[month] += usd
+	}
+	return nil
+}
+
+func (t *TokenBudgetTracker) month() string {
+	return t.now().Format("2006-01")
+}
+
+// saveLocked writes the spend to the file given to Load; callers must hold mu
+func (t *TokenBudgetTracker) saveLocked() error {
+	if t.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.spend, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so a crash never leaves a partial file
+	tmpPath := t.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write LLM spend: %v", err)
+	}
+	return os.Rename(tmpPath, t.path)
+}
+
+// Record adds the cost of a call to this month's spend, saves it and returns the cost
+func (t *TokenBudgetTracker) Record(model string, usage openRouterUsage) float64 {
+	cost := llmCost(model, usage)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spend[t.month()] += cost
+	if err := t.saveLocked(); err != nil {
+		log.Printf("Failed to save LLM spend: %v", err)
+	}
+	return cost
+}
+
+// Spent returns the estimated spend in USD so far this month
+func (t *TokenBudgetTracker) Spent() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.spend[t.month()]
+}
+
+// ErrLLMBudgetExceeded is returned for LLM requests once the monthly budget is used up
+// and no other model is configured to fall back to
+var ErrLLMBudgetExceeded = errors.New("monthly OpenRouter budget exceeded")
+
+// ModelFor returns the model to use instead of model. While the monthly budget
+// (budgetUSD, 0 for none) is not exhausted that is model itself; afterwards it is
+// the cheapest priced model among candidates. Without a candidate other than model
+// there is nothing cheaper to switch to, and the request is refused with
+// ErrLLMBudgetExceeded.
+func (t *TokenBudgetTracker) ModelFor(model string, budgetUSD float64, candidates []string) (string, error) {
+	if budgetUSD <= 0 {
+		return model, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	month := t.month()
+	spent := t.spend[month]
+	if spent < budgetUSD {
+		return model, nil
+	}
+
+	cheapest, fallback := model, false
+	for _, candidate := range candidates {
+		if candidate != model {
+			fallback = true
+		}
+		price, ok := lookupModelPrice(candidate)
+		if !ok {
+			continue
+		}
+		current, known := lookupModelPrice(cheapest)
+		if !known || price.Prompt+price.Completion < current.Prompt+current.Completion {
+			cheapest = candidate
+		}
+	}
+	warn := t.warnedMonth != month
+	t.warnedMonth = month
+	if !fallback {
+		if warn {
+			log.Printf("WARNING: estimated OpenRouter spend $%.2f reached the monthly budget of $%.2f and no "+
+				"fallback model is configured, refusing LLM requests until next month", spent, budgetUSD)
+		}
+		return "", ErrLLMBudgetExceeded
+	}
+	if warn {
+		log.Printf("WARNING: estimated OpenRouter spend $%.2f reached the monthly budget of $%.2f, switching to %s",
+			spent, budgetUSD, cheapest)
+	}
+	return cheapest, nil
+}
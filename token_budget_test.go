@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLLMCost(t *testing.T) {
+	usage := openRouterUsage{PromptTokens: 1000, CompletionTokens: 100}
+	// 1000 * $2.50/M + 100 * $10/M
+	if cost := llmCost("openai/gpt-4o", usage); math.Abs(cost-0.0035) > 1e-9 {
+		t.Errorf("Expected cost 0.0035, got %v", cost)
+	}
+	// Unknown models are charged the highest known price rather than nothing
+	if cost := llmCost("someone/unknown-model", usage); math.Abs(cost-0.0045) > 1e-9 {
+		t.Errorf("Expected unknown models to cost 0.0045, got %v", cost)
+	}
+	if cost := llmCost("meta-llama/llama-3.1-8b-instruct:free", usage); cost != 0 {
+		t.Errorf("Expected free models to cost 0, got %v", cost)
+	}
+}
+
+func TestNormalizeModelName(t *testing.T) {
+	tests := []struct {
+		model    string
+		expected string
+	}{
+		{"anthropic/claude-3.5-sonnet", "claude-3-5-sonnet"},
+		{"anthropic/claude-3-5-sonnet", "claude-3-5-sonnet"},
+		{"claude-3-5-sonnet-20241022", "claude-3-5-sonnet"},
+		{"Anthropic/Claude-3.5-Sonnet:beta", "claude-3-5-sonnet"},
+		{"openai/gpt-4o-2024-08-06", "gpt-4o"},
+		{"openai/gpt-4o-mini", "gpt-4o-mini"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeModelName(tt.model); got != tt.expected {
+			t.Errorf("normalizeModelName(%s): expected '%s', got '%s'", tt.model, tt.expected, got)
+		}
+		if _, known := lookupModelPrice(tt.model); !known {
+			t.Errorf("Expected %s to have a known price", tt.model)
+		}
+	}
+}
+
+func TestTokenBudgetTracker_ModelFor(t *testing.T) {
+	tracker := NewTokenBudgetTracker()
+	candidates := []string{"openai/gpt-4o", "anthropic/claude-3.5-sonnet", "openai/gpt-4o-mini", "someone/unknown-model"}
+
+	if model, err := tracker.ModelFor("openai/gpt-4o", 1.0, candidates); err != nil || model != "openai/gpt-4o" {
+		t.Errorf("Expected the configured model under budget, got '%s' (%v)", model, err)
+	}
+
+	// 100k prompt + 100k completion tokens of gpt-4o cost $1.25
+	tracker.Record("openai/gpt-4o", openRouterUsage{PromptTokens: 100000, CompletionTokens: 100000})
+	if spent := tracker.Spent(); math.Abs(spent-1.25) > 1e-9 {
+		t.Errorf("Expected $1.25 spent, got %v", spent)
+	}
+	if model, err := tracker.ModelFor("openai/gpt-4o", 1.0, candidates); err != nil || model != "openai/gpt-4o-mini" {
+		t.Errorf("Expected the cheapest model over budget, got '%s' (%v)", model, err)
+	}
+	if model, err := tracker.ModelFor("openai/gpt-4o", 0, candidates); err != nil || model != "openai/gpt-4o" {
+		t.Errorf("Expected no switch without a budget, got '%s' (%v)", model, err)
+	}
+}
+
+func TestTokenBudgetTracker_ModelFor_NoFallback(t *testing.T) {
+	tracker := NewTokenBudgetTracker()
+	tracker.Record("openai/gpt-4o", openRouterUsage{PromptTokens: 1000000})
+
+	_, err := tracker.ModelFor("openai/gpt-4o", 1.0, []string{"openai/gpt-4o"})
+	if !errors.Is(err, ErrLLMBudgetExceeded) {
+		t.Errorf("Expected ErrLLMBudgetExceeded with only the exhausted model configured, got %v", err)
+	}
+}
+
+func TestTokenBudgetTracker_MonthRollover(t *testing.T) {
+	now := time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC)
+	tracker := NewTokenBudgetTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Record("openai/gpt-4o", openRouterUsage{PromptTokens: 1000000})
+	if spent := tracker.Spent(); spent != 2.5 {
+		t.Errorf("Expected $2.50 spent, got %v", spent)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if spent := tracker.Spent(); spent != 0 {
+		t.Errorf("Expected spend to reset in a new month, got %v", spent)
+	}
+}
+
+func TestTokenBudgetTracker_PersistsSpend(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "spend.json")
+
+	tracker := NewTokenBudgetTracker()
+	tracker.now = func() time.Time { return now }
+	if err := tracker.Load(path); err != nil {
+		t.Fatalf("Load() of a missing file failed: %v", err)
+	}
+	tracker.Record("openai/gpt-4o", openRouterUsage{PromptTokens: 1000000})
+
+	// A restart picks up where the last process left off
+	restarted := NewTokenBudgetTracker()
+	restarted.now = func() time.Time { return now }
+	if err := restarted.Load(path); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if spent := restarted.Spent(); spent != 2.5 {
+		t.Errorf("Expected $2.50 spent after restart, got %v", spent)
+	}
+
+	restarted.now = func() time.Time { return now.AddDate(0, 1, 0) }
+	if spent := restarted.Spent(); spent != 0 {
+		t.Errorf("Expected nothing spent in the next month, got %v", spent)
+	}
+
+	os.WriteFile(path, []byte("not json"), 0600)
+	if err := NewTokenBudgetTracker().Load(path); err == nil {
+		t.Error("Expected error for a corrupt spend file")
+	}
+}
+
+func TestCallOpenRouter_RecordsUsageAndSwitchesModel(t *testing.T) {
+	original := tokenBudget
+	tokenBudget = NewTokenBudgetTracker()
+	t.Cleanup(func() { tokenBudget = original })
+
+	var models []string
+	withOpenRouterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"e2e4"}}],` +
+			`"usage":{"prompt_tokens":400000,"completion_tokens":10000}}`))
+	})
+
+	cfg := &BotConfig{OpenRouterModel: "openai/gpt-4o", ValidatorModel: "openai/gpt-4o-mini", MonthlyBudgetUSD: 1.0}
+	for i := 0; i < 2; i++ {
+		if _, err := callOpenRouter(cfg, newOpenRouterRequest(cfg, cfg.OpenRouterModel, nil, 0)); err != nil {
+			t.Fatalf("callOpenRouter() failed: %v", err)
+		}
+	}
+
+	// The first call costs $1.10 and exhausts the budget
+	if len(models) != 2 || models[0] != "openai/gpt-4o" || models[1] != "openai/gpt-4o-mini" {
+		t.Errorf("Expected a switch to the cheaper model after the budget, got %v", models)
+	}
+	if spent := tokenBudget.Spent(); spent < 1.1 {
+		t.Errorf("Expected both calls to be recorded, got $%v", spent)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWebhookMaxAttempts = 3
+	defaultWebhookRetryDelay  = 2 * time.Second
+	defaultWebhookTimeout     = 10 * time.Second
+
+	webhookSignatureHeader = "X-Webhook-Signature"
+	webhookSignaturePrefix = "sha256="
+)
+
+// GameReport is the JSON payload posted to the webhook when a game ends
+type GameReport struct {
+	GameID          string `json:"game_id"`
+	Outcome         string `json:"outcome"`
+	Opponent        string `json:"opponent"`
+	MoveCount       int    `json:"move_count"`
+	DurationSeconds int    `json:"duration_seconds"`
+	URL             string `json:"url"`
+}
+
+// WebhookDelivery records the attempts made to deliver a single report
+type WebhookDelivery struct {
+	GameID     string
+	Attempts   int
+	Delivered  bool
+	StatusCode int
+	LastError  string
+}
+
+// GameReporter posts game results to a webhook URL, retrying failed deliveries
+type GameReporter struct {
+	URL         string
+	Secret      string // signs payloads in the X-Webhook-Signature header when set
+	Client      *http.Client
+	MaxAttempts int
+	RetryDelay  time.Duration
+}
+
+// NewGameReporter creates a reporter for WEBHOOK_URL, signing with WEBHOOK_SECRET,
+// with default retry settings
+func NewGameReporter(cfg *BotConfig) *GameReporter {
+	return &GameReporter{
+		URL:         cfg.WebhookURL,
+		Secret:      cfg.WebhookSecret,
+		Client:      &http.Client{Timeout: defaultWebhookTimeout},
+		MaxAttempts: defaultWebhookMaxAttempts,
+		RetryDelay:  defaultWebhookRetryDelay,
+	}
+}
+
+// lichessGameURL returns the public link for a game on the configured Lichess instance
+func lichessGameURL(baseURL, gameID string) string {
+	return fmt.Sprintf("%s/%s", baseURL, gameID)
+}
+
+// Report posts the game report and returns the delivery record.
+// Non-2xx responses and transport errors are retried up to MaxAttempts times.
+func (r *GameReporter) Report(report GameReport) *WebhookDelivery {
+	delivery := &WebhookDelivery{GameID: report.GameID}
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		delivery.LastError = fmt.Sprintf("failed to marshal report: %v", err)
+		return delivery
+	}
+
+	for attempt := 1; attempt <= r.MaxAttempts; attempt++ {
+		delivery.Attempts = attempt
+
+		statusCode, err := r.post(payload)
+		delivery.StatusCode = statusCode
+		if err == nil {
+			delivery.Delivered = true
+			delivery.LastError = ""
+			log.Printf("Webhook delivered for game %s (attempt %d)", report.GameID, attempt)
+			return delivery
+		}
+
+		delivery.LastError = err.Error()
+		log.Printf("Webhook delivery for game %s failed (attempt %d/%d): %v", report.GameID, attempt, r.MaxAttempts, err)
+		if attempt < r.MaxAttempts {
+			time.Sleep(r.RetryDelay)
+		}
+	}
+
+	return delivery
+}
+
+// post sends a single webhook request
+func (r *GameReporter) post(payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, r.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.Secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhookPayload(payload, r.Secret))
+	}
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// signWebhookPayload returns the X-Webhook-Signature value for payload: "sha256=" followed
+// by the hex HMAC-SHA256 of the payload keyed with secret
+func signWebhookPayload(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyWebhookSignature checks an X-Webhook-Signature header against the payload,
+// comparing in constant time. Webhook consumers can use it to authenticate deliveries.
+func verifyWebhookSignature(payload []byte, header string, secret string) bool {
+	if secret == "" || !strings.HasPrefix(header, webhookSignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(header), []byte(signWebhookPayload(payload, secret)))
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestReporter(url string) *GameReporter {
+	reporter := NewGameReporter(&BotConfig{WebhookURL: url})
+	reporter.RetryDelay = 0
+	return reporter
+}
+
+func TestGameReporter_Report(t *testing.T) {
+	var received GameReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected Content-Type 'application/json', got '%s'", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	report := GameReport{
+		GameID:          "abcd1234",
+		Outcome:         "win",
+		Opponent:        "some_bot",
+		MoveCount:       42,
+		DurationSeconds: 300,
+		URL:             lichessGameURL(defaultLichessBaseURL, "abcd1234"),
+	}
+	delivery := newTestReporter(server.URL).Report(report)
+
+	if !delivery.Delivered || delivery.Attempts != 1 {
+		t.Errorf("Expected delivery on first attempt, got %+v", delivery)
+	}
+	if received != report {
+		t.Errorf("Expected payload %+v, got %+v", report, received)
+	}
+	if received.URL != "https://lichess.org/abcd1234" {
+		t.Errorf("Unexpected game URL '%s'", received.URL)
+	}
+}
+
+func TestGameReporter_RetriesOnFailure(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := newTestReporter(server.URL).Report(GameReport{GameID: "retry"})
+
+	if !delivery.Delivered || delivery.Attempts != 3 {
+		t.Errorf("Expected delivery on third attempt, got %+v", delivery)
+	}
+	if delivery.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected final status %d, got %d", http.StatusNoContent, delivery.StatusCode)
+	}
+}
+
+func TestGameReporter_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	delivery := newTestReporter(server.URL).Report(GameReport{GameID: "fail"})
+
+	if delivery.Delivered {
+		t.Errorf("Expected delivery to fail, got %+v", delivery)
+	}
+	if delivery.Attempts != defaultWebhookMaxAttempts || atomic.LoadInt32(&calls) != defaultWebhookMaxAttempts {
+		t.Errorf("Expected %d attempts, got %d (server saw %d)", defaultWebhookMaxAttempts, delivery.Attempts, calls)
+	}
+	if delivery.LastError == "" {
+		t.Error("Expected LastError to be set")
+	}
+}
+
+func TestGameReporter_SignsPayload(t *testing.T) {
+	var signature string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(webhookSignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	reporter := NewGameReporter(&BotConfig{WebhookURL: server.URL, WebhookSecret: "s3cret"})
+	if delivery := reporter.Report(GameReport{GameID: "abcd1234", Outcome: "win"}); !delivery.Delivered {
+		t.Fatalf("Expected delivery, got %+v", delivery)
+	}
+
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Fatalf("Expected sha256= signature header, got '%s'", signature)
+	}
+	if !verifyWebhookSignature(body, signature, "s3cret") {
+		t.Error("Expected the delivered signature to verify")
+	}
+}
+
+func TestGameReporter_NoSignatureWithoutSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sig := r.Header.Get(webhookSignatureHeader); sig != "" {
+			t.Errorf("Expected no signature header, got '%s'", sig)
+		}
+	}))
+	defer server.Close()
+
+	newTestReporter(server.URL).Report(GameReport{GameID: "abcd1234"})
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	payload := []byte(`{"game_id":"abcd1234","outcome":"win"}`)
+	// HMAC-SHA256 of payload with key "s3cret"
+	valid := signWebhookPayload(payload, "s3cret")
+
+	tests := []struct {
+		name    string
+		payload []byte
+		header  string
+		secret  string
+		want    bool
+	}{
+		{"valid signature", payload, valid, "s3cret", true},
+		{"wrong secret", payload, valid, "other", false},
+		{"tampered payload", []byte(`{"game_id":"abcd1234","outcome":"loss"}`), valid, "s3cret", false},
+		{"missing prefix", payload, strings.TrimPrefix(valid, "sha256="), "s3cret", false},
+		{"empty header", payload, "", "s3cret", false},
+		{"empty secret", payload, valid, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyWebhookSignature(tt.payload, tt.header, tt.secret); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}